@@ -0,0 +1,86 @@
+package common
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the source of time of Now(), After() and Sleep(); by default, the
+// real clock is used.
+type Clock interface {
+	Now() time.Time
+	After(time.Duration) <-chan time.Time
+}
+
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type clockValue struct {
+	Clock
+}
+
+var clock atomic.Value
+
+func init() {
+	clock.Store(clockValue{Clock: RealClock{}})
+}
+
+// SetClock replaces the clock; nil resets it to the real clock.
+func SetClock(c Clock) {
+	if c == nil {
+		c = RealClock{}
+	}
+
+	clock.Store(clockValue{Clock: c})
+}
+
+func CurrentClock() Clock {
+	return clock.Load().(clockValue).Clock
+}
+
+// SkewedClock skews the time of the current clock by the given duration; it is
+// for simulating the wrong clock of node.
+type SkewedClock struct {
+	skew int64
+}
+
+func NewSkewedClock() *SkewedClock {
+	return &SkewedClock{}
+}
+
+func (sc *SkewedClock) Skew() time.Duration {
+	return time.Duration(atomic.LoadInt64(&sc.skew))
+}
+
+func (sc *SkewedClock) SetSkew(d time.Duration) {
+	atomic.StoreInt64(&sc.skew, int64(d))
+}
+
+func (sc *SkewedClock) Now() time.Time {
+	return CurrentClock().Now().Add(sc.Skew())
+}
+
+func (sc *SkewedClock) After(d time.Duration) <-chan time.Time {
+	return CurrentClock().After(d)
+}
+
+// After waits for the duration to elapse in the current clock.
+func After(d time.Duration) <-chan time.Time {
+	return CurrentClock().After(d)
+}
+
+// Sleep pauses the current goroutine for the duration in the current clock.
+func Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	<-After(d)
+}
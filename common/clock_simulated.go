@@ -0,0 +1,210 @@
+package common
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type simulatedWaiter struct {
+	at  time.Time
+	seq uint64
+	ch  chan time.Time
+}
+
+type simulatedWaiters []simulatedWaiter
+
+func (sw simulatedWaiters) Len() int {
+	return len(sw)
+}
+
+func (sw simulatedWaiters) Less(i, j int) bool {
+	if sw[i].at.Equal(sw[j].at) {
+		return sw[i].seq < sw[j].seq
+	}
+
+	return sw[i].at.Before(sw[j].at)
+}
+
+func (sw simulatedWaiters) Swap(i, j int) {
+	sw[i], sw[j] = sw[j], sw[i]
+}
+
+func (sw *simulatedWaiters) Push(x interface{}) {
+	*sw = append(*sw, x.(simulatedWaiter))
+}
+
+func (sw *simulatedWaiters) Pop() interface{} {
+	old := *sw
+	n := len(old)
+	w := old[n-1]
+	*sw = old[:n-1]
+
+	return w
+}
+
+// SimulatedClock is the Clock, which does not follow the real time. The time
+// is moved by Advance() and AdvanceToNext(). After started, it moves the time
+// to the next waiter by itself, when no new waiter is added during the idle
+// duration of real time; so the timers are expired as fast as the events are
+// processed.
+type SimulatedClock struct {
+	sync.RWMutex
+	*Logger
+	now          time.Time
+	waiters      simulatedWaiters
+	seq          uint64
+	idle         time.Duration
+	lastActivity time.Time
+	stop         chan struct{}
+}
+
+func NewSimulatedClock(start time.Time, idle time.Duration) *SimulatedClock {
+	return &SimulatedClock{
+		Logger: NewLogger(func(c zerolog.Context) zerolog.Context {
+			return c.Str("module", "simulated-clock")
+		}),
+		now:          start,
+		idle:         idle,
+		lastActivity: time.Now(),
+	}
+}
+
+func (sc *SimulatedClock) Now() time.Time {
+	sc.RLock()
+	defer sc.RUnlock()
+
+	return sc.now
+}
+
+func (sc *SimulatedClock) After(d time.Duration) <-chan time.Time {
+	sc.Lock()
+	defer sc.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- sc.now
+		return ch
+	}
+
+	sc.seq++
+	heap.Push(&sc.waiters, simulatedWaiter{at: sc.now.Add(d), seq: sc.seq, ch: ch})
+	sc.lastActivity = time.Now()
+
+	return ch
+}
+
+// Pending returns the number of waiters, which are not expired yet.
+func (sc *SimulatedClock) Pending() int {
+	sc.RLock()
+	defer sc.RUnlock()
+
+	return sc.waiters.Len()
+}
+
+// Advance moves the time by the duration and expires the waiters in order.
+func (sc *SimulatedClock) Advance(d time.Duration) {
+	sc.Lock()
+	defer sc.Unlock()
+
+	sc.advanceTo(sc.now.Add(d))
+}
+
+// AdvanceToNext moves the time to the next waiter; it returns false if no
+// waiter.
+func (sc *SimulatedClock) AdvanceToNext() bool {
+	sc.Lock()
+	defer sc.Unlock()
+
+	if sc.waiters.Len() < 1 {
+		return false
+	}
+
+	sc.advanceTo(sc.waiters[0].at)
+
+	return true
+}
+
+func (sc *SimulatedClock) advanceTo(t time.Time) {
+	for sc.waiters.Len() > 0 && !sc.waiters[0].at.After(t) {
+		w := heap.Pop(&sc.waiters).(simulatedWaiter)
+		if w.at.After(sc.now) {
+			sc.now = w.at
+		}
+
+		w.ch <- w.at
+	}
+
+	if t.After(sc.now) {
+		sc.now = t
+	}
+}
+
+func (sc *SimulatedClock) Start() error {
+	sc.Lock()
+
+	if sc.stop != nil {
+		sc.Unlock()
+		return DaemonAleadyStartedError
+	}
+
+	sc.stop = make(chan struct{})
+	go sc.loop(sc.stop)
+	sc.Unlock()
+
+	// NOTE the logger may use Now() for timestamp, so log without lock
+	sc.Log().Debug().Dur("idle", sc.idle).Msg("started")
+
+	return nil
+}
+
+func (sc *SimulatedClock) Stop() error {
+	sc.Lock()
+
+	if sc.stop == nil {
+		sc.Unlock()
+		return nil
+	}
+
+	close(sc.stop)
+	sc.stop = nil
+	sc.Unlock()
+
+	sc.Log().Debug().Msg("stopped")
+
+	return nil
+}
+
+func (sc *SimulatedClock) IsStopped() bool {
+	sc.RLock()
+	defer sc.RUnlock()
+
+	return sc.stop == nil
+}
+
+func (sc *SimulatedClock) loop(stop chan struct{}) {
+	interval := sc.idle / 2
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			sc.RLock()
+			idle := time.Since(sc.lastActivity) >= sc.idle
+			sc.RUnlock()
+
+			if idle {
+				_ = sc.AdvanceToNext()
+			}
+		}
+	}
+}
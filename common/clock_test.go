@@ -0,0 +1,174 @@
+package common
+
+import (
+	"io/ioutil"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type testSimulatedClock struct {
+	suite.Suite
+}
+
+func (t *testSimulatedClock) TearDownTest() {
+	SetClock(nil)
+}
+
+func (t *testSimulatedClock) TestDefaultClock() {
+	_, ok := CurrentClock().(RealClock)
+	t.True(ok)
+}
+
+func (t *testSimulatedClock) TestAdvance() {
+	start := time.Now()
+	sc := NewSimulatedClock(start, time.Hour)
+
+	t.Equal(start, sc.Now())
+
+	a := sc.After(time.Second * 2)
+	b := sc.After(time.Second)
+	t.Equal(2, sc.Pending())
+
+	sc.Advance(time.Millisecond * 1500)
+	t.Equal(start.Add(time.Millisecond*1500), sc.Now())
+
+	select {
+	case <-a:
+		t.Fail("not yet expired")
+	case at := <-b:
+		t.Equal(start.Add(time.Second), at)
+	}
+
+	t.True(sc.AdvanceToNext())
+	t.Equal(start.Add(time.Second*2), <-a)
+	t.Equal(start.Add(time.Second*2), sc.Now())
+
+	t.False(sc.AdvanceToNext())
+}
+
+func (t *testSimulatedClock) TestAfterZero() {
+	sc := NewSimulatedClock(time.Now(), time.Hour)
+
+	select {
+	case <-sc.After(0):
+	default:
+		t.Fail("zero duration should be expired at once")
+	}
+
+	t.Equal(0, sc.Pending())
+}
+
+func (t *testSimulatedClock) TestIdle() {
+	start := time.Now()
+	sc := NewSimulatedClock(start, time.Millisecond*10)
+	t.NoError(sc.Start())
+	defer sc.Stop()
+
+	select {
+	case at := <-sc.After(time.Hour):
+		t.Equal(start.Add(time.Hour), at)
+	case <-time.After(time.Second):
+		t.Fail("simulated clock did not advance")
+	}
+}
+
+func (t *testSimulatedClock) TestLogWithClock() {
+	sc := NewSimulatedClock(time.Now(), time.Hour)
+	SetClock(sc)
+
+	// NOTE the timestamp of log comes from the simulated clock
+	l := zerolog.New(ioutil.Discard).Level(zerolog.DebugLevel).With().Timestamp().Logger()
+	previous := zerolog.TimestampFunc
+	zerolog.TimestampFunc = func() time.Time {
+		return Now().Time
+	}
+	defer func() {
+		zerolog.TimestampFunc = previous
+	}()
+
+	_ = sc.SetLogger(l)
+
+	done := make(chan struct{})
+	go func() {
+		_ = sc.Start()
+		_ = sc.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fail("deadlocked")
+	}
+}
+
+func (t *testSimulatedClock) TestNowAndTimer() {
+	start := time.Now().Add(time.Hour * -24)
+	sc := NewSimulatedClock(start, time.Hour)
+	SetClock(sc)
+
+	t.Equal(start, Now().Time)
+
+	var runCount uint64
+	ct := NewCallbackTimer("test", time.Minute, func(Timer) error {
+		atomic.AddUint64(&runCount, 1)
+		return nil
+	})
+	t.NoError(ct.Start())
+	defer ct.Stop()
+
+	// NOTE wait until the timer waits the clock
+	for sc.Pending() < 1 {
+		<-time.After(time.Millisecond)
+	}
+
+	sc.Advance(time.Minute)
+
+	for sc.Pending() < 1 {
+		<-time.After(time.Millisecond)
+	}
+
+	t.Equal(uint64(1), atomic.LoadUint64(&runCount))
+	t.Equal(start.Add(time.Minute), Now().Time)
+}
+
+func (t *testSimulatedClock) TestSkewedClock() {
+	start := time.Now().Add(time.Hour * -24)
+	SetClock(NewSimulatedClock(start, time.Hour))
+
+	skewed := NewSkewedClock()
+	t.Equal(start, NowOf(skewed).Time)
+
+	skewed.SetSkew(time.Minute)
+	t.Equal(start.Add(time.Minute), NowOf(skewed).Time)
+
+	// NOTE the current clock is not skewed
+	t.Equal(start, Now().Time)
+}
+
+func (t *testSimulatedClock) TestTimeSyncerWaitsClock() {
+	sc := NewSimulatedClock(time.Now(), time.Hour)
+	SetClock(sc)
+
+	ts := &TimeSyncer{
+		Logger:   NewLogger(nil),
+		interval: time.Minute,
+		stopChan: make(chan bool),
+	}
+	t.NoError(ts.Start())
+
+	// NOTE the interval of TimeSyncer is waited by the current clock
+	for sc.Pending() < 1 {
+		<-time.After(time.Millisecond)
+	}
+
+	t.NoError(ts.Stop())
+}
+
+func TestSimulatedClock(t *testing.T) {
+	suite.Run(t, new(testSimulatedClock))
+}
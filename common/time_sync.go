@@ -69,16 +69,13 @@ func (s *TimeSyncer) Stop() error {
 }
 
 func (s *TimeSyncer) schedule() {
-	ticker := time.NewTicker(s.interval)
-
 end:
 	for {
 		select {
 		case <-s.stopChan:
-			ticker.Stop()
 			s.Log().Debug().Msg("time-syncer stopped")
 			break end
-		case <-ticker.C:
+		case <-After(s.interval):
 			s.check()
 		}
 	}
@@ -132,9 +129,15 @@ func (s *TimeSyncer) check() {
 }
 
 func Now() Time {
+	return NowOf(CurrentClock())
+}
+
+// NowOf returns the time of the given clock with the offset of TimeSyncer.
+func NowOf(c Clock) Time {
+	now := c.Now()
 	if timeSyncer == nil {
-		return Time{Time: time.Now()}
+		return Time{Time: now}
 	}
 
-	return Time{Time: time.Now().Add(timeSyncer.Offset())}
+	return Time{Time: now.Add(timeSyncer.Offset())}
 }
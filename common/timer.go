@@ -162,7 +162,7 @@ func (ct *CallbackTimer) run() {
 	select {
 	case <-ct.stopChan:
 		return
-	case <-time.After(interval):
+	case <-After(interval):
 	}
 
 	if ct.IsStopped() {
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/keypair"
+)
+
+const keystorePassphraseEnv string = "CONTEST_KEYSTORE_PASSPHRASE"
+
+var keystoreCmd = &cobra.Command{
+	Use:   "keystore",
+	Short: "encrypted keystore of node key",
+	Args:  cobra.NoArgs,
+}
+
+var keystoreNewCmd = &cobra.Command{
+	Use:   "new <keystore file>",
+	Short: "generate new private key and store it to the encrypted keystore",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(args[0]); err == nil {
+			cmd.Println("Error:", "keystore file already exists;", args[0])
+			os.Exit(1)
+		}
+
+		pk, err := newPrivateKey(flagKeystoreType)
+		if err != nil {
+			cmd.Println("Error:", err.Error())
+			os.Exit(1)
+		}
+
+		passphrase, err := readPassphrase(cmd, true)
+		if err != nil {
+			cmd.Println("Error:", err.Error())
+			os.Exit(1)
+		}
+
+		b, err := keypair.EncryptKeystore(pk, passphrase)
+		if err != nil {
+			cmd.Println("Error:", err.Error())
+			os.Exit(1)
+		}
+
+		if err := ioutil.WriteFile(args[0], b, 0600); err != nil {
+			cmd.Println("Error:", err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Println(pk.PublicKey().String())
+	},
+}
+
+var keystoreShowCmd = &cobra.Command{
+	Use:   "show <keystore file>",
+	Short: "decrypt the keystore and show the public key",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		b, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			cmd.Println("Error:", err.Error())
+			os.Exit(1)
+		}
+
+		passphrase, err := readPassphrase(cmd, false)
+		if err != nil {
+			cmd.Println("Error:", err.Error())
+			os.Exit(1)
+		}
+
+		pk, err := keypair.DecryptKeystore(b, passphrase)
+		if err != nil {
+			cmd.Println("Error:", err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Println(pk.PublicKey().String())
+	},
+}
+
+var keystoreSignerCmd = &cobra.Command{
+	Use:   "signer <keystore file>",
+	Short: "run remote signer with the keystore over unix socket",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		b, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			cmd.Println("Error:", err.Error())
+			os.Exit(1)
+		}
+
+		passphrase, err := readPassphrase(cmd, false)
+		if err != nil {
+			cmd.Println("Error:", err.Error())
+			os.Exit(1)
+		}
+
+		pk, err := keypair.DecryptKeystore(b, passphrase)
+		if err != nil {
+			cmd.Println("Error:", err.Error())
+			os.Exit(1)
+		}
+
+		server := keypair.NewRemoteSignerServer(flagSignerSocket, pk)
+		_ = server.SetLogger(log)
+
+		if err := server.Start(); err != nil {
+			cmd.Println("Error:", err.Error())
+			os.Exit(1)
+		}
+
+		exitHooks = append(exitHooks, func() {
+			_ = server.Stop()
+		})
+
+		log.Info().
+			Str("socket", flagSignerSocket).
+			Str("publickey", pk.PublicKey().String()).
+			Msg("remote signer started")
+
+		select {}
+	},
+}
+
+func newPrivateKey(t string) (keypair.PrivateKey, error) {
+	switch t {
+	case keypair.StellarType.Name():
+		return keypair.NewStellarPrivateKey()
+	case keypair.Secp256k1Type.Name():
+		return keypair.NewSecp256k1PrivateKey()
+	case keypair.BLSType.Name():
+		return keypair.NewBLSPrivateKey()
+	default:
+		return nil, xerrors.Errorf("unknown keypair type; type=%q", t)
+	}
+}
+
+// readPassphrase reads passphrase from the environment variable,
+// `CONTEST_KEYSTORE_PASSPHRASE`, or from terminal.
+func readPassphrase(cmd *cobra.Command, confirm bool) ([]byte, error) {
+	if p := os.Getenv(keystorePassphraseEnv); len(p) > 0 {
+		return []byte(p), nil
+	}
+
+	if !terminal.IsTerminal(int(syscall.Stdin)) {
+		return nil, xerrors.Errorf("passphrase is empty; set %s", keystorePassphraseEnv)
+	}
+
+	cmd.Print("passphrase: ")
+	passphrase, err := terminal.ReadPassword(int(syscall.Stdin))
+	cmd.Println()
+	if err != nil {
+		return nil, err
+	} else if len(passphrase) < 1 {
+		return nil, xerrors.Errorf("empty passphrase")
+	}
+
+	if !confirm {
+		return passphrase, nil
+	}
+
+	cmd.Print("confirm passphrase: ")
+	confirmed, err := terminal.ReadPassword(int(syscall.Stdin))
+	cmd.Println()
+	if err != nil {
+		return nil, err
+	} else if !bytes.Equal(passphrase, confirmed) {
+		return nil, xerrors.Errorf("passphrase does not match")
+	}
+
+	return passphrase, nil
+}
+
+func init() {
+	keystoreNewCmd.Flags().StringVar(
+		&flagKeystoreType, "type", flagKeystoreType, "keypair type: {stellar secp256k1 bls}",
+	)
+
+	keystoreCmd.AddCommand(keystoreNewCmd)
+	keystoreSignerCmd.Flags().StringVar(&flagSignerSocket, "socket", flagSignerSocket, "unix socket path")
+
+	keystoreCmd.AddCommand(keystoreShowCmd)
+	keystoreCmd.AddCommand(keystoreSignerCmd)
+	rootCmd.AddCommand(keystoreCmd)
+}
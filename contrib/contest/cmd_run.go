@@ -11,10 +11,12 @@ import (
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 
 	"github.com/spikeekips/mitum/common"
 	"github.com/spikeekips/mitum/contrib/contest/condition"
+	contest_module "github.com/spikeekips/mitum/contrib/contest/module"
 	"github.com/spikeekips/mitum/node"
 )
 
@@ -35,7 +37,23 @@ var runCmd = &cobra.Command{
 			log.Info().Msg("contest stopped")
 		}()
 
-		config, err := LoadConfig(args[0], flagNumberOfNodes)
+		// NOTE the seed should be set before config is loaded; the blocks of
+		// config are generated with it.
+		seed := flagSeed
+		if !cmd.Flags().Changed("seed") {
+			seed = time.Now().UnixNano()
+		}
+		contest_module.SetSeed(seed)
+
+		log.Info().Int64("seed", seed).Msg("random seed; run again with `--seed` to replay")
+
+		// NOTE without `--number-of-nodes`, the nodes of config are used
+		var numberOfNodes uint
+		if cmd.Flags().Changed("number-of-nodes") {
+			numberOfNodes = flagNumberOfNodes
+		}
+
+		config, err := LoadConfig(args[0], numberOfNodes)
 		if err != nil {
 			cmd.Println("Error:", err.Error())
 			os.Exit(1)
@@ -46,8 +64,10 @@ var runCmd = &cobra.Command{
 			Dur("flagExitAfter", flagExitAfter).
 			Msg("config loaded")
 
+		_ = config.Hash.SetDefaultAlgorithms()
+
 		var nodes *Nodes
-		nodeList := getAllNodesFromConfig(config)
+		nodeList := getAllNodesFromConfig(config, seed)
 
 		previousExitHooks := exitHooks
 		exitHooks = nil
@@ -55,6 +75,9 @@ var runCmd = &cobra.Command{
 			_ = nodes.Stop()
 		})
 
+		logWriters := []io.Writer{logOutput}
+
+		var conditionChecker *condition.MultipleConditionChecker
 		if config.Condition != nil {
 			satisfiedChan := make(chan bool)
 
@@ -66,6 +89,7 @@ var runCmd = &cobra.Command{
 			conditions := prepareConditions(config, nodeList)
 
 			cp := condition.NewMultipleConditionCheckerFromConditions(conditions, 1)
+			conditionChecker = cp
 			lw := condition.NewLogWatcher(cp, satisfiedChan)
 
 			exitHooks = append(
@@ -86,15 +110,111 @@ var runCmd = &cobra.Command{
 				},
 			)
 
-			log = log.
-				Output(io.MultiWriter(logOutput, lw))
+			logWriters = append(logWriters, lw)
 
 			_ = lw.SetLogger(stdoutLog)
 			_ = lw.Start()
 		}
+
+		var scenario *Scenario
+		if len(config.Scenario) > 0 {
+			scenario, err = NewScenario(config.Scenario)
+			if err != nil {
+				cmd.Println("Error:", err.Error())
+				os.Exit(1)
+			}
+
+			exitHooks = append(exitHooks, func() {
+				_ = scenario.Stop()
+			})
+
+			logWriters = append(logWriters, scenario)
+		}
+
+		// NOTE invariants and report are collected with the info log of new
+		// block
+		var invariants *Invariants
+		if flagLogLevel.lvl > zerolog.InfoLevel {
+			log.Warn().Msg("invariants are not checked and report is not made; log level should be debug or info")
+		} else {
+			invariants, err = NewInvariants(config, nodeList)
+			if err != nil {
+				cmd.Println("Error:", err.Error())
+				os.Exit(1)
+			}
+
+			exitHooks = append(exitHooks, func() {
+				_ = invariants.Stop()
+
+				if !invariants.Report(os.Stdout) {
+					exitCode = 1
+				}
+			})
+
+			aliases := make([]string, len(nodeList))
+			for i, n := range nodeList {
+				aliases[i] = n.Alias()
+			}
+
+			report := NewReport(seed, args[0], aliases).SetInvariants(invariants)
+			if conditionChecker != nil {
+				_ = report.SetConditions(conditionChecker)
+			}
+
+			// NOTE without log directory, the summary of report is printed
+			exitHooks = append(exitHooks, func() {
+				if len(logDir) < 1 {
+					_ = WriteReportSummary(os.Stdout, report.Result(exitCode))
+					return
+				}
+
+				if err := report.WriteFiles(logDir, exitCode); err != nil {
+					log.Error().Err(err).Msg("failed to write report")
+					return
+				}
+
+				log.Info().Str("directory", logDir).Msg("report written")
+			})
+
+			logWriters = append(logWriters, invariants, report)
+		}
+
+		if len(logWriters) > 1 {
+			log = log.Output(io.MultiWriter(logWriters...))
+		}
+
+		if scenario != nil {
+			scenario.SetLogger(log)
+		}
+
+		if invariants != nil {
+			_ = invariants.SetLogger(stdoutLog)
+		}
+
+		if config.Clock.IsSimulated() {
+			clock := common.NewSimulatedClock(time.Now(), *config.Clock.Idle)
+			clock.SetLogger(log)
+
+			common.SetClock(clock)
+			zerolog.TimestampFunc = func() time.Time {
+				return common.Now().Time
+			}
+
+			if err := clock.Start(); err != nil {
+				cmd.Println("Error:", err.Error())
+				os.Exit(1)
+			}
+
+			// NOTE the clock is stopped at first; otherwise the simulated time
+			// keeps going while the nodes are stopped
+			exitHooks = append([]func(){func() {
+				_ = clock.Stop()
+			}}, exitHooks...)
+		}
+
 		exitHooks = append(exitHooks, previousExitHooks...)
 
-		nodes, err = NewNodes(config, nodeList)
+		nodes, err = NewNodes(config, nodeList, seed)
 		if err != nil {
 			printError(cmd, err)
 			os.Exit(1)
@@ -112,7 +232,14 @@ var runCmd = &cobra.Command{
 			sigc <- syscall.SIGINT // interrupt process by force after timeout
 		}()
 
-		if err := run(cmd, nodes); err != nil {
+		if invariants != nil {
+			if err := invariants.Start(); err != nil {
+				printError(cmd, err)
+				os.Exit(1)
+			}
+		}
+
+		if err := run(cmd, nodes, scenario); err != nil {
 			printError(cmd, err)
 			os.Exit(1)
 		}
@@ -122,6 +249,7 @@ var runCmd = &cobra.Command{
 func init() {
 	runCmd.Flags().DurationVar(&flagExitAfter, "exit-after", 0, "exit after; 0 forever")
 	runCmd.Flags().UintVar(&flagNumberOfNodes, "number-of-nodes", 0, "number of nodes")
+	runCmd.Flags().Int64Var(&flagSeed, "seed", 0, "random seed of keys and faults; random by default")
 
 	rootCmd.AddCommand(runCmd)
 }
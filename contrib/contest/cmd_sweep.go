@@ -0,0 +1,165 @@
+package main
+
+import (
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+)
+
+var sweepCmd = &cobra.Command{
+	Use:   "sweep <config>",
+	Short: "run contest with the combinations of parameters",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		// NOTE invariants and report are collected with the info log of new
+		// block
+		if flagLogLevel.lvl > zerolog.InfoLevel {
+			cmd.Println("Error: log level should be debug or info for sweep")
+			os.Exit(1)
+		}
+
+		if flagSweepDuration < time.Nanosecond {
+			cmd.Println("Error: `--duration` should be greater than zero")
+			os.Exit(1)
+		}
+
+		params, err := sweepParameters()
+		if err != nil {
+			cmd.Println("Error:", err.Error())
+			os.Exit(1)
+		}
+
+		seed := flagSeed
+		if !cmd.Flags().Changed("seed") {
+			seed = time.Now().UnixNano()
+		}
+
+		cases, err := sweepCases(params, flagSweepSample, rand.New(rand.NewSource(seed))) // nolint
+		if err != nil {
+			cmd.Println("Error:", err.Error())
+			os.Exit(1)
+		}
+
+		log.Info().
+			Int64("seed", seed).
+			Int("cases", len(cases)).
+			Msg("sweep started; run again with `--seed` to make the same cases")
+
+		sw := NewSweep(args[0], params, cases, flagSweepDuration, logDir)
+
+		// NOTE stopped by force, the finished cases are reported
+		exitHooks = append([]func(){func() {
+			writeSweepResult(sw)
+		}}, exitHooks...)
+
+		if !sw.Run() {
+			exitCode = 1
+		}
+
+		exitHooks = exitHooks[1:]
+
+		writeSweepResult(sw)
+	},
+}
+
+func init() {
+	sweepCmd.Flags().DurationVar(&flagSweepDuration, "duration", flagSweepDuration, "duration of each case")
+	sweepCmd.Flags().UintVar(&flagSweepSample, "sample", 0, "number of random cases; 0 runs all combinations")
+	sweepCmd.Flags().Int64Var(&flagSeed, "seed", 0, "random seed of cases; random by default")
+	sweepCmd.Flags().StringSliceVar(&flagSweepNodes, "nodes", nil, "number of nodes; 4,5 or 4..7")
+	sweepCmd.Flags().StringSliceVar(&flagSweepSeeds, "seeds", nil, "random seeds of cases; 1,2 or 1..10")
+	sweepCmd.Flags().StringSliceVar(&flagSweepThreshold, "threshold", nil, "thresholds")
+	sweepCmd.Flags().StringSliceVar(
+		&flagSweepTimeoutWaitBallot, "timeout-wait-ballot", nil, "timeouts of waiting ballot")
+	sweepCmd.Flags().StringSliceVar(
+		&flagSweepTimeoutWaitINITBallot, "timeout-wait-init-ballot", nil, "timeouts of waiting INIT ballot")
+	sweepCmd.Flags().StringSliceVar(&flagSweepDrop, "drop", nil, "drop rates of network")
+	sweepCmd.Flags().StringSliceVar(&flagSweepDuplicate, "duplicate", nil, "duplicate rates of network")
+	sweepCmd.Flags().StringSliceVar(&flagSweepReorder, "reorder", nil, "reorder rates of network")
+
+	rootCmd.AddCommand(sweepCmd)
+}
+
+func sweepParameters() ([]*sweepParameter, error) {
+	type flagParameter struct {
+		name   string
+		values []string
+		ranged bool
+		set    func(*sweepCase, string) error
+	}
+
+	fps := []flagParameter{
+		{name: "nodes", values: flagSweepNodes, ranged: true, set: setSweepNodes},
+		{name: "threshold", values: flagSweepThreshold, set: setSweepThreshold},
+		{
+			name:   "timeout_wait_ballot",
+			values: flagSweepTimeoutWaitBallot,
+			set: setSweepPolicyTimeout(func(pc *PolicyConfig, d *time.Duration) {
+				pc.TimeoutWaitBallot = d
+			}),
+		},
+		{
+			name:   "timeout_wait_init_ballot",
+			values: flagSweepTimeoutWaitINITBallot,
+			set: setSweepPolicyTimeout(func(pc *PolicyConfig, d *time.Duration) {
+				pc.TimeoutWaitINITBallot = d
+			}),
+		},
+		{
+			name:   "drop",
+			values: flagSweepDrop,
+			set: setSweepFault(func(lc *LinkFaultConfig, p *float64) {
+				lc.Drop = p
+			}),
+		},
+		{
+			name:   "duplicate",
+			values: flagSweepDuplicate,
+			set: setSweepFault(func(lc *LinkFaultConfig, p *float64) {
+				lc.Duplicate = p
+			}),
+		},
+		{
+			name:   "reorder",
+			values: flagSweepReorder,
+			set: setSweepFault(func(lc *LinkFaultConfig, p *float64) {
+				lc.Reorder = p
+			}),
+		},
+		{name: "seed", values: flagSweepSeeds, ranged: true, set: setSweepSeed},
+	}
+
+	var params []*sweepParameter
+	for _, fp := range fps {
+		p, err := newSweepParameter(fp.name, fp.values, fp.ranged, fp.set)
+		if err != nil {
+			return nil, err
+		} else if len(p.values) < 1 {
+			continue
+		}
+
+		params = append(params, p)
+	}
+
+	return params, nil
+}
+
+// writeSweepResult prints the table of sweep; with log directory, the results
+// are also written.
+func writeSweepResult(sw *Sweep) {
+	_ = sw.WriteTable(os.Stdout)
+
+	if len(logDir) < 1 {
+		return
+	}
+
+	if err := sw.WriteFiles(logDir); err != nil {
+		log.Error().Err(err).Msg("failed to write sweep result")
+		return
+	}
+
+	log.Info().Str("directory", logDir).Msg("sweep result written")
+}
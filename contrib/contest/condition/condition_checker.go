@@ -401,6 +401,16 @@ func (mc *MultipleConditionChecker) Check(o LogItem) bool {
 	return ok
 }
 
+// Queries returns the queries of all the checkers.
+func (mc *MultipleConditionChecker) Queries() []string {
+	queries := make([]string, len(mc.checkers))
+	for i, c := range mc.checkers {
+		queries[i] = c.Query()
+	}
+
+	return queries
+}
+
 func (mc *MultipleConditionChecker) Satisfied() map[string][]LogItem {
 	o := map[string][]LogItem{}
 
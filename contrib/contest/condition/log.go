@@ -90,6 +90,13 @@ func (lw *LogWatcher) Stop() error {
 	return nil
 }
 
+func (lw *LogWatcher) isStopped() bool {
+	lw.RLock()
+	defer lw.RUnlock()
+
+	return lw.stopped
+}
+
 func (lw *LogWatcher) Write(b []byte) (int, error) {
 	lw.RLock()
 	if lw.stopped {
@@ -153,6 +160,11 @@ func (lw *LogWatcher) Start() error {
 
 	go func() {
 		for {
+			// NOTE stopped from outside; the watcher is not reused
+			if lw.isStopped() {
+				return
+			}
+
 			if check() {
 				break
 			}
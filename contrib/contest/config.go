@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/spikeekips/mitum/contrib/contest/condition"
 	contest_module "github.com/spikeekips/mitum/contrib/contest/module"
+	"github.com/spikeekips/mitum/hash"
 	"github.com/spikeekips/mitum/isaac"
 )
 
@@ -22,9 +24,19 @@ type Config struct {
 	Nodes          map[string]*NodeConfig
 	NumberOfNodes_ *uint `yaml:"number_of_nodes,omitempty"`
 	Condition      map[string]*ConditionConfig
+	Hash           *HashConfig             `yaml:"hash,omitempty"`
+	Network        *NetworkConfig          `yaml:"network,omitempty"`
+	Scenario       []*ScenarioActionConfig `yaml:"scenario,omitempty"`
+	Invariants     *InvariantsConfig       `yaml:"invariants,omitempty"`
+	Clock          *ClockConfig            `yaml:"clock,omitempty"`
 }
 
-func LoadConfig(f string, numberOfNodes uint) (*Config, error) {
+// ConfigModifier changes the loaded config before it is validated.
+type ConfigModifier func(*Config) error
+
+// LoadConfig loads the config file; if numberOfNodes is 0, the number of
+// nodes is decided by the nodes of config.
+func LoadConfig(f string, numberOfNodes uint, modifiers ...ConfigModifier) (*Config, error) {
 	log.Debug().
 		Uint("number_of_nodes", numberOfNodes).
 		Str("file", f).
@@ -40,6 +52,12 @@ func LoadConfig(f string, numberOfNodes uint) (*Config, error) {
 		return nil, err
 	}
 
+	for _, m := range modifiers {
+		if err := m(&config); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := config.IsValid(); err != nil {
 		return nil, err
 	}
@@ -56,10 +74,13 @@ func LoadConfig(f string, numberOfNodes uint) (*Config, error) {
 		}
 	}
 
-	if numberOfNodes < 1 && last < 1 {
-		return nil, xerrors.Errorf("number-of-nodes should be greater than 0")
-	} else {
+	if numberOfNodes < 1 {
 		numberOfNodes = last + 1
+	} else if numberOfNodes < last+1 {
+		return nil, xerrors.Errorf(
+			"number-of-nodes should not be less than the nodes of config; number-of-nodes=%d nodes=%d",
+			numberOfNodes, last+1,
+		)
 	}
 	config.NumberOfNodes_ = &numberOfNodes
 
@@ -98,6 +119,28 @@ func LoadConfig(f string, numberOfNodes uint) (*Config, error) {
 
 	config.Nodes = nodes
 
+	if err := config.Network.checkNodes(config.Nodes); err != nil {
+		return nil, err
+	}
+
+	for _, nc := range config.Nodes {
+		for _, a := range nc.Policy.Authorities {
+			if _, found := config.Nodes[a]; !found {
+				return nil, xerrors.Errorf("unknown node found in authorities; node=%q", a)
+			}
+		}
+	}
+
+	for _, a := range config.Scenario {
+		if a.Node == nil {
+			continue
+		}
+
+		if _, found := config.Nodes[*a.Node]; !found {
+			return nil, xerrors.Errorf("unknown node found in scenario; node=%q", *a.Node)
+		}
+	}
+
 	return &config, nil
 }
 
@@ -106,6 +149,9 @@ func (cn *Config) MarshalJSON() ([]byte, error) {
 		"global":          cn.Global,
 		"nodes":           cn.Nodes,
 		"number_of_nodes": cn.NumberOfNodes(),
+		"network":         cn.Network,
+		"invariants":      cn.Invariants,
+		"clock":           cn.Clock,
 	})
 }
 
@@ -113,6 +159,9 @@ func (cn *Config) MarshalZerologObject(e *zerolog.Event) {
 	e.Interface("global", cn.Global)
 	e.Interface("nodes", cn.Nodes)
 	e.Uint("number_of_nodes", cn.NumberOfNodes())
+	e.Interface("network", cn.Network)
+	e.Interface("invariants", cn.Invariants)
+	e.Interface("clock", cn.Clock)
 }
 
 func (cn *Config) String() string {
@@ -121,6 +170,16 @@ func (cn *Config) String() string {
 }
 
 func (cn *Config) IsValid() error {
+	if cn.Hash == nil {
+		cn.Hash = defaultHashConfig()
+	}
+
+	// NOTE hash algorithms should be set before any hash is made
+	if err := cn.Hash.IsValid(); err != nil {
+		return err
+	}
+	defer cn.Hash.SetDefaultAlgorithms()()
+
 	if cn.Global == nil {
 		cn.Global = defaultNodeConfig()
 	}
@@ -203,6 +262,40 @@ func (cn *Config) IsValid() error {
 		}
 	}
 
+	if cn.Network == nil {
+		cn.Network = defaultNetworkConfig()
+	}
+
+	if err := cn.Network.IsValid(); err != nil {
+		return err
+	}
+
+	if cn.Clock == nil {
+		cn.Clock = defaultClockConfig()
+	}
+
+	if err := cn.Clock.IsValid(); err != nil {
+		return err
+	}
+
+	if cn.Invariants == nil {
+		cn.Invariants = defaultInvariantsConfig()
+	}
+
+	if err := cn.Invariants.IsValid(); err != nil {
+		return err
+	}
+
+	for _, a := range cn.Scenario {
+		if a == nil {
+			return xerrors.Errorf("empty scenario action found")
+		}
+
+		if err := a.IsValid(cn.Network); err != nil {
+			return err
+		}
+	}
+
 	all, found := cn.Condition["all"]
 	if !found {
 		all = defaultConditionConfig()
@@ -225,6 +318,66 @@ func (cn *Config) NumberOfNodes() uint {
 	return *cn.NumberOfNodes_
 }
 
+// HashConfig sets the hash algorithms of the network; the algorithm of the
+// hint, which is not in Hints, is Default.
+type HashConfig struct {
+	Default    *string           `yaml:"default,omitempty"`
+	Hints      map[string]string `yaml:"hints,omitempty"`
+	algorithms *hash.Algorithms
+}
+
+func defaultHashConfig() *HashConfig {
+	d := hash.DoubleSHA256.String()
+
+	return &HashConfig{Default: &d}
+}
+
+func (hc *HashConfig) IsValid() error {
+	if hc.Default == nil {
+		d := hash.DoubleSHA256.String()
+		hc.Default = &d
+	}
+
+	def, err := hash.ParseAlgorithm(*hc.Default)
+	if err != nil {
+		return xerrors.Errorf("invalid hash default: %w", err)
+	}
+
+	as := hash.NewAlgorithms(def)
+	for hint, name := range hc.Hints {
+		a, err := hash.ParseAlgorithm(name)
+		if err != nil {
+			return xerrors.Errorf("invalid hash of hint, %q: %w", hint, err)
+		}
+
+		if err := as.Set(hint, a); err != nil {
+			return err
+		}
+	}
+
+	hc.algorithms = as
+
+	return nil
+}
+
+// SetDefaultAlgorithms sets hash.DefaultAlgorithms by the config, because the
+// nodes make the new hashes by hash.DefaultAlgorithms; the returned function
+// restores the previous one.
+func (hc *HashConfig) SetDefaultAlgorithms() func() {
+	previous := hash.DefaultAlgorithms.Copy()
+	hash.DefaultAlgorithms.Load(hc.algorithms)
+
+	return func() {
+		hash.DefaultAlgorithms.Load(previous)
+	}
+}
+
+// Algorithms is the hash Algorithms of the network; the nodes check the hashes
+// of the incoming seals with it.
+func (hc *HashConfig) Algorithms() *hash.Algorithms {
+	return hc.algorithms
+}
+
 type NodeConfig struct {
 	Policy  *PolicyConfig  `yaml:",omitempty"`
 	Blocks  []*BlockConfig `yaml:"blocks,omitempty"`
@@ -320,6 +473,9 @@ type PolicyConfig struct {
 	TimeoutWaitVoteResultInJoin       *time.Duration `yaml:"timeout_wait_vote_result_in_join,omitempty"`
 	TimeoutWaitBallot                 *time.Duration `yaml:"timeout_wait_ballot,omitempty"`
 	TimeoutWaitINITBallot             *time.Duration `yaml:"timeout_wait_init_ballot,omitempty"`
+	AllConfirm                        *bool          `yaml:"all_confirm,omitempty"`
+	PipelineProposal                  *bool          `yaml:"pipeline_proposal,omitempty"`
+	Authorities                       []string       `yaml:"authorities,omitempty"`
 }
 
 func defaultPolicyConfig() *PolicyConfig {
@@ -328,6 +484,8 @@ func defaultPolicyConfig() *PolicyConfig {
 	timeoutWaitVoteResultInJoin := time.Second * 3
 	timeoutWaitBallot := time.Second * 3
 	timeoutWaitINITBallot := time.Second * 3
+	allConfirm := false
+	pipelineProposal := false
 
 	return &PolicyConfig{
 		Threshold:                         &th,
@@ -335,6 +493,8 @@ func defaultPolicyConfig() *PolicyConfig {
 		TimeoutWaitVoteResultInJoin:       &timeoutWaitVoteResultInJoin,
 		TimeoutWaitBallot:                 &timeoutWaitBallot,
 		TimeoutWaitINITBallot:             &timeoutWaitINITBallot,
+		AllConfirm:                        &allConfirm,
+		PipelineProposal:                  &pipelineProposal,
 	}
 }
 
@@ -375,9 +535,33 @@ func (pc *PolicyConfig) IsValid(global *PolicyConfig) error {
 		pc.TimeoutWaitINITBallot = global.TimeoutWaitINITBallot
 	}
 
+	if pc.AllConfirm == nil {
+		pc.AllConfirm = global.AllConfirm
+	}
+
+	if pc.PipelineProposal == nil {
+		pc.PipelineProposal = global.PipelineProposal
+	}
+
+	if pc.Authorities == nil {
+		pc.Authorities = global.Authorities
+	}
+
 	return nil
 }
 
+func (pc *PolicyConfig) Policy() isaac.Policy {
+	return isaac.Policy{
+		Threshold:                         *pc.Threshold,
+		IntervalBroadcastINITBallotInJoin: *pc.IntervalBroadcastINITBallotInJoin,
+		TimeoutWaitVoteResultInJoin:       *pc.TimeoutWaitVoteResultInJoin,
+		TimeoutWaitBallot:                 *pc.TimeoutWaitBallot,
+		TimeoutWaitINITBallot:             *pc.TimeoutWaitINITBallot,
+		AllConfirm:                        *pc.AllConfirm,
+		PipelineProposal:                  *pc.PipelineProposal,
+	}
+}
+
 type BlockConfig struct {
 	Height *isaac.Height
 	Round  *isaac.Round
@@ -508,6 +692,18 @@ func (sc *SuffrageConfig) IsValid(global *SuffrageConfig) error {
 		//
 	}
 
+	if v, found := (*sc)["liveness_window"]; found {
+		if _, ok := v.(int); !ok {
+			return xerrors.Errorf("`liveness_window` must be int")
+		}
+
+		if v, found := (*sc)["liveness_max_missed"]; !found {
+			(*sc)["liveness_max_missed"] = 1
+		} else if _, ok := v.(int); !ok {
+			return xerrors.Errorf("`liveness_max_missed` must be int")
+		}
+	}
+
 	if v, found := (*sc)["number_of_acting"]; !found {
 		log.Warn().Msg("number_of_acting is missing; the total number of nodes will be number_of_acting")
 		(*sc)["number_of_acting"] = 0
@@ -562,6 +758,24 @@ func (sc *ProposalMakerConfig) IsValid(global *ProposalMakerConfig) error {
 		} else if _, err := time.ParseDuration(d); err != nil {
 			return err
 		}
+	case "ConditionProposalMaker":
+		if s, found := (*sc)["delay"]; !found {
+			(*sc)["delay"] = (*defaultProposalMakerConfig())["delay"]
+		} else if d, ok := s.(string); !ok {
+			return xerrors.Errorf("`delay` must be time.Duration string format; %v", (*sc)["delay"])
+		} else if _, err := time.ParseDuration(d); err != nil {
+			return err
+		}
+
+		if s, found := (*sc)["conditions"]; !found {
+			log.Warn().Msg("conditions is missing")
+		} else {
+			for _, c := range s.(ProposalMakerConfig) {
+				if _, err := parseConditionProposalHandler(c.(ProposalMakerConfig)); err != nil {
+					return err
+				}
+			}
+		}
 	}
 
 	return nil
@@ -702,3 +916,486 @@ func parseConditionHandler(m map[string]interface{}) (contest_module.ConditionBa
 
 	return contest_module.NewConditionBallotHandler(cc, action), nil
 }
+
+func parseConditionProposalHandler(m map[string]interface{}) (contest_module.ConditionProposalHandler, error) {
+	query, found := m["condition"].(string)
+	if !found {
+		return contest_module.ConditionProposalHandler{}, xerrors.Errorf("condition is missing in condition block")
+	}
+
+	action, found := m["action"].(string)
+	if !found {
+		return contest_module.ConditionProposalHandler{}, xerrors.Errorf("action is missing in condition block")
+	}
+
+	var nodes []string
+	if s, found := m["nodes"]; found {
+		l, ok := s.([]interface{})
+		if !ok {
+			return contest_module.ConditionProposalHandler{}, xerrors.Errorf("`nodes` must be list; %v", s)
+		}
+
+		for _, n := range l {
+			name, ok := n.(string)
+			if !ok {
+				return contest_module.ConditionProposalHandler{}, xerrors.Errorf("`nodes` must be list of node name; %v", s)
+			}
+			nodes = append(nodes, name)
+		}
+	}
+
+	cc, err := condition.NewConditionChecker(query)
+	if err != nil {
+		return contest_module.ConditionProposalHandler{}, err
+	}
+
+	return contest_module.NewConditionProposalHandler(cc, action, nodes)
+}
+
+// NetworkConfig declares the faults of the network between nodes.
+// * default: the fault of all the links
+// * nodes: the fault of the links from and to the node
+// * links: the fault of the link; `n0->n1` is the link from n0 to n1, `n0<->n1`
+// is the both links between n0 and n1
+// * partitions: the named partitions
+// * interceptors: the named interceptors, which drop, delay, duplicate or
+// mutate the seals matched with the condition
+type NetworkConfig struct {
+	Default      *LinkFaultConfig              `yaml:"default,omitempty"`
+	Nodes        map[string]*LinkFaultConfig   `yaml:"nodes,omitempty"`
+	Links        map[string]*LinkFaultConfig   `yaml:"links,omitempty"`
+	Partitions   map[string]*PartitionConfig   `yaml:"partitions,omitempty"`
+	Interceptors map[string]*InterceptorConfig `yaml:"interceptors,omitempty"`
+}
+
+func defaultNetworkConfig() *NetworkConfig {
+	return &NetworkConfig{Default: defaultLinkFaultConfig()}
+}
+
+func (nc *NetworkConfig) IsValid() error {
+	if nc.Default == nil {
+		nc.Default = defaultLinkFaultConfig()
+	}
+
+	if err := nc.Default.IsValid(defaultLinkFaultConfig()); err != nil {
+		return xerrors.Errorf("invalid network default: %w", err)
+	}
+
+	for name, c := range nc.Nodes {
+		if c == nil {
+			c = &LinkFaultConfig{}
+			nc.Nodes[name] = c
+		}
+
+		if err := c.IsValid(nc.Default); err != nil {
+			return xerrors.Errorf("invalid network of node, %q: %w", name, err)
+		}
+	}
+
+	for name, c := range nc.Links {
+		if _, _, _, err := parseLinkName(name); err != nil {
+			return err
+		}
+
+		if c == nil {
+			c = &LinkFaultConfig{}
+			nc.Links[name] = c
+		}
+
+		if err := c.IsValid(nc.Default); err != nil {
+			return xerrors.Errorf("invalid network of link, %q: %w", name, err)
+		}
+	}
+
+	for name, c := range nc.Partitions {
+		if c == nil {
+			return xerrors.Errorf("empty partition, %q", name)
+		}
+
+		if _, err := c.Partition(name); err != nil {
+			return err
+		}
+	}
+
+	for name, c := range nc.Interceptors {
+		if c == nil {
+			return xerrors.Errorf("empty interceptor, %q", name)
+		}
+
+		if _, err := c.Interceptor(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkNodes checks the node names in the config are known.
+func (nc *NetworkConfig) checkNodes(nodes map[string]*NodeConfig) error {
+	check := func(n string) error {
+		if _, found := nodes[n]; !found {
+			return xerrors.Errorf("unknown node found in network config; node=%q", n)
+		}
+
+		return nil
+	}
+
+	for n := range nc.Nodes {
+		if err := check(n); err != nil {
+			return err
+		}
+	}
+
+	for name := range nc.Links {
+		from, to, _, _ := parseLinkName(name)
+		for _, n := range []string{from, to} {
+			if err := check(n); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, c := range nc.Partitions {
+		for _, g := range c.Groups {
+			for _, n := range g {
+				if err := check(n); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (nc *NetworkConfig) Faults() (*contest_module.NetworkFaults, error) {
+	nf := contest_module.NewNetworkFaults(nc.Default.LinkFault())
+
+	for name, c := range nc.Nodes {
+		_ = nf.SetNode(name, c.LinkFault())
+	}
+
+	for name, c := range nc.Links {
+		from, to, both, err := parseLinkName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		_ = nf.SetLink(from, to, c.LinkFault())
+		if both {
+			_ = nf.SetLink(to, from, c.LinkFault())
+		}
+	}
+
+	for name, c := range nc.Partitions {
+		pt, err := c.Partition(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := nf.AddPartition(pt); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, c := range nc.Interceptors {
+		ic, err := c.Interceptor(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := nf.Interceptors().Add(ic); err != nil {
+			return nil, err
+		}
+	}
+
+	return nf, nil
+}
+
+func parseLinkName(name string) (string, string, bool, error) {
+	for _, sep := range []string{"<->", "->"} {
+		s := strings.SplitN(name, sep, 2)
+		if len(s) != 2 {
+			continue
+		}
+
+		from, to := strings.TrimSpace(s[0]), strings.TrimSpace(s[1])
+		if len(from) < 1 || len(to) < 1 || from == to {
+			break
+		}
+
+		return from, to, sep == "<->", nil
+	}
+
+	return "", "", false, xerrors.Errorf("invalid link name, %q; link should be `<node>-><node>` or `<node><-><node>`", name)
+}
+
+type LinkFaultConfig struct {
+	Latency      *LatencyConfig `yaml:"latency,omitempty"`
+	Drop         *float64       `yaml:"drop,omitempty"`
+	Duplicate    *float64       `yaml:"duplicate,omitempty"`
+	Reorder      *float64       `yaml:"reorder,omitempty"`
+	ReorderDelay *time.Duration `yaml:"reorder_delay,omitempty"`
+}
+
+func defaultLinkFaultConfig() *LinkFaultConfig {
+	lf := contest_module.NewLinkFault()
+
+	return &LinkFaultConfig{
+		Latency:      defaultLatencyConfig(),
+		Drop:         &lf.Drop,
+		Duplicate:    &lf.Duplicate,
+		Reorder:      &lf.Reorder,
+		ReorderDelay: &lf.ReorderDelay,
+	}
+}
+
+func (lc *LinkFaultConfig) IsValid(global *LinkFaultConfig) error {
+	if lc.Latency == nil {
+		lc.Latency = global.Latency
+	} else if err := lc.Latency.IsValid(global.Latency); err != nil {
+		return err
+	}
+
+	if lc.Drop == nil {
+		lc.Drop = global.Drop
+	}
+
+	if lc.Duplicate == nil {
+		lc.Duplicate = global.Duplicate
+	}
+
+	if lc.Reorder == nil {
+		lc.Reorder = global.Reorder
+	}
+
+	if lc.ReorderDelay == nil {
+		lc.ReorderDelay = global.ReorderDelay
+	}
+
+	return lc.LinkFault().IsValid()
+}
+
+func (lc *LinkFaultConfig) LinkFault() contest_module.LinkFault {
+	return contest_module.LinkFault{
+		Latency:      lc.Latency.Latency(),
+		Drop:         *lc.Drop,
+		Duplicate:    *lc.Duplicate,
+		Reorder:      *lc.Reorder,
+		ReorderDelay: *lc.ReorderDelay,
+	}
+}
+
+type LatencyConfig struct {
+	Distribution *string        `yaml:"distribution,omitempty"`
+	Min          *time.Duration `yaml:"min,omitempty"`
+	Max          *time.Duration `yaml:"max,omitempty"`
+	Mean         *time.Duration `yaml:"mean,omitempty"`
+	StdDev       *time.Duration `yaml:"stddev,omitempty"`
+}
+
+func defaultLatencyConfig() *LatencyConfig {
+	d := "fixed"
+	var zero time.Duration
+
+	return &LatencyConfig{
+		Distribution: &d,
+		Min:          &zero,
+		Max:          &zero,
+		Mean:         &zero,
+		StdDev:       &zero,
+	}
+}
+
+func (lc *LatencyConfig) IsValid(global *LatencyConfig) error {
+	if global == nil {
+		global = defaultLatencyConfig()
+	}
+
+	if lc.Distribution == nil {
+		lc.Distribution = global.Distribution
+	}
+
+	if lc.Min == nil {
+		lc.Min = global.Min
+	}
+
+	if lc.Max == nil {
+		lc.Max = global.Max
+	}
+
+	if lc.Mean == nil {
+		lc.Mean = global.Mean
+	}
+
+	if lc.StdDev == nil {
+		lc.StdDev = global.StdDev
+	}
+
+	return lc.Latency().IsValid()
+}
+
+func (lc *LatencyConfig) Latency() contest_module.Latency {
+	return contest_module.Latency{
+		Distribution: *lc.Distribution,
+		Min:          *lc.Min,
+		Max:          *lc.Max,
+		Mean:         *lc.Mean,
+		StdDev:       *lc.StdDev,
+	}
+}
+
+// PartitionConfig divides the nodes into the groups from `from` until `until`
+// after the nodes started; without `from` and `until`, the partition is
+// active from the start.
+type PartitionConfig struct {
+	Groups [][]string     `yaml:"groups"`
+	From   *time.Duration `yaml:"from,omitempty"`
+	Until  *time.Duration `yaml:"until,omitempty"`
+}
+
+func (pc *PartitionConfig) Partition(name string) (*contest_module.Partition, error) {
+	var from, until time.Duration
+	if pc.From != nil {
+		from = *pc.From
+	}
+
+	if pc.Until != nil {
+		until = *pc.Until
+	}
+
+	return contest_module.NewPartition(name, pc.Groups, from, until)
+}
+
+// InterceptorConfig intercepts the seals, which match the `condition`, in the
+// links between nodes; the condition can use `from`, `to`, `seal.type`,
+// `seal.hash`, `ballot.*` and `proposal.*`.
+// * action: one of drop, delay, duplicate and mutate
+// * delay: the duration for `delay`
+// * fields: the fields of ballot or proposal for `mutate`
+type InterceptorConfig struct {
+	Condition *string        `yaml:"condition"`
+	Action    *string        `yaml:"action"`
+	Delay     *time.Duration `yaml:"delay,omitempty"`
+	Fields    []string       `yaml:"fields,omitempty"`
+}
+
+func (ic *InterceptorConfig) Interceptor(name string) (*contest_module.Interceptor, error) {
+	if ic.Condition == nil {
+		return nil, xerrors.Errorf("condition is missing in interceptor, %q", name)
+	}
+
+	if ic.Action == nil {
+		return nil, xerrors.Errorf("action is missing in interceptor, %q", name)
+	}
+
+	cc, err := condition.NewConditionChecker(*ic.Condition)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid condition of interceptor, %q: %w", name, err)
+	}
+
+	var delay time.Duration
+	if ic.Delay != nil {
+		delay = *ic.Delay
+	}
+
+	return contest_module.NewInterceptor(name, cc, *ic.Action, delay, ic.Fields)
+}
+
+// InvariantsConfig enables the invariants, which are checked on every run;
+// without `invariants`, all the invariants are checked.
+// * safety: no two nodes accept different blocks at the same height
+// * majority: every accepted block has the majority votes
+// * liveness_timeout: the highest height of nodes and the height of the node,
+// which is behind the highest, should be increased within the timeout; 0
+// disables it
+type InvariantsConfig struct {
+	Safety          *bool          `yaml:"safety,omitempty"`
+	Majority        *bool          `yaml:"majority,omitempty"`
+	LivenessTimeout *time.Duration `yaml:"liveness_timeout,omitempty"`
+}
+
+func defaultInvariantsConfig() *InvariantsConfig {
+	safety := true
+	majority := true
+	livenessTimeout := time.Second * 30
+
+	return &InvariantsConfig{
+		Safety:          &safety,
+		Majority:        &majority,
+		LivenessTimeout: &livenessTimeout,
+	}
+}
+
+func (ic *InvariantsConfig) IsValid() error {
+	d := defaultInvariantsConfig()
+
+	if ic.Safety == nil {
+		ic.Safety = d.Safety
+	}
+
+	if ic.Majority == nil {
+		ic.Majority = d.Majority
+	}
+
+	if ic.LivenessTimeout == nil {
+		ic.LivenessTimeout = d.LivenessTimeout
+	} else if *ic.LivenessTimeout < 0 {
+		return xerrors.Errorf("`liveness_timeout` should not be negative; liveness_timeout=%q", *ic.LivenessTimeout)
+	}
+
+	return nil
+}
+
+var ClockTypes = []string{
+	"real",
+	"simulated",
+}
+
+// ClockConfig selects the clock of nodes; the timers and timeouts of nodes
+// follow the clock.
+// * real: the real clock
+// * simulated: the simulated clock; when no new timer is added during `idle`
+// of real time, the time moves to the next timer at once
+type ClockConfig struct {
+	Type *string        `yaml:"type,omitempty"`
+	Idle *time.Duration `yaml:"idle,omitempty"`
+}
+
+func defaultClockConfig() *ClockConfig {
+	t := "real"
+	idle := time.Millisecond * 20
+
+	return &ClockConfig{Type: &t, Idle: &idle}
+}
+
+func (cc *ClockConfig) IsValid() error {
+	d := defaultClockConfig()
+
+	if cc.Type == nil {
+		cc.Type = d.Type
+	}
+
+	var found bool
+	for _, t := range ClockTypes {
+		if t == *cc.Type {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return xerrors.Errorf("unknown clock type found; type=%q", *cc.Type)
+	}
+
+	if cc.Idle == nil {
+		cc.Idle = d.Idle
+	} else if *cc.Idle <= 0 {
+		return xerrors.Errorf("`idle` should be greater than zero; idle=%q", *cc.Idle)
+	}
+
+	return nil
+}
+
+func (cc *ClockConfig) IsSimulated() bool {
+	return *cc.Type == "simulated"
+}
@@ -22,9 +22,23 @@ var (
 	flagTrace         string
 	flagExitAfter     time.Duration
 	flagNumberOfNodes uint = 3
+	flagSeed          int64
 	flagQuiet         bool
 	flagQueries       []string
 	flagJSONPretty    bool
+	flagKeystoreType  string = "stellar"
+	flagSignerSocket  string = "./signer.sock"
+
+	flagSweepDuration              time.Duration = time.Second * 10
+	flagSweepSample                uint
+	flagSweepNodes                 []string
+	flagSweepSeeds                 []string
+	flagSweepThreshold             []string
+	flagSweepTimeoutWaitBallot     []string
+	flagSweepTimeoutWaitINITBallot []string
+	flagSweepDrop                  []string
+	flagSweepDuplicate             []string
+	flagSweepReorder               []string
 )
 
 type FlagLogLevel struct {
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+
+	contest_module "github.com/spikeekips/mitum/contrib/contest/module"
+	"github.com/spikeekips/mitum/isaac"
+	"github.com/spikeekips/mitum/node"
+)
+
+var newBlockLogMessage = []byte(`new block created`)
+
+type invariantHashLog struct {
+	Hash string `json:"hash"`
+}
+
+// invariantBlockLog is the log of the new block of consensus state handler.
+type invariantBlockLog struct {
+	Node  string `json:"node"`
+	Block struct {
+		Hash   invariantHashLog `json:"hash"`
+		Height uint64           `json:"height"`
+		Round  uint64           `json:"round"`
+	} `json:"block"`
+	VR struct {
+		Stage     string `json:"stage"`
+		Agreement string `json:"agreement"`
+		Records   []struct {
+			Node  invariantHashLog `json:"node"`
+			Block invariantHashLog `json:"block"`
+		} `json:"records"`
+	} `json:"vr"`
+}
+
+// Invariants checks the invariants with the new blocks from the log of
+// nodes.
+type Invariants struct {
+	*contest_module.InvariantChecker
+	aliases map[string]string
+}
+
+func NewInvariants(config *Config, nodeList []node.Node) (*Invariants, error) {
+	thresholds := map[string]*isaac.Threshold{}
+	for _, n := range nodeList {
+		thr, _, err := newThreshold(config, config.Nodes[n.Alias()])
+		if err != nil {
+			return nil, err
+		}
+
+		thresholds[n.Alias()] = thr
+	}
+
+	aliases := map[string]string{}
+	for _, n := range nodeList {
+		aliases[n.Address().String()] = n.Alias()
+	}
+
+	ic := contest_module.NewInvariantChecker(
+		*config.Invariants.Safety,
+		*config.Invariants.Majority,
+		*config.Invariants.LivenessTimeout,
+		func(node string, stage isaac.Stage) uint {
+			thr, found := thresholds[node]
+			if !found {
+				return 0
+			}
+
+			_, threshold := thr.Get(stage)
+
+			return threshold
+		},
+	)
+
+	for _, n := range nodeList {
+		_ = ic.AddNodes(n.Alias())
+	}
+
+	return &Invariants{InvariantChecker: ic, aliases: aliases}, nil
+}
+
+func (iv *Invariants) Write(b []byte) (int, error) {
+	if !bytes.Contains(b, newBlockLogMessage) || iv.IsStopped() {
+		return len(b), nil
+	}
+
+	var l invariantBlockLog
+	if err := json.Unmarshal(b, &l); err != nil || len(l.Node) < 1 || len(l.Block.Hash.Hash) < 1 {
+		return len(b), nil
+	}
+
+	stage, err := isaac.StageFromString(l.VR.Stage)
+	if err != nil {
+		return len(b), nil
+	}
+
+	var voters []string
+	for _, r := range l.VR.Records {
+		if r.Block.Hash != l.Block.Hash.Hash {
+			continue
+		}
+
+		alias, found := iv.aliases[r.Node.Hash]
+		if !found {
+			alias = r.Node.Hash
+		}
+		voters = append(voters, alias)
+	}
+
+	_ = iv.NewBlock(contest_module.InvariantBlock{
+		Node:      l.Node,
+		Height:    l.Block.Height,
+		Round:     l.Block.Round,
+		Hash:      l.Block.Hash.Hash,
+		Stage:     stage,
+		Agreement: l.VR.Agreement,
+		Voters:    voters,
+	})
+
+	return len(b), nil
+}
+
+// Report prints the violations; it returns false if any violation found.
+func (iv *Invariants) Report(w io.Writer) bool {
+	vs := iv.Violations()
+	if len(vs) < 1 {
+		_, _ = fmt.Fprintln(w, color.New(color.FgGreen).Sprint("invariants: no violation"))
+
+		return true
+	}
+
+	_, _ = fmt.Fprintln(w, color.New(color.FgRed).Sprintf("invariants: %d violation(s)", len(vs)))
+	for _, v := range vs {
+		_, _ = fmt.Fprintf(
+			w,
+			"%s %s height=%d nodes=%v: %s\n",
+			color.New(color.FgRed).Sprint("violated:"),
+			v.Invariant,
+			v.Height,
+			v.Nodes,
+			v.Message,
+		)
+	}
+
+	return false
+}
@@ -23,6 +23,7 @@ var (
 	traceFile      *os.File
 	log            zerolog.Logger
 	logOutput      io.Writer
+	logDir         string
 	stdoutLog      zerolog.Logger
 )
 
@@ -46,9 +47,11 @@ var rootCmd = &cobra.Command{
 			var dir string
 			switch mode := fi.Mode(); {
 			case mode.IsDir():
+				dir = FlagLogOut
 			case mode.IsRegular():
-				dir = filepath.Base(FlagLogOut)
+				dir = filepath.Dir(FlagLogOut)
 			}
+			logDir = dir
 
 			if f, err := os.Create(filepath.Join(dir, "all.log")); err != nil {
 				cmd.Println("Error:", err.Error())
@@ -0,0 +1,77 @@
+package contest_module
+
+import (
+	"sync"
+
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/isaac"
+)
+
+// SwitchBallotMaker delegates to the current BallotMaker, which can be
+// replaced while node is running.
+type SwitchBallotMaker struct {
+	sync.RWMutex
+	bm isaac.BallotMaker
+}
+
+func NewSwitchBallotMaker(bm isaac.BallotMaker) *SwitchBallotMaker {
+	return &SwitchBallotMaker{bm: bm}
+}
+
+func (sb *SwitchBallotMaker) BallotMaker() isaac.BallotMaker {
+	sb.RLock()
+	defer sb.RUnlock()
+
+	return sb.bm
+}
+
+func (sb *SwitchBallotMaker) Switch(bm isaac.BallotMaker) {
+	sb.Lock()
+	defer sb.Unlock()
+
+	sb.bm = bm
+}
+
+func (sb *SwitchBallotMaker) INIT(
+	lastBlock hash.Hash,
+	lastRound isaac.Round,
+	nextHeight isaac.Height,
+	nextBlock hash.Hash,
+	currentRound isaac.Round,
+	currentProposal hash.Hash,
+) (isaac.Ballot, error) {
+	return sb.BallotMaker().INIT(lastBlock, lastRound, nextHeight, nextBlock, currentRound, currentProposal)
+}
+
+func (sb *SwitchBallotMaker) SIGN(
+	lastBlock hash.Hash,
+	lastRound isaac.Round,
+	nextHeight isaac.Height,
+	nextBlock hash.Hash,
+	currentRound isaac.Round,
+	currentProposal hash.Hash,
+) (isaac.Ballot, error) {
+	return sb.BallotMaker().SIGN(lastBlock, lastRound, nextHeight, nextBlock, currentRound, currentProposal)
+}
+
+func (sb *SwitchBallotMaker) ACCEPT(
+	lastBlock hash.Hash,
+	lastRound isaac.Round,
+	nextHeight isaac.Height,
+	nextBlock hash.Hash,
+	currentRound isaac.Round,
+	currentProposal hash.Hash,
+) (isaac.Ballot, error) {
+	return sb.BallotMaker().ACCEPT(lastBlock, lastRound, nextHeight, nextBlock, currentRound, currentProposal)
+}
+
+func (sb *SwitchBallotMaker) ALLCONFIRM(
+	lastBlock hash.Hash,
+	lastRound isaac.Round,
+	nextHeight isaac.Height,
+	nextBlock hash.Hash,
+	currentRound isaac.Round,
+	currentProposal hash.Hash,
+) (isaac.Ballot, error) {
+	return sb.BallotMaker().ALLCONFIRM(lastBlock, lastRound, nextHeight, nextBlock, currentRound, currentProposal)
+}
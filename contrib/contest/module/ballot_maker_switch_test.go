@@ -0,0 +1,39 @@
+package contest_module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/spikeekips/mitum/isaac"
+	"github.com/spikeekips/mitum/node"
+)
+
+type testSwitchBallotMaker struct {
+	suite.Suite
+}
+
+func (t *testSwitchBallotMaker) TestSwitch() {
+	home := node.NewRandomHome()
+	sb := NewSwitchBallotMaker(isaac.NewDefaultBallotMaker(home))
+
+	height := isaac.NewBlockHeight(1)
+	round := isaac.Round(0)
+	previousBlock := NewRandomBlockHash()
+	newBlock := NewRandomBlockHash()
+
+	ballot, err := sb.INIT(previousBlock, round, height, newBlock, round, NewRandomProposalHash())
+	t.NoError(err)
+	t.True(ballot.Block().Equal(newBlock))
+
+	db := NewDamangedBallotMaker(home).AddPoint(height.String(), round.String(), isaac.StageINIT.String())
+	sb.Switch(db)
+
+	ballot, err = sb.INIT(previousBlock, round, height, newBlock, round, NewRandomProposalHash())
+	t.NoError(err)
+	t.False(ballot.Block().Equal(newBlock))
+}
+
+func TestSwitchBallotMaker(t *testing.T) {
+	suite.Run(t, new(testSwitchBallotMaker))
+}
@@ -1,16 +1,13 @@
 package contest_module
 
 import (
-	"crypto/rand"
-	mrand "math/rand"
-
 	"github.com/spikeekips/mitum/hash"
 	"github.com/spikeekips/mitum/isaac"
 )
 
 func NewRandomProposalHash() hash.Hash {
 	b := make([]byte, 4)
-	_, _ = rand.Read(b)
+	random.Read(b)
 
 	h, _ := isaac.NewProposalHash(b)
 	return h
@@ -28,7 +25,7 @@ func NewBlock(height isaac.Height, round isaac.Round) isaac.Block {
 
 func NewRandomBlockHash() hash.Hash {
 	b := make([]byte, 4)
-	_, _ = rand.Read(b)
+	random.Read(b)
 
 	h, _ := isaac.NewBlockHash(b)
 
@@ -56,9 +53,9 @@ func NewRandomNextBlock(bk isaac.Block) isaac.Block {
 }
 
 func NewRandomHeight() isaac.Height {
-	return isaac.NewBlockHeight(uint64(mrand.Intn(100)))
+	return isaac.NewBlockHeight(uint64(random.Intn(100)))
 }
 
 func NewRandomRound() isaac.Round {
-	return isaac.Round(uint64(mrand.Intn(100)))
+	return isaac.Round(uint64(random.Intn(100)))
 }
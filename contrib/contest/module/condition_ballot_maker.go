@@ -120,6 +120,8 @@ func (cb ConditionBallotMaker) modifyBallot(
 		cbFunc = cb.DefaultBallotMaker.SIGN
 	case isaac.StageACCEPT:
 		cbFunc = cb.DefaultBallotMaker.ACCEPT
+	case isaac.StageALLCONFIRM:
+		cbFunc = cb.DefaultBallotMaker.ALLCONFIRM
 	default:
 		err := xerrors.Errorf("unknown stage found")
 		cb.Log().Error().
@@ -175,3 +177,17 @@ func (cb ConditionBallotMaker) ACCEPT(
 		isaac.StageACCEPT,
 	)
 }
+
+func (cb ConditionBallotMaker) ALLCONFIRM(
+	lastBlock hash.Hash,
+	lastRound isaac.Round,
+	nextHeight isaac.Height,
+	nextBlock hash.Hash,
+	currentRound isaac.Round,
+	currentProposal hash.Hash,
+) (isaac.Ballot, error) {
+	return cb.modifyBallot(
+		lastBlock, lastRound, nextHeight, nextBlock, currentRound, currentProposal,
+		isaac.StageALLCONFIRM,
+	)
+}
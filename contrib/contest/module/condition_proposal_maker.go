@@ -0,0 +1,262 @@
+package contest_module
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/contrib/contest/condition"
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/isaac"
+	"github.com/spikeekips/mitum/seal"
+)
+
+var ConditionProposalActions = []string{
+	"withhold",
+	"wrong-height",
+	"wrong-round",
+	"stale-last_block",
+	"equivocate",
+}
+
+type ConditionProposalHandler struct {
+	checker condition.ConditionChecker
+	action  string
+	nodes   []string
+}
+
+func NewConditionProposalHandler(
+	checker condition.ConditionChecker,
+	action string,
+	nodes []string,
+) (ConditionProposalHandler, error) {
+	var found bool
+	for _, a := range ConditionProposalActions {
+		if a == action {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ConditionProposalHandler{}, xerrors.Errorf("unknown proposal action found: %v", action)
+	}
+
+	if action == "equivocate" && len(nodes) < 1 {
+		return ConditionProposalHandler{}, xerrors.Errorf("`nodes` must be given for `%s`", action)
+	}
+
+	return ConditionProposalHandler{checker: checker, action: action, nodes: nodes}, nil
+}
+
+func (ch ConditionProposalHandler) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"query":  ch.checker.Query(),
+		"action": ch.action,
+		"nodes":  ch.nodes,
+	})
+}
+
+// ConditionProposalMaker makes the wrong proposal when the condition is
+// matched.
+// * withhold: does not make proposal
+// * wrong-height, wrong-round: makes the proposal for the next height or round
+// * stale-last_block: makes the proposal with the previous block as last block
+// * equivocate: makes another valid proposal, which is sent to the `nodes`; the
+// alternate drops the last transaction of the proposal and is signed at the
+// different time, so it can be accepted by the other nodes.
+// ConditionProposalMaker should be set to FaultNetwork as SealReplacer
+// The conditions are evaluated by the order of name, so the result of same
+// conditions is always same.
+type ConditionProposalMaker struct {
+	sync.RWMutex
+	*common.Logger
+	pm         isaac.ProposalMaker
+	homeState  *isaac.HomeState
+	conditions map[string]ConditionProposalHandler
+	names      []string
+	alternates map[string]conditionProposalAlternate
+}
+
+type conditionProposalAlternate struct {
+	proposal isaac.Proposal
+	nodes    map[string]struct{}
+}
+
+func NewConditionProposalMaker(
+	pm isaac.ProposalMaker,
+	homeState *isaac.HomeState,
+	conditions map[string]ConditionProposalHandler,
+) *ConditionProposalMaker {
+	names := make([]string, 0, len(conditions))
+	for name := range conditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return &ConditionProposalMaker{
+		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
+			return c.Str("module", "condition-proposal_maker")
+		}),
+		pm:         pm,
+		homeState:  homeState,
+		conditions: conditions,
+		names:      names,
+		alternates: map[string]conditionProposalAlternate{},
+	}
+}
+
+func (cp *ConditionProposalMaker) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"type":       "ConditionProposalMaker",
+		"conditions": cp.conditions,
+	})
+}
+
+func (cp *ConditionProposalMaker) Make(height isaac.Height, round isaac.Round, lastBlock hash.Hash) (
+	isaac.Proposal, error,
+) {
+	li, err := condition.NewLogItemFromMap(
+		map[string]interface{}{
+			"node":  cp.homeState.Home().Alias(),
+			"state": cp.homeState.State().String(),
+			"block": map[string]interface{}{
+				"height":   cp.homeState.Block().Height().Uint64(),
+				"round":    cp.homeState.Block().Round().Uint64(),
+				"proposal": cp.homeState.Block().Proposal().String(),
+			},
+			"previousBlock": map[string]interface{}{
+				"height":   cp.homeState.PreviousBlock().Height().Uint64(),
+				"round":    cp.homeState.PreviousBlock().Round().Uint64(),
+				"proposal": cp.homeState.PreviousBlock().Proposal().String(),
+			},
+			"proposal": map[string]interface{}{
+				"height":     height.Uint64(),
+				"round":      round.Uint64(),
+				"last_block": lastBlock.String(),
+			},
+		})
+	if err != nil {
+		return isaac.Proposal{}, err
+	}
+
+	var equivocate []string
+	for _, name := range cp.names {
+		c := cp.conditions[name]
+		if !c.checker.Check(li) {
+			continue
+		}
+
+		cp.Log().Debug().
+			Str("checker", name).
+			Str("query", c.checker.Query()).
+			Str("action", c.action).
+			RawJSON("data", li.Bytes()).
+			Msg("condition matched")
+
+		switch c.action {
+		case "withhold":
+			return isaac.Proposal{}, xerrors.Errorf("proposal withheld by force")
+		case "wrong-height":
+			height = height.Add(1)
+		case "wrong-round":
+			round++
+		case "stale-last_block":
+			lastBlock = cp.homeState.PreviousBlock().Hash()
+		case "equivocate":
+			equivocate = append(equivocate, c.nodes...)
+		}
+	}
+
+	proposal, err := cp.pm.Make(height, round, lastBlock)
+	if err != nil {
+		return isaac.Proposal{}, err
+	}
+
+	if len(equivocate) > 0 {
+		if err := cp.equivocate(proposal, equivocate); err != nil {
+			return isaac.Proposal{}, err
+		}
+	}
+
+	return proposal, nil
+}
+
+func (cp *ConditionProposalMaker) equivocate(proposal isaac.Proposal, nodes []string) error {
+	// NOTE the alternate should be valid for the other nodes, so the
+	// transactions are the known ones, which the proposal already has.
+	transactions := proposal.Transactions()
+	if len(transactions) > 0 {
+		transactions = transactions[:len(transactions)-1]
+	}
+
+	alternate, err := isaac.NewProposal(
+		proposal.Height(),
+		proposal.Round(),
+		proposal.LastBlock(),
+		proposal.Proposer(),
+		transactions,
+	)
+	if err != nil {
+		return err
+	}
+
+	// NOTE the proposal is signed after Make(), so the alternate is signed
+	// before it; even the body is same, the hash of alternate is different.
+	signedAt := cp.homeState.Home().Now().Add(-time.Nanosecond)
+	if err := alternate.SignAt(cp.homeState.Home().Signer(), nil, signedAt); err != nil {
+		return err
+	}
+
+	ns := map[string]struct{}{}
+	for _, n := range nodes {
+		ns[n] = struct{}{}
+	}
+
+	cp.Lock()
+	defer cp.Unlock()
+
+	// NOTE remove the old alternates
+	for k, a := range cp.alternates {
+		if a.proposal.Height().Cmp(proposal.Height()) < 0 {
+			delete(cp.alternates, k)
+		}
+	}
+
+	cp.alternates[proposal.Body().Hash().String()] = conditionProposalAlternate{proposal: alternate, nodes: ns}
+
+	cp.Log().Debug().
+		Object("proposal", proposal.Body().Hash()).
+		Object("alternate", alternate.Body().Hash()).
+		Strs("nodes", nodes).
+		Msg("equivocating proposal made")
+
+	return nil
+}
+
+// ReplaceSeal replaces the proposal with the equivocating proposal for the
+// node.
+func (cp *ConditionProposalMaker) ReplaceSeal(to string, sl seal.Seal) seal.Seal {
+	proposal, ok := sl.(isaac.Proposal)
+	if !ok {
+		return sl
+	}
+
+	cp.RLock()
+	defer cp.RUnlock()
+
+	a, found := cp.alternates[proposal.Body().Hash().String()]
+	if !found {
+		return sl
+	}
+
+	if _, found := a.nodes[to]; !found {
+		return sl
+	}
+
+	return a.proposal
+}
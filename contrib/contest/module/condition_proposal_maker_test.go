@@ -0,0 +1,270 @@
+package contest_module
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/spikeekips/mitum/contrib/contest/condition"
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/isaac"
+	"github.com/spikeekips/mitum/node"
+)
+
+type testConditionProposalMaker struct {
+	suite.Suite
+}
+
+func (t *testConditionProposalMaker) newMaker(query, action string, nodes []string) (
+	*ConditionProposalMaker, *isaac.HomeState,
+) {
+	lastBlock := NewRandomBlock()
+	nextBlock := NewRandomNextBlock(lastBlock)
+	homeState := isaac.NewHomeState(node.NewRandomHome(), lastBlock).SetBlock(nextBlock)
+
+	cc, err := condition.NewConditionChecker(query)
+	t.NoError(err)
+
+	ch, err := NewConditionProposalHandler(cc, action, nodes)
+	t.NoError(err)
+
+	cp := NewConditionProposalMaker(
+		isaac.NewDefaultProposalMaker(homeState.Home(), 0),
+		homeState,
+		map[string]ConditionProposalHandler{"default": ch},
+	)
+
+	return cp, homeState
+}
+
+func (t *testConditionProposalMaker) TestUnknownAction() {
+	cc, _ := condition.NewConditionChecker(`proposal.round = 1`)
+
+	_, err := NewConditionProposalHandler(cc, "findme", nil)
+	t.Contains(err.Error(), "unknown proposal action")
+
+	_, err = NewConditionProposalHandler(cc, "equivocate", nil)
+	t.Contains(err.Error(), "`nodes` must be given")
+}
+
+func (t *testConditionProposalMaker) TestNotMatched() {
+	cp, homeState := t.newMaker(fmt.Sprintf(`proposal.height = %d`, 9999), "withhold", nil)
+
+	height := homeState.Block().Height().Add(1)
+	proposal, err := cp.Make(height, isaac.Round(1), homeState.Block().Hash())
+	t.NoError(err)
+
+	t.True(proposal.Height().Equal(height))
+	t.Equal(isaac.Round(1), proposal.Round())
+	t.True(proposal.LastBlock().Equal(homeState.Block().Hash()))
+}
+
+func (t *testConditionProposalMaker) TestOrderOfConditions() {
+	_, homeState := t.newMaker(`proposal.round = 1`, "withhold", nil)
+
+	conditions := map[string]ConditionProposalHandler{}
+	for _, name := range []string{"c", "a", "b"} {
+		cc, err := condition.NewConditionChecker(`proposal.round = 1`)
+		t.NoError(err)
+
+		ch, err := NewConditionProposalHandler(cc, "wrong-round", nil)
+		t.NoError(err)
+		conditions[name] = ch
+	}
+
+	cp := NewConditionProposalMaker(
+		isaac.NewDefaultProposalMaker(homeState.Home(), 0),
+		homeState,
+		conditions,
+	)
+
+	// NOTE the conditions are evaluated by the order of name
+	t.Equal([]string{"a", "b", "c"}, cp.names)
+
+	proposal, err := cp.Make(homeState.Block().Height().Add(1), isaac.Round(1), homeState.Block().Hash())
+	t.NoError(err)
+	t.Equal(isaac.Round(4), proposal.Round())
+}
+
+func (t *testConditionProposalMaker) TestWithhold() {
+	cp, homeState := t.newMaker(`proposal.round = 1`, "withhold", nil)
+
+	_, err := cp.Make(homeState.Block().Height().Add(1), isaac.Round(1), homeState.Block().Hash())
+	t.Contains(err.Error(), "withheld")
+}
+
+func (t *testConditionProposalMaker) TestWrongHeight() {
+	cp, homeState := t.newMaker(`proposal.round = 1`, "wrong-height", nil)
+
+	height := homeState.Block().Height().Add(1)
+	proposal, err := cp.Make(height, isaac.Round(1), homeState.Block().Hash())
+	t.NoError(err)
+
+	t.True(proposal.Height().Equal(height.Add(1)))
+	t.Equal(isaac.Round(1), proposal.Round())
+}
+
+func (t *testConditionProposalMaker) TestWrongRound() {
+	cp, homeState := t.newMaker(`proposal.round = 1`, "wrong-round", nil)
+
+	height := homeState.Block().Height().Add(1)
+	proposal, err := cp.Make(height, isaac.Round(1), homeState.Block().Hash())
+	t.NoError(err)
+
+	t.True(proposal.Height().Equal(height))
+	t.Equal(isaac.Round(2), proposal.Round())
+}
+
+func (t *testConditionProposalMaker) TestStaleLastBlock() {
+	cp, homeState := t.newMaker(`proposal.round = 1`, "stale-last_block", nil)
+
+	proposal, err := cp.Make(homeState.Block().Height().Add(1), isaac.Round(1), homeState.Block().Hash())
+	t.NoError(err)
+
+	t.True(proposal.LastBlock().Equal(homeState.PreviousBlock().Hash()))
+}
+
+type testOperationPool []hash.Hash
+
+func (tp testOperationPool) Pending() []hash.Hash {
+	return tp
+}
+
+// newTransactions saves the new KeyRotations into the seal storage.
+func (t *testConditionProposalMaker) newTransactions(ss isaac.SealStorage, n int) testOperationPool {
+	var hs []hash.Hash
+	for i := 0; i < n; i++ {
+		home := node.NewRandomHome()
+
+		kr, err := isaac.NewKeyRotation(home.Address(), node.NewRandomHome().PublicKey(), isaac.NewBlockHeight(10), nil)
+		t.NoError(err)
+		t.NoError(kr.Sign(home.PrivateKey(), nil))
+		t.NoError(ss.Save(kr))
+
+		hs = append(hs, kr.Hash())
+	}
+
+	return hs
+}
+
+func (t *testConditionProposalMaker) TestEquivocate() {
+	_, homeState := t.newMaker(`proposal.round = 1`, "withhold", nil)
+
+	cc, err := condition.NewConditionChecker(`proposal.round = 1`)
+	t.NoError(err)
+	ch, err := NewConditionProposalHandler(cc, "equivocate", []string{"n1"})
+	t.NoError(err)
+
+	pool := t.newTransactions(isaac.NewTSealStorage(), 2)
+	cp := NewConditionProposalMaker(
+		isaac.NewDefaultProposalMaker(homeState.Home(), 0, pool),
+		homeState,
+		map[string]ConditionProposalHandler{"default": ch},
+	)
+
+	proposal, err := cp.Make(homeState.Block().Height().Add(1), isaac.Round(1), homeState.Block().Hash())
+	t.NoError(err)
+	t.NoError(proposal.Sign(homeState.Home().PrivateKey(), nil))
+
+	// NOTE not in nodes; the original proposal
+	t.Equal(proposal, cp.ReplaceSeal("n2", proposal))
+
+	alternate, ok := cp.ReplaceSeal("n1", proposal).(isaac.Proposal)
+	t.True(ok)
+	t.NoError(alternate.IsValid())
+	t.False(alternate.Hash().Equal(proposal.Hash()))
+	t.True(alternate.Height().Equal(proposal.Height()))
+	t.Equal(proposal.Round(), alternate.Round())
+	t.True(alternate.LastBlock().Equal(proposal.LastBlock()))
+
+	// NOTE the transactions of alternate are the part of the proposal
+	t.Equal(len(proposal.Transactions())-1, len(alternate.Transactions()))
+	for i, h := range alternate.Transactions() {
+		t.True(h.Equal(proposal.Transactions()[i]))
+	}
+
+	// NOTE unknown proposal is not replaced
+	other, err := cp.pm.Make(proposal.Height(), proposal.Round()+1, proposal.LastBlock())
+	t.NoError(err)
+	t.Equal(other, cp.ReplaceSeal("n1", other))
+}
+
+func (t *testConditionProposalMaker) TestEquivocateWithoutTransactions() {
+	cp, homeState := t.newMaker(`proposal.round = 1`, "equivocate", []string{"n1"})
+
+	proposal, err := cp.Make(homeState.Block().Height().Add(1), isaac.Round(1), homeState.Block().Hash())
+	t.NoError(err)
+	t.NoError(proposal.Sign(homeState.Home().PrivateKey(), nil))
+
+	// NOTE same body, but signed at the different time
+	alternate, ok := cp.ReplaceSeal("n1", proposal).(isaac.Proposal)
+	t.True(ok)
+	t.NoError(alternate.IsValid())
+	t.True(alternate.Body().Hash().Equal(proposal.Body().Hash()))
+	t.False(alternate.Hash().Equal(proposal.Hash()))
+}
+
+// TestEquivocateChecked checks the alternate proposal is accepted by the
+// ProposalChecker of the other node.
+func (t *testConditionProposalMaker) TestEquivocateChecked() {
+	_, homeState := t.newMaker(`proposal.round = 1`, "withhold", nil)
+
+	cc, err := condition.NewConditionChecker(`proposal.round = 1`)
+	t.NoError(err)
+	ch, err := NewConditionProposalHandler(cc, "equivocate", []string{"n1"})
+	t.NoError(err)
+
+	// NOTE the transactions are known to the other node
+	other := isaac.NewHomeState(node.NewRandomHome(), homeState.PreviousBlock()).SetBlock(homeState.Block())
+	ss := isaac.NewTSealStorage()
+	pool := t.newTransactions(ss, 2)
+
+	cp := NewConditionProposalMaker(
+		isaac.NewDefaultProposalMaker(homeState.Home(), 0, pool),
+		homeState,
+		map[string]ConditionProposalHandler{"default": ch},
+	)
+
+	proposal, err := cp.Make(homeState.Block().Height().Add(1), isaac.Round(1), homeState.Block().Hash())
+	t.NoError(err)
+	t.NoError(proposal.Sign(homeState.Home().PrivateKey(), nil))
+
+	alternate, ok := cp.ReplaceSeal("n1", proposal).(isaac.Proposal)
+	t.True(ok)
+
+	thr, err := isaac.NewThreshold(2, 67)
+	t.NoError(err)
+	policyKeeper, err := isaac.NewPolicyKeeper(other, thr, ss, isaac.Policy{
+		Threshold:                         67,
+		IntervalBroadcastINITBallotInJoin: time.Second,
+		TimeoutWaitVoteResultInJoin:       time.Second,
+		TimeoutWaitBallot:                 time.Second,
+		TimeoutWaitINITBallot:             time.Second,
+	})
+	t.NoError(err)
+
+	suffrage := isaac.NewFixedProposerSuffrage(homeState.Home(), homeState.Home(), other.Home())
+
+	vr := isaac.NewVoteResult(proposal.Height(), proposal.Round(), isaac.StageINIT).
+		SetAgreement(isaac.Majority).
+		SetBlock(homeState.Block().Hash()).
+		SetLastBlock(homeState.PreviousBlock().Hash()).
+		SetProposal(homeState.Block().Proposal())
+
+	checker := isaac.NewProposalCheckerConsensus(other, suffrage, policyKeeper)
+	for _, sl := range []isaac.Proposal{proposal, alternate} {
+		t.NoError(
+			checker.New(context.TODO()).
+				SetContext("proposal", sl).
+				SetContext("lastINITVoteResult", vr).
+				Check(),
+		)
+	}
+}
+
+func TestConditionProposalMaker(t *testing.T) {
+	suite.Run(t, new(testConditionProposalMaker))
+}
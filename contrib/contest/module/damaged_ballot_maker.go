@@ -153,6 +153,8 @@ func (db DamangedBallotMaker) modifyBallot(
 		cbFunc = db.DefaultBallotMaker.SIGN
 	case isaac.StageACCEPT:
 		cbFunc = db.DefaultBallotMaker.ACCEPT
+	case isaac.StageALLCONFIRM:
+		cbFunc = db.DefaultBallotMaker.ALLCONFIRM
 	default:
 		err := xerrors.Errorf("unknown stage found")
 		db.Log().Error().
@@ -209,6 +211,20 @@ func (db DamangedBallotMaker) ACCEPT(
 	)
 }
 
+func (db DamangedBallotMaker) ALLCONFIRM(
+	lastBlock hash.Hash,
+	lastRound isaac.Round,
+	nextHeight isaac.Height,
+	nextBlock hash.Hash,
+	currentRound isaac.Round,
+	currentProposal hash.Hash,
+) (isaac.Ballot, error) {
+	return db.modifyBallot(
+		lastBlock, lastRound, nextHeight, nextBlock, currentRound, currentProposal,
+		isaac.StageALLCONFIRM,
+	)
+}
+
 func (db DamangedBallotMaker) MarshalJSON() ([]byte, error) {
 	return json.Marshal(map[string]interface{}{
 		"type":    "DamangedBallotMaker",
@@ -0,0 +1,338 @@
+package contest_module
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/isaac"
+)
+
+const (
+	InvariantSafety   = "safety"
+	InvariantLiveness = "liveness"
+	InvariantMajority = "majority"
+)
+
+// InvariantThreshold returns the number of votes, which is required to reach
+// majority in the stage of node.
+type InvariantThreshold func(node string, stage isaac.Stage) uint
+
+// InvariantBlock is the block, which is accepted by node, with the voters of
+// it's VoteResult.
+type InvariantBlock struct {
+	Node      string
+	Height    uint64
+	Round     uint64
+	Hash      string
+	Stage     isaac.Stage
+	Agreement string
+	Voters    []string // NOTE the voters, who voted for the Hash
+}
+
+type InvariantViolation struct {
+	Invariant string
+	Height    uint64
+	Nodes     []string
+	Message   string
+	At        time.Time
+}
+
+func (iv InvariantViolation) String() string {
+	return fmt.Sprintf("%s: height=%d nodes=%v: %s", iv.Invariant, iv.Height, iv.Nodes, iv.Message)
+}
+
+func (iv InvariantViolation) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("invariant", iv.Invariant)
+	e.Uint64("height", iv.Height)
+	e.Strs("nodes", iv.Nodes)
+	e.Str("message", iv.Message)
+	e.Time("at", iv.At)
+}
+
+type invariantHeight struct {
+	hash string
+	node string
+}
+
+// InvariantChecker checks the invariants against the accepted blocks of all
+// the nodes.
+// * safety: no two nodes accept different blocks at the same height
+// * liveness: the highest height of all the nodes increases within the
+// liveness timeout and the node, which is behind the highest, increases it's
+// height within the liveness timeout; 0 timeout disables it
+// * majority: every accepted block has the majority votes by the threshold
+type InvariantChecker struct {
+	sync.RWMutex
+	*common.Logger
+	safety          bool
+	majority        bool
+	livenessTimeout time.Duration
+	threshold       InvariantThreshold
+	heights         map[uint64]invariantHeight
+	lastHeights     map[string]uint64
+	highest         uint64
+	progressed      time.Time
+	stalled         bool
+	nodeProgressed  map[string]time.Time
+	lagged          map[string]bool
+	violations      []InvariantViolation
+	stop            chan struct{}
+}
+
+func NewInvariantChecker(
+	safety, majority bool,
+	livenessTimeout time.Duration,
+	threshold InvariantThreshold,
+) *InvariantChecker {
+	return &InvariantChecker{
+		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
+			return c.Str("module", "invariant-checker")
+		}),
+		safety:          safety,
+		majority:        majority,
+		livenessTimeout: livenessTimeout,
+		threshold:       threshold,
+		heights:         map[uint64]invariantHeight{},
+		lastHeights:     map[string]uint64{},
+		nodeProgressed:  map[string]time.Time{},
+		lagged:          map[string]bool{},
+	}
+}
+
+// AddNodes adds the nodes, which are checked for the liveness before their
+// first block; it should be called before Start().
+func (ic *InvariantChecker) AddNodes(nodes ...string) *InvariantChecker {
+	ic.Lock()
+	defer ic.Unlock()
+
+	for _, n := range nodes {
+		if _, found := ic.lastHeights[n]; !found {
+			ic.lastHeights[n] = 0
+		}
+	}
+
+	return ic
+}
+
+func (ic *InvariantChecker) Start() error {
+	ic.Lock()
+	defer ic.Unlock()
+
+	if ic.stop != nil {
+		return common.DaemonAleadyStartedError
+	}
+
+	ic.stop = make(chan struct{})
+	ic.progressed = common.CurrentClock().Now()
+	for n := range ic.lastHeights {
+		ic.nodeProgressed[n] = ic.progressed
+	}
+
+	if ic.livenessTimeout > 0 {
+		go ic.loopLiveness(ic.stop)
+	}
+
+	return nil
+}
+
+func (ic *InvariantChecker) Stop() error {
+	ic.Lock()
+	defer ic.Unlock()
+
+	if ic.stop == nil {
+		return nil
+	}
+
+	close(ic.stop)
+	ic.stop = nil
+
+	return nil
+}
+
+func (ic *InvariantChecker) IsStopped() bool {
+	ic.RLock()
+	defer ic.RUnlock()
+
+	return ic.stop == nil
+}
+
+// Violations returns the violations found so far in order.
+func (ic *InvariantChecker) Violations() []InvariantViolation {
+	ic.RLock()
+	defer ic.RUnlock()
+
+	vs := make([]InvariantViolation, len(ic.violations))
+	copy(vs, ic.violations)
+
+	return vs
+}
+
+// NewBlock checks the safety and majority of the accepted block and returns
+// the violations of it.
+func (ic *InvariantChecker) NewBlock(block InvariantBlock) []InvariantViolation {
+	ic.Lock()
+	var vs []InvariantViolation
+	if ic.stop != nil {
+		vs = ic.newBlock(block)
+		ic.violations = append(ic.violations, vs...)
+	}
+	ic.Unlock()
+
+	// NOTE the log may be written to InvariantChecker itself, so log outside
+	// lock
+	for _, v := range vs {
+		ic.Log().Error().Object("violation", v).Msg("invariant violated")
+	}
+
+	return vs
+}
+
+func (ic *InvariantChecker) newBlock(block InvariantBlock) []InvariantViolation {
+	var vs []InvariantViolation
+
+	if ic.safety {
+		if h, found := ic.heights[block.Height]; !found {
+			ic.heights[block.Height] = invariantHeight{hash: block.Hash, node: block.Node}
+		} else if h.hash != block.Hash {
+			vs = append(vs, InvariantViolation{
+				Invariant: InvariantSafety,
+				Height:    block.Height,
+				Nodes:     []string{h.node, block.Node},
+				Message:   fmt.Sprintf("different blocks accepted; %q != %q", h.hash, block.Hash),
+				At:        common.CurrentClock().Now(),
+			})
+		}
+	}
+
+	if ic.majority {
+		var required uint
+		if ic.threshold != nil {
+			required = ic.threshold(block.Node, block.Stage)
+		}
+
+		voters := map[string]struct{}{}
+		for _, v := range block.Voters {
+			voters[v] = struct{}{}
+		}
+
+		if block.Agreement != isaac.Majority.String() || uint(len(voters)) < required {
+			vs = append(vs, InvariantViolation{
+				Invariant: InvariantMajority,
+				Height:    block.Height,
+				Nodes:     []string{block.Node},
+				Message: fmt.Sprintf(
+					"block accepted without majority; agreement=%q votes=%d threshold=%d stage=%q",
+					block.Agreement, len(voters), required, block.Stage,
+				),
+				At: common.CurrentClock().Now(),
+			})
+		}
+	}
+
+	now := common.CurrentClock().Now()
+
+	if block.Height > ic.highest {
+		// NOTE the nodes at the previous highest start to lag from now
+		for n, h := range ic.lastHeights {
+			if h == ic.highest {
+				ic.nodeProgressed[n] = now
+			}
+		}
+
+		ic.highest = block.Height
+		ic.progressed = now
+		ic.stalled = false
+	}
+
+	if h, found := ic.lastHeights[block.Node]; !found || block.Height > h {
+		ic.lastHeights[block.Node] = block.Height
+		ic.nodeProgressed[block.Node] = now
+		ic.lagged[block.Node] = false
+	}
+
+	return vs
+}
+
+func (ic *InvariantChecker) loopLiveness(stop chan struct{}) {
+	interval := ic.livenessTimeout / 10
+	if interval < time.Millisecond*10 {
+		interval = time.Millisecond * 10
+	}
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-common.After(interval):
+			for _, v := range ic.checkLiveness() {
+				ic.Log().Error().Object("violation", v).Msg("invariant violated")
+			}
+		}
+	}
+}
+
+// checkLiveness reports the stall of the highest height and the nodes, which
+// lag behind the highest; the same stall is reported once until the height is
+// increased.
+func (ic *InvariantChecker) checkLiveness() []InvariantViolation {
+	ic.Lock()
+	defer ic.Unlock()
+
+	if ic.stop == nil {
+		return nil
+	}
+
+	now := common.CurrentClock().Now()
+
+	var nodes []string
+	for n := range ic.lastHeights {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+
+	var vs []InvariantViolation
+	if !ic.stalled && now.Sub(ic.progressed) >= ic.livenessTimeout {
+		ic.stalled = true
+
+		heights := make([]string, len(nodes))
+		for i, n := range nodes {
+			heights[i] = fmt.Sprintf("%s=%d", n, ic.lastHeights[n])
+		}
+
+		vs = append(vs, InvariantViolation{
+			Invariant: InvariantLiveness,
+			Height:    ic.highest,
+			Nodes:     nodes,
+			Message:   fmt.Sprintf("height not increased for %v; last heights=%v", ic.livenessTimeout, heights),
+			At:        now,
+		})
+	}
+
+	for _, n := range nodes {
+		height := ic.lastHeights[n]
+		if ic.lagged[n] || height >= ic.highest || now.Sub(ic.nodeProgressed[n]) < ic.livenessTimeout {
+			continue
+		}
+
+		ic.lagged[n] = true
+
+		vs = append(vs, InvariantViolation{
+			Invariant: InvariantLiveness,
+			Height:    height,
+			Nodes:     []string{n},
+			Message: fmt.Sprintf(
+				"height of node not increased for %v; height=%d highest=%d", ic.livenessTimeout, height, ic.highest,
+			),
+			At: now,
+		})
+	}
+
+	ic.violations = append(ic.violations, vs...)
+
+	return vs
+}
@@ -0,0 +1,150 @@
+package contest_module
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/isaac"
+)
+
+type testInvariantChecker struct {
+	suite.Suite
+}
+
+func (t *testInvariantChecker) newBlock(node string, height uint64, hash string, voters ...string) InvariantBlock {
+	return InvariantBlock{
+		Node:      node,
+		Height:    height,
+		Hash:      hash,
+		Stage:     isaac.StageINIT,
+		Agreement: isaac.Majority.String(),
+		Voters:    voters,
+	}
+}
+
+func (t *testInvariantChecker) threshold(string, isaac.Stage) uint {
+	return 2
+}
+
+func (t *testInvariantChecker) TestSafety() {
+	ic := NewInvariantChecker(true, false, 0, nil)
+	t.NoError(ic.Start())
+	defer ic.Stop()
+
+	t.Empty(ic.NewBlock(t.newBlock("n0", 10, "bk:a")))
+	t.Empty(ic.NewBlock(t.newBlock("n1", 10, "bk:a")))
+
+	vs := ic.NewBlock(t.newBlock("n2", 10, "bk:b"))
+	t.Equal(1, len(vs))
+	t.Equal(InvariantSafety, vs[0].Invariant)
+	t.Equal(uint64(10), vs[0].Height)
+	t.Equal([]string{"n0", "n2"}, vs[0].Nodes)
+
+	t.Equal(vs, ic.Violations())
+}
+
+func (t *testInvariantChecker) TestMajority() {
+	ic := NewInvariantChecker(false, true, 0, t.threshold)
+	t.NoError(ic.Start())
+	defer ic.Stop()
+
+	t.Empty(ic.NewBlock(t.newBlock("n0", 10, "bk:a", "n0", "n1")))
+
+	// NOTE duplicated voters are counted once
+	vs := ic.NewBlock(t.newBlock("n1", 10, "bk:a", "n0", "n0"))
+	t.Equal(1, len(vs))
+	t.Equal(InvariantMajority, vs[0].Invariant)
+	t.Equal([]string{"n1"}, vs[0].Nodes)
+
+	block := t.newBlock("n2", 10, "bk:a", "n0", "n1", "n2")
+	block.Agreement = isaac.Draw.String()
+	vs = ic.NewBlock(block)
+	t.Equal(1, len(vs))
+	t.Equal(InvariantMajority, vs[0].Invariant)
+}
+
+func (t *testInvariantChecker) TestViolationTime() {
+	start := time.Now().Add(time.Hour * -24)
+	common.SetClock(common.NewSimulatedClock(start, time.Hour))
+	defer common.SetClock(nil)
+
+	ic := NewInvariantChecker(true, false, 0, nil)
+	t.NoError(ic.Start())
+	defer ic.Stop()
+
+	t.Empty(ic.NewBlock(t.newBlock("n0", 10, "bk:a")))
+
+	// NOTE the time of violation comes from the current clock
+	vs := ic.NewBlock(t.newBlock("n1", 10, "bk:b"))
+	t.Equal(1, len(vs))
+	t.Equal(start, vs[0].At)
+}
+
+func (t *testInvariantChecker) TestLiveness() {
+	ic := NewInvariantChecker(false, false, time.Millisecond*100, nil)
+	t.NoError(ic.Start())
+	defer ic.Stop()
+
+	<-time.After(time.Millisecond * 60)
+	t.Empty(ic.NewBlock(t.newBlock("n0", 10, "bk:a")))
+
+	<-time.After(time.Millisecond * 60)
+	t.Empty(ic.Violations())
+
+	<-time.After(time.Millisecond * 200)
+	vs := ic.Violations()
+	t.Equal(1, len(vs)) // NOTE same stall is reported once
+	t.Equal(InvariantLiveness, vs[0].Invariant)
+	t.Equal(uint64(10), vs[0].Height)
+	t.Equal([]string{"n0"}, vs[0].Nodes)
+
+	t.Empty(ic.NewBlock(t.newBlock("n0", 11, "bk:b")))
+	t.Equal(1, len(ic.Violations()))
+}
+
+func (t *testInvariantChecker) TestLivenessLaggedNode() {
+	ic := NewInvariantChecker(false, false, time.Millisecond*100, nil).AddNodes("n0", "n1", "n2", "n3")
+	t.NoError(ic.Start())
+	defer ic.Stop()
+
+	// NOTE n2 stalls after height 1 and n3 does not store any block, but the
+	// highest keeps increasing by n0 and n1
+	t.Empty(ic.NewBlock(t.newBlock("n2", 1, "bk:1")))
+	for i := uint64(1); i < 11; i++ {
+		t.Empty(ic.NewBlock(t.newBlock("n0", i, fmt.Sprintf("bk:%d", i))))
+		t.Empty(ic.NewBlock(t.newBlock("n1", i, fmt.Sprintf("bk:%d", i))))
+
+		<-time.After(time.Millisecond * 30)
+	}
+
+	vs := ic.Violations()
+	t.Equal(2, len(vs)) // NOTE same lag is reported once
+
+	sort.Slice(vs, func(i, j int) bool { return vs[i].Nodes[0] < vs[j].Nodes[0] })
+	t.Equal(InvariantLiveness, vs[0].Invariant)
+	t.Equal([]string{"n2"}, vs[0].Nodes)
+	t.Equal(uint64(1), vs[0].Height)
+	t.Equal(InvariantLiveness, vs[1].Invariant)
+	t.Equal([]string{"n3"}, vs[1].Nodes)
+	t.Equal(uint64(0), vs[1].Height)
+
+	// NOTE n2 catches up
+	t.Empty(ic.NewBlock(t.newBlock("n2", 10, "bk:10")))
+	t.Equal(2, len(ic.Violations()))
+}
+
+func (t *testInvariantChecker) TestNotStarted() {
+	ic := NewInvariantChecker(true, true, 0, t.threshold)
+
+	t.Empty(ic.NewBlock(t.newBlock("n0", 10, "bk:a")))
+	t.Empty(ic.Violations())
+}
+
+func TestInvariantChecker(t *testing.T) {
+	suite.Run(t, new(testInvariantChecker))
+}
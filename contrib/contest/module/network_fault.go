@@ -0,0 +1,631 @@
+package contest_module
+
+import (
+	"context"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/node"
+	"github.com/spikeekips/mitum/seal"
+)
+
+var LatencyDistributions = []string{
+	"fixed",
+	"uniform",
+	"normal",
+}
+
+// Latency is the latency distribution of link.
+// * fixed: always Mean
+// * uniform: between Min and Max
+// * normal: normal distribution of Mean and StdDev, clipped by Min and Max
+type Latency struct {
+	Distribution string
+	Min          time.Duration
+	Max          time.Duration
+	Mean         time.Duration
+	StdDev       time.Duration
+}
+
+func (lt Latency) IsValid() error {
+	var found bool
+	for _, d := range LatencyDistributions {
+		if d == lt.Distribution {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return xerrors.Errorf("unknown latency distribution; distribution=%q", lt.Distribution)
+	}
+
+	if lt.Min < 0 || lt.Max < 0 || lt.Mean < 0 || lt.StdDev < 0 {
+		return xerrors.Errorf("negative latency found; latency=%+v", lt)
+	}
+
+	if lt.Max > 0 && lt.Min > lt.Max {
+		return xerrors.Errorf("latency min is greater than max; min=%q max=%q", lt.Min, lt.Max)
+	}
+
+	return nil
+}
+
+func (lt Latency) duration(r *rand.Rand) time.Duration {
+	var d time.Duration
+	switch lt.Distribution {
+	case "uniform":
+		if lt.Max <= lt.Min {
+			return lt.Min
+		}
+
+		return lt.Min + time.Duration(r.Int63n(int64(lt.Max-lt.Min)))
+	case "normal":
+		d = time.Duration(r.NormFloat64()*float64(lt.StdDev)) + lt.Mean
+	default:
+		d = lt.Mean
+	}
+
+	if d < lt.Min {
+		d = lt.Min
+	}
+
+	if lt.Max > 0 && d > lt.Max {
+		d = lt.Max
+	}
+
+	return d
+}
+
+// LinkFault is the faults of the link between nodes.
+// * Drop: the probability of dropping seal
+// * Duplicate: the probability of sending seal twice
+// * Reorder: the probability of holding seal for additional random duration
+// under ReorderDelay, so the later seal can be delivered first
+type LinkFault struct {
+	Latency      Latency
+	Drop         float64
+	Duplicate    float64
+	Reorder      float64
+	ReorderDelay time.Duration
+}
+
+func NewLinkFault() LinkFault {
+	return LinkFault{
+		Latency:      Latency{Distribution: "fixed"},
+		ReorderDelay: time.Millisecond * 100,
+	}
+}
+
+func (lf LinkFault) IsValid() error {
+	if err := lf.Latency.IsValid(); err != nil {
+		return err
+	}
+
+	for name, p := range map[string]float64{
+		"drop":      lf.Drop,
+		"duplicate": lf.Duplicate,
+		"reorder":   lf.Reorder,
+	} {
+		if p < 0 || p > 1 {
+			return xerrors.Errorf("%s should be between 0 and 1; %s=%v", name, name, p)
+		}
+	}
+
+	if lf.ReorderDelay < 0 {
+		return xerrors.Errorf("negative reorder delay; reorder_delay=%q", lf.ReorderDelay)
+	}
+
+	return nil
+}
+
+// Partition divides the nodes into the groups; while the partition is active,
+// the nodes in the different groups can not reach each other. The nodes,
+// which are not in any group, are not affected.
+// * the partition is active from From until Until after NetworkFaults started;
+// zero Until means forever
+// * Open() and Heal() override From and Until
+type Partition struct {
+	name   string
+	groups map[string]int
+	from   time.Duration
+	until  time.Duration
+	manual *bool
+}
+
+func NewPartition(name string, groups [][]string, from, until time.Duration) (*Partition, error) {
+	if len(groups) < 2 {
+		return nil, xerrors.Errorf("partition needs at least 2 groups; partition=%q", name)
+	}
+
+	if until > 0 && until <= from {
+		return nil, xerrors.Errorf("until should be greater than from; partition=%q", name)
+	}
+
+	gs := map[string]int{}
+	for i, g := range groups {
+		for _, n := range g {
+			if _, found := gs[n]; found {
+				return nil, xerrors.Errorf("node is in multiple groups; partition=%q node=%q", name, n)
+			}
+			gs[n] = i
+		}
+	}
+
+	return &Partition{name: name, groups: gs, from: from, until: until}, nil
+}
+
+func (pt *Partition) Name() string {
+	return pt.name
+}
+
+func (pt *Partition) Nodes() []string {
+	var nodes []string
+	for n := range pt.groups {
+		nodes = append(nodes, n)
+	}
+
+	return nodes
+}
+
+func (pt *Partition) isActive(elapsed time.Duration) bool {
+	if pt.manual != nil {
+		return *pt.manual
+	}
+
+	if elapsed < pt.from {
+		return false
+	}
+
+	return pt.until < 1 || elapsed < pt.until
+}
+
+func (pt *Partition) separates(from, to string) bool {
+	a, found := pt.groups[from]
+	if !found {
+		return false
+	}
+
+	b, found := pt.groups[to]
+	if !found {
+		return false
+	}
+
+	return a != b
+}
+
+type linkKey struct {
+	from string
+	to   string
+}
+
+// NetworkFaults keeps the faults of the links between nodes, which are
+// identified by node alias. The fault of link is selected by the order of,
+// link, from node, to node and default.
+type NetworkFaults struct {
+	sync.RWMutex
+	*common.Logger
+	def          LinkFault
+	nodes        map[string]LinkFault
+	links        map[linkKey]LinkFault
+	partitions   map[string]*Partition
+	interceptors *Interceptors
+	seed         int64
+	rands        map[linkKey]*rand.Rand
+	randLock     sync.Mutex
+	started      time.Time
+	stop         chan struct{}
+	delayed      sync.WaitGroup
+}
+
+func NewNetworkFaults(def LinkFault) *NetworkFaults {
+	return &NetworkFaults{
+		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
+			return c.Str("module", "network-faults")
+		}),
+		def:          def,
+		nodes:        map[string]LinkFault{},
+		links:        map[linkKey]LinkFault{},
+		partitions:   map[string]*Partition{},
+		interceptors: NewInterceptors(),
+		seed:         time.Now().UnixNano(),
+		rands:        map[linkKey]*rand.Rand{},
+		started:      common.CurrentClock().Now(),
+		stop:         make(chan struct{}),
+	}
+}
+
+// SetSeed makes the faults deterministic; every link has it's own random
+// source from the seed, so the faults of link do not depend on the other
+// links.
+func (nf *NetworkFaults) SetSeed(seed int64) *NetworkFaults {
+	nf.randLock.Lock()
+	defer nf.randLock.Unlock()
+
+	nf.seed = seed
+	nf.rands = map[linkKey]*rand.Rand{}
+
+	return nf
+}
+
+func (nf *NetworkFaults) rand(from, to string) *rand.Rand {
+	key := linkKey{from: from, to: to}
+	if r, found := nf.rands[key]; found {
+		return r
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(from + "->" + to))
+
+	r := rand.New(rand.NewSource(nf.seed ^ int64(h.Sum64()))) // nolint
+	nf.rands[key] = r
+
+	return r
+}
+
+// Start resets the start time of partitions.
+func (nf *NetworkFaults) Start() {
+	nf.Lock()
+	defer nf.Unlock()
+
+	nf.started = common.CurrentClock().Now()
+	if nf.stop == nil {
+		nf.stop = make(chan struct{})
+	}
+}
+
+// Stop drops the delayed seals and waits until the delaying goroutines are
+// finished; after Stop, the seals are not delayed but dropped until Start.
+func (nf *NetworkFaults) Stop() {
+	nf.Lock()
+	if nf.stop != nil {
+		close(nf.stop)
+		nf.stop = nil
+	}
+	nf.Unlock()
+
+	nf.delayed.Wait()
+}
+
+// delay runs f after d by the current clock.
+func (nf *NetworkFaults) delay(d time.Duration, f func()) {
+	nf.RLock()
+	stop := nf.stop
+	if stop == nil {
+		nf.RUnlock()
+		return
+	}
+	nf.delayed.Add(1)
+	nf.RUnlock()
+
+	go func() {
+		defer nf.delayed.Done()
+
+		select {
+		case <-stop:
+		case <-common.After(d):
+			f()
+		}
+	}()
+}
+
+// SetNode sets the fault of the links from and to the node.
+func (nf *NetworkFaults) SetNode(alias string, lf LinkFault) *NetworkFaults {
+	nf.Lock()
+	defer nf.Unlock()
+
+	nf.nodes[alias] = lf
+
+	return nf
+}
+
+// SetLink sets the fault of the link from node to the other node.
+func (nf *NetworkFaults) SetLink(from, to string, lf LinkFault) *NetworkFaults {
+	nf.Lock()
+	defer nf.Unlock()
+
+	nf.links[linkKey{from: from, to: to}] = lf
+
+	return nf
+}
+
+func (nf *NetworkFaults) AddPartition(pt *Partition) error {
+	nf.Lock()
+	defer nf.Unlock()
+
+	if _, found := nf.partitions[pt.Name()]; found {
+		return xerrors.Errorf("partition already added; partition=%q", pt.Name())
+	}
+
+	nf.partitions[pt.Name()] = pt
+
+	return nil
+}
+
+func (nf *NetworkFaults) Interceptors() *Interceptors {
+	return nf.interceptors
+}
+
+// Open activates the partition.
+func (nf *NetworkFaults) Open(name string) error {
+	return nf.setPartition(name, true)
+}
+
+// Heal deactivates the partition.
+func (nf *NetworkFaults) Heal(name string) error {
+	return nf.setPartition(name, false)
+}
+
+func (nf *NetworkFaults) setPartition(name string, active bool) error {
+	nf.Lock()
+	defer nf.Unlock()
+
+	pt, found := nf.partitions[name]
+	if !found {
+		return xerrors.Errorf("unknown partition; partition=%q", name)
+	}
+
+	pt.manual = &active
+
+	nf.Log().Debug().Str("partition", name).Bool("active", active).Msg("partition changed")
+
+	return nil
+}
+
+// Link returns the LinkFault of the link from node to the other node.
+func (nf *NetworkFaults) Link(from, to string) LinkFault {
+	nf.RLock()
+	defer nf.RUnlock()
+
+	if lf, found := nf.links[linkKey{from: from, to: to}]; found {
+		return lf
+	}
+
+	if lf, found := nf.nodes[from]; found {
+		return lf
+	}
+
+	if lf, found := nf.nodes[to]; found {
+		return lf
+	}
+
+	return nf.def
+}
+
+// Partitioned checks whether the link is cut by any active partition; if
+// cut, the name of partition is returned.
+func (nf *NetworkFaults) Partitioned(from, to string) (string, bool) {
+	nf.RLock()
+	defer nf.RUnlock()
+
+	elapsed := common.CurrentClock().Now().Sub(nf.started)
+	for name, pt := range nf.partitions {
+		if pt.isActive(elapsed) && pt.separates(from, to) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// Delays returns the delays of the copies of seal, which will be delivered
+// through the link; empty delays means the seal is dropped.
+func (nf *NetworkFaults) Delays(from, to string) []time.Duration {
+	lf := nf.Link(from, to)
+
+	nf.randLock.Lock()
+	defer nf.randLock.Unlock()
+
+	r := nf.rand(from, to)
+
+	if lf.Drop > 0 && r.Float64() < lf.Drop {
+		return nil
+	}
+
+	n := 1
+	if lf.Duplicate > 0 && r.Float64() < lf.Duplicate {
+		n = 2
+	}
+
+	delays := make([]time.Duration, n)
+	for i := range delays {
+		d := lf.Latency.duration(r)
+		if lf.Reorder > 0 && lf.ReorderDelay > 0 && r.Float64() < lf.Reorder {
+			d += time.Duration(r.Int63n(int64(lf.ReorderDelay)))
+		}
+
+		delays[i] = d
+	}
+
+	return delays
+}
+
+// SealReplacer replaces the seal broadcasted to the node, so the different
+// nodes can receive the different seals.
+type SealReplacer interface {
+	ReplaceSeal(to string, sl seal.Seal) seal.Seal
+}
+
+// FaultNetwork wraps ChannelNetwork and applies the NetworkFaults to the
+// seals sent to the other nodes. The seal sent to home is not affected. The
+// interceptors are also applied to the requests and to the responses of the
+// other nodes.
+type FaultNetwork struct {
+	*ChannelNetwork
+	faults       *NetworkFaults
+	replacerLock sync.RWMutex
+	replacer     SealReplacer
+}
+
+func NewFaultNetwork(cn *ChannelNetwork, faults *NetworkFaults) *FaultNetwork {
+	return &FaultNetwork{ChannelNetwork: cn, faults: faults}
+}
+
+func (fn *FaultNetwork) Faults() *NetworkFaults {
+	return fn.faults
+}
+
+// SetSealReplacer sets the SealReplacer; nil removes it.
+func (fn *FaultNetwork) SetSealReplacer(replacer SealReplacer) *FaultNetwork {
+	fn.replacerLock.Lock()
+	defer fn.replacerLock.Unlock()
+
+	fn.replacer = replacer
+
+	return fn
+}
+
+func (fn *FaultNetwork) replaceSeal(to string, sl seal.Seal) seal.Seal {
+	fn.replacerLock.RLock()
+	replacer := fn.replacer
+	fn.replacerLock.RUnlock()
+
+	if replacer == nil {
+		return sl
+	}
+
+	return replacer.ReplaceSeal(to, sl)
+}
+
+func (fn *FaultNetwork) Broadcast(bsl seal.Seal) error {
+	from := fn.Home().Alias()
+
+	for _, ch := range fn.Chans() {
+		if ch.Home().Equal(fn.Home()) {
+			_ = ch.Write(bsl)
+			continue
+		}
+
+		to := ch.Home().Alias()
+		sl := fn.replaceSeal(to, bsl)
+		l := fn.Log().With().Str("from", from).Str("to", to).Object("seal", sl.Hash()).Logger()
+
+		if name, cut := fn.faults.Partitioned(from, to); cut {
+			l.Debug().Str("partition", name).Msg("seal dropped by partition")
+			continue
+		}
+
+		intercepted := fn.faults.Interceptors().Intercept(fn.Home(), to, sl)
+		if len(intercepted) < 1 {
+			l.Debug().Msg("seal dropped by interceptor")
+			continue
+		}
+
+		for _, ic := range intercepted {
+			delays := fn.faults.Delays(from, to)
+			if len(delays) < 1 {
+				l.Debug().Msg("seal dropped")
+				continue
+			} else if len(delays) > 1 {
+				l.Debug().Msg("seal duplicated")
+			}
+
+			for _, d := range delays {
+				fn.send(ch, ic.Seal, d+ic.Delay)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (fn *FaultNetwork) send(ch *ChannelNetwork, sl seal.Seal, d time.Duration) {
+	if d < 1 {
+		_ = ch.Write(sl)
+		return
+	}
+
+	fn.faults.delay(d, func() {
+		_ = ch.Write(sl)
+	})
+}
+
+func (fn *FaultNetwork) Request(ctx context.Context, n node.Address, sl seal.Seal) (seal.Seal, error) {
+	i, found := fn.chans.Load(n)
+	if !found {
+		return nil, xerrors.Errorf("unknown node; node=%q", n)
+	}
+
+	ch := i.(*ChannelNetwork)
+	if err := fn.reachable(ch); err != nil {
+		return nil, err
+	}
+
+	return fn.request(ctx, ch, sl)
+}
+
+func (fn *FaultNetwork) RequestAll(ctx context.Context, sl seal.Seal) (map[node.Address]seal.Seal, error) {
+	results := map[node.Address]seal.Seal{}
+
+	for _, ch := range fn.Chans() {
+		if err := fn.reachable(ch); err != nil {
+			fn.Log().Error().Err(err).Object("target", ch.home.Address()).Msg("failed to request")
+			results[ch.home.Address()] = nil
+			continue
+		}
+
+		r, err := fn.request(ctx, ch, sl)
+		if err != nil {
+			fn.Log().Error().Err(err).Object("target", ch.home.Address()).Msg("failed to request")
+		}
+		results[ch.home.Address()] = r
+	}
+
+	return results, nil
+}
+
+// request intercepts the request seal to the node and the response seal from
+// the node; only the first copy of the duplicated seal is used, because a
+// request has only one response.
+func (fn *FaultNetwork) request(ctx context.Context, ch *ChannelNetwork, sl seal.Seal) (seal.Seal, error) {
+	if ch.Home().Equal(fn.Home()) {
+		return fn.ChannelNetwork.request(ctx, ch, sl)
+	}
+
+	isl, err := fn.intercept(ctx, fn.Home(), ch.Home().Alias(), sl)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := fn.ChannelNetwork.request(ctx, ch, isl)
+	if err != nil || r == nil {
+		return r, err
+	}
+
+	return fn.intercept(ctx, ch.Home(), fn.Home().Alias(), r)
+}
+
+func (fn *FaultNetwork) intercept(ctx context.Context, from node.Home, to string, sl seal.Seal) (seal.Seal, error) {
+	intercepted := fn.faults.Interceptors().Intercept(from, to, sl)
+	if len(intercepted) < 1 {
+		return nil, xerrors.Errorf("seal dropped by interceptor; from=%q to=%q", from.Alias(), to)
+	}
+
+	ic := intercepted[0]
+	if ic.Delay > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-common.After(ic.Delay):
+		}
+	}
+
+	return ic.Seal, nil
+}
+
+func (fn *FaultNetwork) reachable(ch *ChannelNetwork) error {
+	if ch.Home().Equal(fn.Home()) {
+		return nil
+	}
+
+	if name, cut := fn.faults.Partitioned(fn.Home().Alias(), ch.Home().Alias()); cut {
+		return xerrors.Errorf("node is not reachable by partition; node=%q partition=%q", ch.Home().Alias(), name)
+	}
+
+	return nil
+}
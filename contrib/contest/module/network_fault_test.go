@@ -0,0 +1,361 @@
+package contest_module
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/contrib/contest/condition"
+	"github.com/spikeekips/mitum/isaac"
+	"github.com/spikeekips/mitum/node"
+	"github.com/spikeekips/mitum/seal"
+)
+
+type testNetworkFaults struct {
+	suite.Suite
+}
+
+func (t *testNetworkFaults) newNetworks(faults *NetworkFaults, aliases ...string) []*FaultNetwork {
+	var fns []*FaultNetwork
+	for _, a := range aliases {
+		home := node.NewRandomHome().SetAlias(a).(node.Home)
+		cn := NewChannelNetwork(home, nil)
+		fns = append(fns, NewFaultNetwork(cn, faults))
+	}
+
+	for _, a := range fns {
+		for _, b := range fns {
+			_ = a.AddMembers(b.ChannelNetwork)
+		}
+	}
+
+	return fns
+}
+
+func (t *testNetworkFaults) newSeal() seal.Seal {
+	home := node.NewRandomHome()
+	block := isaac.NewRandomBlock()
+
+	ballot, err := isaac.NewINITBallot(
+		home.Address(),
+		block.Hash(),
+		block.Round(),
+		block.Height().Add(1),
+		isaac.NewRandomBlockHash(),
+		isaac.Round(0),
+		block.Proposal(),
+	)
+	t.NoError(err)
+	t.NoError(ballot.Sign(home.PrivateKey(), nil))
+
+	return ballot
+}
+
+// received counts the seals received by the network within the timeout.
+func (t *testNetworkFaults) received(fn *FaultNetwork, timeout time.Duration) int {
+	var count int
+	for {
+		select {
+		case <-time.After(timeout):
+			return count
+		case <-fn.Reader():
+			count++
+		}
+	}
+}
+
+func (t *testNetworkFaults) TestLatency() {
+	r := rand.New(rand.NewSource(1)) // nolint
+
+	uniform := Latency{Distribution: "uniform", Min: time.Millisecond * 10, Max: time.Millisecond * 20}
+	normal := Latency{
+		Distribution: "normal",
+		Min:          time.Millisecond * 5,
+		Max:          time.Millisecond * 15,
+		Mean:         time.Millisecond * 10,
+		StdDev:       time.Millisecond * 10,
+	}
+	fixed := Latency{Distribution: "fixed", Mean: time.Millisecond * 7}
+
+	for i := 0; i < 100; i++ {
+		d := uniform.duration(r)
+		t.True(d >= uniform.Min && d < uniform.Max)
+
+		d = normal.duration(r)
+		t.True(d >= normal.Min && d <= normal.Max)
+
+		t.Equal(fixed.Mean, fixed.duration(r))
+	}
+
+	t.Error(Latency{Distribution: "killme"}.IsValid())
+	t.Error(Latency{Distribution: "uniform", Min: time.Second, Max: time.Millisecond}.IsValid())
+}
+
+func (t *testNetworkFaults) TestLinkFaultIsValid() {
+	lf := NewLinkFault()
+	t.NoError(lf.IsValid())
+
+	lf.Drop = 1.1
+	t.Error(lf.IsValid())
+}
+
+func (t *testNetworkFaults) TestLinkPriority() {
+	def := NewLinkFault()
+
+	n0 := NewLinkFault()
+	n0.Drop = 0.1
+
+	link := NewLinkFault()
+	link.Drop = 0.2
+
+	nf := NewNetworkFaults(def).
+		SetNode("n0", n0).
+		SetLink("n1", "n0", link)
+
+	t.Equal(n0, nf.Link("n0", "n1"))
+	t.Equal(n0, nf.Link("n2", "n0"))
+	t.Equal(link, nf.Link("n1", "n0"))
+	t.Equal(def, nf.Link("n1", "n2"))
+}
+
+func (t *testNetworkFaults) TestPartition() {
+	_, err := NewPartition("one", [][]string{{"n0"}}, 0, 0)
+	t.Error(err)
+
+	_, err = NewPartition("dup", [][]string{{"n0"}, {"n0", "n1"}}, 0, 0)
+	t.Error(err)
+
+	pt, err := NewPartition("split", [][]string{{"n0", "n1"}, {"n2"}}, 0, 0)
+	t.NoError(err)
+
+	nf := NewNetworkFaults(NewLinkFault())
+	t.NoError(nf.AddPartition(pt))
+	t.Error(nf.AddPartition(pt))
+
+	name, cut := nf.Partitioned("n0", "n2")
+	t.True(cut)
+	t.Equal("split", name)
+
+	_, cut = nf.Partitioned("n0", "n1")
+	t.False(cut)
+
+	// NOTE n3 is not in any group
+	_, cut = nf.Partitioned("n3", "n2")
+	t.False(cut)
+
+	t.NoError(nf.Heal("split"))
+	_, cut = nf.Partitioned("n0", "n2")
+	t.False(cut)
+
+	t.NoError(nf.Open("split"))
+	_, cut = nf.Partitioned("n0", "n2")
+	t.True(cut)
+
+	t.Error(nf.Open("unknown"))
+}
+
+func (t *testNetworkFaults) TestPartitionWindow() {
+	pt, err := NewPartition("split", [][]string{{"n0"}, {"n1"}}, time.Millisecond*50, time.Millisecond*100)
+	t.NoError(err)
+
+	nf := NewNetworkFaults(NewLinkFault())
+	t.NoError(nf.AddPartition(pt))
+	nf.Start()
+
+	_, cut := nf.Partitioned("n0", "n1")
+	t.False(cut)
+
+	<-time.After(time.Millisecond * 60)
+	_, cut = nf.Partitioned("n0", "n1")
+	t.True(cut)
+
+	<-time.After(time.Millisecond * 50)
+	_, cut = nf.Partitioned("n0", "n1")
+	t.False(cut)
+}
+
+func (t *testNetworkFaults) TestSeed() {
+	lf := NewLinkFault()
+	lf.Drop = 0.5
+	lf.Latency = Latency{Distribution: "uniform", Min: time.Millisecond, Max: time.Second}
+
+	delays := func(nf *NetworkFaults, from, to string) [][]time.Duration {
+		var ds [][]time.Duration
+		for i := 0; i < 100; i++ {
+			ds = append(ds, nf.Delays(from, to))
+		}
+
+		return ds
+	}
+
+	a := NewNetworkFaults(lf).SetSeed(1)
+	b := NewNetworkFaults(lf).SetSeed(1)
+
+	// NOTE the other link does not affect the faults of link
+	_ = delays(b, "n1", "n0")
+
+	t.Equal(delays(a, "n0", "n1"), delays(b, "n0", "n1"))
+	t.NotEqual(delays(a, "n0", "n2"), delays(NewNetworkFaults(lf).SetSeed(2), "n0", "n2"))
+}
+
+func (t *testNetworkFaults) TestBroadcastDrop() {
+	lf := NewLinkFault()
+	lf.Drop = 1
+
+	fns := t.newNetworks(NewNetworkFaults(lf), "n0", "n1")
+	t.NoError(fns[0].Broadcast(t.newSeal()))
+
+	// NOTE seal to home is not dropped
+	t.Equal(1, t.received(fns[0], time.Millisecond*50))
+	t.Equal(0, t.received(fns[1], time.Millisecond*50))
+}
+
+func (t *testNetworkFaults) TestBroadcastDuplicate() {
+	lf := NewLinkFault()
+	lf.Duplicate = 1
+
+	fns := t.newNetworks(NewNetworkFaults(lf), "n0", "n1")
+	t.NoError(fns[0].Broadcast(t.newSeal()))
+
+	t.Equal(1, t.received(fns[0], time.Millisecond*50))
+	t.Equal(2, t.received(fns[1], time.Millisecond*50))
+}
+
+func (t *testNetworkFaults) TestBroadcastLatency() {
+	lf := NewLinkFault()
+	lf.Latency = Latency{Distribution: "fixed", Mean: time.Millisecond * 100}
+
+	fns := t.newNetworks(NewNetworkFaults(lf), "n0", "n1")
+	t.NoError(fns[0].Broadcast(t.newSeal()))
+
+	t.Equal(0, t.received(fns[1], time.Millisecond*50))
+	t.Equal(1, t.received(fns[1], time.Millisecond*100))
+}
+
+func (t *testNetworkFaults) TestStopDropsDelayed() {
+	lf := NewLinkFault()
+	lf.Latency = Latency{Distribution: "fixed", Mean: time.Millisecond * 100}
+
+	nf := NewNetworkFaults(lf)
+	fns := t.newNetworks(nf, "n0", "n1")
+	t.NoError(fns[0].Broadcast(t.newSeal()))
+
+	// NOTE Stop does not wait the latency of delayed seals
+	started := time.Now()
+	nf.Stop()
+	t.True(time.Since(started) < time.Millisecond*100)
+
+	t.Equal(0, t.received(fns[1], time.Millisecond*150))
+
+	// NOTE after stop, seals are dropped until Start
+	t.NoError(fns[0].Broadcast(t.newSeal()))
+	t.Equal(0, t.received(fns[1], time.Millisecond*150))
+
+	nf.Start()
+	t.NoError(fns[0].Broadcast(t.newSeal()))
+	t.Equal(1, t.received(fns[1], time.Millisecond*150))
+}
+
+func (t *testNetworkFaults) TestBroadcastPartition() {
+	nf := NewNetworkFaults(NewLinkFault())
+	pt, _ := NewPartition("split", [][]string{{"n0", "n1"}, {"n2"}}, 0, 0)
+	t.NoError(nf.AddPartition(pt))
+
+	fns := t.newNetworks(nf, "n0", "n1", "n2")
+	t.NoError(fns[0].Broadcast(t.newSeal()))
+
+	t.Equal(1, t.received(fns[1], time.Millisecond*50))
+	t.Equal(0, t.received(fns[2], time.Millisecond*50))
+
+	_, err := fns[0].Request(context.TODO(), fns[2].Home().Address(), t.newSeal())
+	t.Contains(err.Error(), "partition")
+}
+
+func (t *testNetworkFaults) TestBroadcastInterceptor() {
+	cc, _ := condition.NewConditionChecker(`from = 'n0' AND to = 'n1'`)
+	ic, err := NewInterceptor("a", cc, "drop", 0, nil)
+	t.NoError(err)
+
+	nf := NewNetworkFaults(NewLinkFault())
+	t.NoError(nf.Interceptors().Add(ic))
+
+	fns := t.newNetworks(nf, "n0", "n1", "n2")
+	t.NoError(fns[0].Broadcast(t.newSeal()))
+
+	// NOTE seal to home is not intercepted
+	t.Equal(1, t.received(fns[0], time.Millisecond*50))
+	t.Equal(0, t.received(fns[1], time.Millisecond*50))
+	t.Equal(1, t.received(fns[2], time.Millisecond*50))
+}
+
+func (t *testNetworkFaults) newRequestNetworks(nf *NetworkFaults, aliases ...string) []*FaultNetwork {
+	var fns []*FaultNetwork
+	for _, a := range aliases {
+		home := node.NewRandomHome().SetAlias(a).(node.Home)
+		cn := NewChannelNetwork(home, func(sl seal.Seal) (seal.Seal, error) {
+			return sl, nil
+		})
+		fns = append(fns, NewFaultNetwork(cn, nf))
+	}
+
+	for _, a := range fns {
+		for _, b := range fns {
+			_ = a.AddMembers(b.ChannelNetwork)
+		}
+	}
+
+	return fns
+}
+
+func (t *testNetworkFaults) TestRequestInterceptor() {
+	cc, _ := condition.NewConditionChecker(`from = 'n0' AND to = 'n1'`)
+	ic, err := NewInterceptor("a", cc, "drop", 0, nil)
+	t.NoError(err)
+
+	nf := NewNetworkFaults(NewLinkFault())
+	t.NoError(nf.Interceptors().Add(ic))
+
+	fns := t.newRequestNetworks(nf, "n0", "n1", "n2")
+
+	_, err = fns[0].Request(context.TODO(), fns[1].Home().Address(), t.newSeal())
+	t.Contains(err.Error(), "dropped by interceptor")
+
+	sl := t.newSeal()
+	r, err := fns[0].Request(context.TODO(), fns[2].Home().Address(), sl)
+	t.NoError(err)
+	t.True(sl.Hash().Equal(r.Hash()))
+
+	// NOTE the response from n0 to n1 is intercepted
+	_, err = fns[1].Request(context.TODO(), fns[0].Home().Address(), t.newSeal())
+	t.Contains(err.Error(), "dropped by interceptor")
+
+	results, err := fns[0].RequestAll(context.TODO(), t.newSeal())
+	t.NoError(err)
+	t.Nil(results[fns[1].Home().Address()])
+	t.NotNil(results[fns[2].Home().Address()])
+}
+
+func (t *testNetworkFaults) TestRequestInterceptorDelay() {
+	cc, _ := condition.NewConditionChecker(`from = 'n1' AND to = 'n0'`)
+	ic, err := NewInterceptor("a", cc, "delay", time.Second, nil)
+	t.NoError(err)
+
+	nf := NewNetworkFaults(NewLinkFault())
+	t.NoError(nf.Interceptors().Add(ic))
+
+	fns := t.newRequestNetworks(nf, "n0", "n1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	_, err = fns[0].Request(ctx, fns[1].Home().Address(), t.newSeal())
+	t.True(xerrors.Is(err, context.DeadlineExceeded))
+}
+
+func TestNetworkFaults(t *testing.T) {
+	suite.Run(t, new(testNetworkFaults))
+}
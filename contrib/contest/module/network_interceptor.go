@@ -0,0 +1,322 @@
+package contest_module
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/contrib/contest/condition"
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/isaac"
+	"github.com/spikeekips/mitum/node"
+	"github.com/spikeekips/mitum/seal"
+)
+
+var InterceptActions = []string{
+	"drop",
+	"delay",
+	"duplicate",
+	"mutate",
+}
+
+var InterceptMutateFields = []string{
+	"height",
+	"round",
+	"proposal",
+	"block",
+	"last_block",
+	"last_round",
+}
+
+// Interceptor changes the seal in the link between nodes, when the seal
+// matches the condition.
+// * drop: drops the seal
+// * delay: holds the seal for Delay
+// * duplicate: sends the seal twice
+// * mutate: changes the Fields of ballot or proposal with the random values
+// and signs it again by the sender, so the seal is still valid; the fields,
+// which the seal does not have, are ignored
+type Interceptor struct {
+	name    string
+	checker condition.ConditionChecker
+	action  string
+	delay   time.Duration
+	fields  []string
+}
+
+func NewInterceptor(
+	name string,
+	checker condition.ConditionChecker,
+	action string,
+	delay time.Duration,
+	fields []string,
+) (*Interceptor, error) {
+	if !inStrings(action, InterceptActions) {
+		return nil, xerrors.Errorf("unknown intercept action; interceptor=%q action=%q", name, action)
+	}
+
+	switch action {
+	case "delay":
+		if delay < 1 {
+			return nil, xerrors.Errorf("`delay` must be given for `delay`; interceptor=%q", name)
+		}
+	case "mutate":
+		if len(fields) < 1 {
+			return nil, xerrors.Errorf("`fields` must be given for `mutate`; interceptor=%q", name)
+		}
+
+		for _, f := range fields {
+			if !inStrings(f, InterceptMutateFields) {
+				return nil, xerrors.Errorf("unknown mutate field; interceptor=%q field=%q", name, f)
+			}
+		}
+	}
+
+	return &Interceptor{name: name, checker: checker, action: action, delay: delay, fields: fields}, nil
+}
+
+func (ic *Interceptor) Name() string {
+	return ic.name
+}
+
+func (ic *Interceptor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"query":  ic.checker.Query(),
+		"action": ic.action,
+		"delay":  ic.delay,
+		"fields": ic.fields,
+	})
+}
+
+// Intercepted is the seal, which passed the interceptors; Delay is added to
+// the latency of link.
+type Intercepted struct {
+	Seal  seal.Seal
+	Delay time.Duration
+}
+
+// Interceptors evaluates the Interceptor by the order of name; every matched
+// Interceptor is applied in turn, and drop stops the others.
+type Interceptors struct {
+	sync.RWMutex
+	*common.Logger
+	interceptors []*Interceptor
+}
+
+func NewInterceptors() *Interceptors {
+	return &Interceptors{
+		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
+			return c.Str("module", "network-interceptors")
+		}),
+	}
+}
+
+func (is *Interceptors) Add(ic *Interceptor) error {
+	is.Lock()
+	defer is.Unlock()
+
+	for _, i := range is.interceptors {
+		if i.Name() == ic.Name() {
+			return xerrors.Errorf("interceptor already added; interceptor=%q", ic.Name())
+		}
+	}
+
+	is.interceptors = append(is.interceptors, ic)
+	sort.SliceStable(is.interceptors, func(i, j int) bool {
+		return is.interceptors[i].Name() < is.interceptors[j].Name()
+	})
+
+	return nil
+}
+
+func (is *Interceptors) Len() int {
+	is.RLock()
+	defer is.RUnlock()
+
+	return len(is.interceptors)
+}
+
+// Intercept applies the interceptors to the seal from the node to the other
+// node; empty result means the seal is dropped.
+func (is *Interceptors) Intercept(from node.Home, to string, sl seal.Seal) []Intercepted {
+	is.RLock()
+	interceptors := is.interceptors
+	is.RUnlock()
+
+	result := []Intercepted{{Seal: sl}}
+	if len(interceptors) < 1 {
+		return result
+	}
+
+	li, err := condition.NewLogItemFromMap(interceptLogItem(from.Alias(), to, sl))
+	if err != nil {
+		is.Log().Error().Err(err).Msg("failed to make log item of seal")
+		return result
+	}
+
+	for _, ic := range interceptors {
+		if !ic.checker.Check(li) {
+			continue
+		}
+
+		is.Log().Debug().
+			Str("interceptor", ic.Name()).
+			Str("query", ic.checker.Query()).
+			Str("action", ic.action).
+			RawJSON("data", li.Bytes()).
+			Msg("seal intercepted")
+
+		switch ic.action {
+		case "drop":
+			return nil
+		case "delay":
+			for i := range result {
+				result[i].Delay += ic.delay
+			}
+		case "duplicate":
+			result = append(result, result...)
+		case "mutate":
+			for i := range result {
+				m, err := mutateSeal(from, result[i].Seal, ic.fields)
+				if err != nil {
+					is.Log().Error().Err(err).Str("interceptor", ic.Name()).Msg("failed to mutate seal")
+					continue
+				}
+
+				result[i].Seal = m
+			}
+		}
+	}
+
+	return result
+}
+
+func interceptLogItem(from, to string, sl seal.Seal) map[string]interface{} {
+	m := map[string]interface{}{
+		"from": from,
+		"to":   to,
+		"seal": map[string]interface{}{
+			"type": sl.Type().Name(),
+			"hash": sl.Hash().String(),
+		},
+	}
+
+	switch t := sl.(type) {
+	case isaac.Ballot:
+		m["ballot"] = map[string]interface{}{
+			"stage":      t.Stage().String(),
+			"height":     t.Height().Uint64(),
+			"round":      t.Round().Uint64(),
+			"proposal":   t.Proposal().String(),
+			"block":      t.Block().String(),
+			"last_block": t.LastBlock().String(),
+			"last_round": t.LastRound().Uint64(),
+		}
+	case isaac.Proposal:
+		m["proposal"] = map[string]interface{}{
+			"height":     t.Height().Uint64(),
+			"round":      t.Round().Uint64(),
+			"last_block": t.LastBlock().String(),
+		}
+	}
+
+	return m
+}
+
+func mutateSeal(from node.Home, sl seal.Seal, fields []string) (seal.Seal, error) {
+	switch t := sl.(type) {
+	case isaac.Ballot:
+		return mutateBallot(from, t, fields)
+	case isaac.Proposal:
+		return mutateProposal(from, t, fields)
+	default:
+		return sl, nil
+	}
+}
+
+func mutateBallot(from node.Home, ballot isaac.Ballot, fields []string) (isaac.Ballot, error) {
+	lastBlock := ballot.LastBlock()
+	lastRound := ballot.LastRound()
+	height := ballot.Height()
+	block := ballot.Block()
+	round := ballot.Round()
+	proposal := ballot.Proposal()
+
+	for _, f := range fields {
+		switch f {
+		case "height":
+			height = NewRandomHeight()
+		case "round":
+			round = NewRandomRound()
+		case "proposal":
+			proposal = NewRandomProposalHash()
+		case "block":
+			block = NewRandomBlockHash()
+		case "last_block":
+			lastBlock = NewRandomBlockHash()
+		case "last_round":
+			lastRound = NewRandomRound()
+		}
+	}
+
+	var f func(hash.Hash, isaac.Round, isaac.Height, hash.Hash, isaac.Round, hash.Hash) (isaac.Ballot, error)
+
+	bm := isaac.NewDefaultBallotMaker(from)
+	switch ballot.Stage() {
+	case isaac.StageINIT:
+		f = bm.INIT
+	case isaac.StageSIGN:
+		f = bm.SIGN
+	case isaac.StageACCEPT:
+		f = bm.ACCEPT
+	case isaac.StageALLCONFIRM:
+		f = bm.ALLCONFIRM
+	default:
+		return isaac.Ballot{}, xerrors.Errorf("unknown stage found; stage=%q", ballot.Stage())
+	}
+
+	return f(lastBlock, lastRound, height, block, round, proposal)
+}
+
+func mutateProposal(from node.Home, proposal isaac.Proposal, fields []string) (isaac.Proposal, error) {
+	height := proposal.Height()
+	round := proposal.Round()
+	lastBlock := proposal.LastBlock()
+
+	for _, f := range fields {
+		switch f {
+		case "height":
+			height = NewRandomHeight()
+		case "round":
+			round = NewRandomRound()
+		case "last_block":
+			lastBlock = NewRandomBlockHash()
+		}
+	}
+
+	mutated, err := isaac.NewProposal(height, round, lastBlock, proposal.Proposer(), proposal.Transactions())
+	if err != nil {
+		return isaac.Proposal{}, err
+	}
+
+	if err := mutated.SignAt(from.Signer(), nil, from.Now()); err != nil {
+		return isaac.Proposal{}, err
+	}
+
+	return mutated, nil
+}
+
+func inStrings(s string, l []string) bool {
+	for _, i := range l {
+		if i == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,152 @@
+package contest_module
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/spikeekips/mitum/contrib/contest/condition"
+	"github.com/spikeekips/mitum/isaac"
+	"github.com/spikeekips/mitum/node"
+)
+
+type testInterceptors struct {
+	suite.Suite
+}
+
+func (t *testInterceptors) newInterceptor(name, query, action string, delay time.Duration, fields ...string) *Interceptor {
+	cc, err := condition.NewConditionChecker(query)
+	t.NoError(err)
+
+	ic, err := NewInterceptor(name, cc, action, delay, fields)
+	t.NoError(err)
+
+	return ic
+}
+
+func (t *testInterceptors) newBallot(home node.Home, stage isaac.Stage) isaac.Ballot {
+	block := isaac.NewRandomBlock()
+
+	bm := isaac.NewDefaultBallotMaker(home)
+
+	var ballot isaac.Ballot
+	var err error
+	switch stage {
+	case isaac.StageACCEPT:
+		ballot, err = bm.ACCEPT(block.Hash(), block.Round(), block.Height().Add(1), isaac.NewRandomBlockHash(), 0, block.Proposal())
+	default:
+		ballot, err = bm.INIT(block.Hash(), block.Round(), block.Height().Add(1), isaac.NewRandomBlockHash(), 0, block.Proposal())
+	}
+	t.NoError(err)
+
+	return ballot
+}
+
+func (t *testInterceptors) TestNew() {
+	cc, _ := condition.NewConditionChecker(`from = 'n0'`)
+
+	_, err := NewInterceptor("a", cc, "findme", 0, nil)
+	t.Contains(err.Error(), "unknown intercept action")
+
+	_, err = NewInterceptor("a", cc, "delay", 0, nil)
+	t.Contains(err.Error(), "`delay` must be given")
+
+	_, err = NewInterceptor("a", cc, "mutate", 0, nil)
+	t.Contains(err.Error(), "`fields` must be given")
+
+	_, err = NewInterceptor("a", cc, "mutate", 0, []string{"findme"})
+	t.Contains(err.Error(), "unknown mutate field")
+
+	is := NewInterceptors()
+	t.NoError(is.Add(t.newInterceptor("a", `from = 'n0'`, "drop", 0)))
+
+	err = is.Add(t.newInterceptor("a", `from = 'n0'`, "drop", 0))
+	t.Contains(err.Error(), "already added")
+}
+
+func (t *testInterceptors) TestDrop() {
+	home := node.NewRandomHome().SetAlias("n0").(node.Home)
+
+	is := NewInterceptors()
+	t.NoError(is.Add(t.newInterceptor(
+		"drop-accept", `from = 'n0' AND to = 'n1' AND ballot.stage = 'ACCEPT'`, "drop", 0,
+	)))
+
+	accept := t.newBallot(home, isaac.StageACCEPT)
+	t.Empty(is.Intercept(home, "n1", accept))
+
+	// NOTE the other link
+	t.Equal([]Intercepted{{Seal: accept}}, is.Intercept(home, "n2", accept))
+
+	// NOTE the other stage
+	initBallot := t.newBallot(home, isaac.StageINIT)
+	t.Equal([]Intercepted{{Seal: initBallot}}, is.Intercept(home, "n1", initBallot))
+}
+
+func (t *testInterceptors) TestDelayAndDuplicate() {
+	home := node.NewRandomHome().SetAlias("n0").(node.Home)
+
+	is := NewInterceptors()
+	t.NoError(is.Add(t.newInterceptor("a", `seal.type = 'ballot'`, "delay", time.Second)))
+	t.NoError(is.Add(t.newInterceptor("b", `to = 'n1'`, "duplicate", 0)))
+
+	ballot := t.newBallot(home, isaac.StageINIT)
+
+	r := is.Intercept(home, "n1", ballot)
+	t.Equal(2, len(r))
+	for _, i := range r {
+		t.Equal(ballot, i.Seal)
+		t.Equal(time.Second, i.Delay)
+	}
+
+	r = is.Intercept(home, "n2", ballot)
+	t.Equal([]Intercepted{{Seal: ballot, Delay: time.Second}}, r)
+}
+
+func (t *testInterceptors) TestMutate() {
+	home := node.NewRandomHome().SetAlias("n0").(node.Home)
+
+	is := NewInterceptors()
+	t.NoError(is.Add(t.newInterceptor("a", `ballot.stage = 'ACCEPT'`, "mutate", 0, "block", "round")))
+
+	ballot := t.newBallot(home, isaac.StageACCEPT)
+
+	r := is.Intercept(home, "n1", ballot)
+	t.Equal(1, len(r))
+
+	mutated, ok := r[0].Seal.(isaac.Ballot)
+	t.True(ok)
+	t.NoError(mutated.IsValid())
+	t.False(mutated.Hash().Equal(ballot.Hash()))
+	t.Equal(isaac.StageACCEPT, mutated.Stage())
+	t.True(mutated.Node().Equal(home.Address()))
+	t.True(mutated.Height().Equal(ballot.Height()))
+	t.False(mutated.Block().Equal(ballot.Block()))
+	t.True(mutated.Proposal().Equal(ballot.Proposal()))
+}
+
+func (t *testInterceptors) TestMutateProposal() {
+	home := node.NewRandomHome().SetAlias("n0").(node.Home)
+
+	is := NewInterceptors()
+	t.NoError(is.Add(t.newInterceptor("a", `seal.type = 'proposal'`, "mutate", 0, "last_block", "block")))
+
+	block := isaac.NewRandomBlock()
+	proposal, err := isaac.NewProposal(block.Height().Add(1), 0, block.Hash(), home.Address(), nil)
+	t.NoError(err)
+	t.NoError(proposal.Sign(home.PrivateKey(), nil))
+
+	r := is.Intercept(home, "n1", proposal)
+	t.Equal(1, len(r))
+
+	mutated, ok := r[0].Seal.(isaac.Proposal)
+	t.True(ok)
+	t.NoError(mutated.IsValid())
+	t.True(mutated.Height().Equal(proposal.Height()))
+	t.False(mutated.LastBlock().Equal(proposal.LastBlock()))
+}
+
+func TestInterceptors(t *testing.T) {
+	suite.Run(t, new(testInterceptors))
+}
@@ -3,5 +3,8 @@ package contest_module
 var ProposalMakers []string
 
 func init() {
-	ProposalMakers = append(ProposalMakers, "DefaultProposalMaker")
+	ProposalMakers = append(ProposalMakers,
+		"DefaultProposalMaker",
+		"ConditionProposalMaker",
+	)
 }
@@ -0,0 +1,46 @@
+package contest_module
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// random is the source of the random values of contest_module, like the
+// hashes of blocks and damaged ballots; SetSeed makes them deterministic.
+var random = newLockedRand(time.Now().UnixNano())
+
+type lockedRand struct {
+	sync.Mutex
+	r *rand.Rand
+}
+
+func newLockedRand(seed int64) *lockedRand {
+	return &lockedRand{r: rand.New(rand.NewSource(seed))} // nolint
+}
+
+func (lr *lockedRand) Seed(seed int64) {
+	lr.Lock()
+	defer lr.Unlock()
+
+	lr.r.Seed(seed)
+}
+
+func (lr *lockedRand) Read(b []byte) {
+	lr.Lock()
+	defer lr.Unlock()
+
+	_, _ = lr.r.Read(b)
+}
+
+func (lr *lockedRand) Intn(n int) int {
+	lr.Lock()
+	defer lr.Unlock()
+
+	return lr.r.Intn(n)
+}
+
+// SetSeed sets the seed of the random values of contest_module.
+func SetSeed(seed int64) {
+	random.Seed(seed)
+}
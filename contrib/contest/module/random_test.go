@@ -0,0 +1,28 @@
+package contest_module
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type testRandom struct {
+	suite.Suite
+}
+
+func (t *testRandom) TestSetSeed() {
+	SetSeed(3)
+	a := []interface{}{NewRandomBlockHash(), NewRandomProposalHash(), NewRandomHeight(), NewRandomRound()}
+
+	SetSeed(3)
+	b := []interface{}{NewRandomBlockHash(), NewRandomProposalHash(), NewRandomHeight(), NewRandomRound()}
+
+	t.Equal(a, b)
+
+	SetSeed(4)
+	t.NotEqual(a[0], NewRandomBlockHash())
+}
+
+func TestRandom(t *testing.T) {
+	suite.Run(t, new(testRandom))
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/xerrors"
@@ -18,10 +20,23 @@ import (
 )
 
 type Node struct {
+	sync.RWMutex
 	*common.Logger
-	homeState *isaac.HomeState
-	nt        *contest_module.ChannelNetwork
-	sc        *isaac.StateController
+	home         node.Home
+	clock        *common.SkewedClock
+	nodes        []node.Node
+	globalConfig *Config
+	config       *NodeConfig
+	homeState    *isaac.HomeState
+	nt           *contest_module.FaultNetwork
+	sc           *isaac.StateController
+	verifier     *seal.BatchVerifier
+	ballotMaker  *contest_module.SwitchBallotMaker
+	rootLog      zerolog.Logger
+	running      bool
+	reading      bool
+	receiving    sync.WaitGroup
+	storage      *nodeStorage
 }
 
 func NewNode(
@@ -29,42 +44,107 @@ func NewNode(
 	nodes []node.Node,
 	globalConfig *Config,
 	config *NodeConfig,
+	faults *contest_module.NetworkFaults,
 ) (*Node, error) {
 	rootLog := log.With().Str("node", home.Alias()).Logger()
+
+	// NOTE the clock of node can be skewed by scenario
+	clock := common.NewSkewedClock()
+	home = home.SetClock(clock)
+
+	var no *Node
+	cn := contest_module.NewChannelNetwork(
+		home,
+		func(sl seal.Seal) (seal.Seal, error) {
+			return no.response(sl)
+		},
+	)
+	cn.SetLogger(rootLog)
+
+	no = &Node{
+		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
+			return c.Str("node", home.Alias())
+		}),
+		home:         home,
+		clock:        clock,
+		nodes:        nodes,
+		globalConfig: globalConfig,
+		config:       config,
+		nt:           contest_module.NewFaultNetwork(cn, faults),
+		rootLog:      rootLog,
+		storage:      newNodeStorage(config, rootLog),
+	}
+
+	if err := no.build(); err != nil {
+		return nil, err
+	}
+
+	return no, nil
+}
+
+// build creates the state of node from the config and the storage of node;
+// the blocks stored by node are replayed, so the Policy and the Suffrage are
+// same with the ones before the node is rebuilt. The network is not touched, so
+// the other nodes still can reach the rebuilt node.
+func (no *Node) build() error {
+	home := no.home
+	config := no.config
+	globalConfig := no.globalConfig
+	rootLog := no.rootLog
+	nt := no.nt
+
 	log_ := rootLog.With().Str("module", "node").Logger()
 
-	lastBlock := config.LastBlock()
-	previousBlock := config.Block(lastBlock.Height().Sub(1))
+	previousBlock, lastBlock := no.storage.LastBlocks()
 	homeState := isaac.NewHomeState(home, previousBlock).SetBlock(lastBlock)
 
-	numberOfActing := uint((*config.Modules.Suffrage)["number_of_acting"].(int))
-	if numberOfActing < 1 {
-		numberOfActing = globalConfig.NumberOfNodes()
+	thr, numberOfActing, err := newThreshold(globalConfig, config)
+	if err != nil {
+		return err
 	}
 
-	suffrage := newSuffrage(config, nodes, numberOfActing)
+	ssr := no.storage.SealStorage()
+
+	// NOTE KeySuffrage should be the outermost Suffrage
+	suffrage := isaac.NewKeySuffrage(newSuffrage(config, no.nodes, numberOfActing, no.storage), ssr)
+	suffrage.SetLogger(rootLog)
+
 	ballotChecker := isaac.NewCompilerBallotChecker(homeState, suffrage)
 	ballotChecker.SetLogger(rootLog)
 
-	thr, _ := isaac.NewThreshold(numberOfActing, *config.Policy.Threshold)
-	if err := thr.Set(isaac.StageINIT, globalConfig.NumberOfNodes(), *config.Policy.Threshold); err != nil {
-		return nil, err
-	}
-
-	cm := isaac.NewCompiler(homeState, isaac.NewBallotbox(thr), ballotChecker)
+	cm := isaac.NewCompiler(homeState, isaac.NewBallotbox(thr).SetSuffrage(suffrage), ballotChecker)
 	cm.SetLogger(rootLog)
 
-	nt := contest_module.NewChannelNetwork(
-		home,
-		func(sl seal.Seal) (seal.Seal, error) {
-			return sl, xerrors.Errorf("echo back")
-		},
+	pv := contest_module.NewDummyProposalValidator()
+
+	ballotMaker := contest_module.NewSwitchBallotMaker(
+		newBallotMaker(config.Modules.BallotMaker, homeState, rootLog),
 	)
-	nt.SetLogger(rootLog)
 
-	pv := contest_module.NewDummyProposalValidator()
+	policyKeeper, err := isaac.NewPolicyKeeper(homeState, thr, ssr, config.Policy.Policy())
+	if err != nil {
+		return err
+	}
+	policyKeeper.SetLogger(rootLog)
+	_ = policyKeeper.SetAuthorities(authorities(config, no.nodes)...)
+
+	// NOTE replay the blocks stored by node
+	_, stored := no.storage.Blocks()
+	for _, block := range stored {
+		if err := policyKeeper.StoreBlock(block); err != nil {
+			return err
+		}
 
-	ballotMaker := newBallotMaker(config, homeState, rootLog)
+		if err := suffrage.StoreBlock(block); err != nil {
+			return err
+		}
+	}
+
+	verifier := seal.NewBatchVerifier(time.Millisecond, 100, uint(runtime.NumCPU()), nil)
+	verifier.SetLogger(rootLog)
+
+	sealFetcher := isaac.NewSealFetcher(home, nt, ssr, suffrage).SetSealVerifier(verifier)
+	sealFetcher.SetLogger(rootLog)
 
 	var sc *isaac.StateController
 	{ // state handlers
@@ -78,15 +158,20 @@ func NewNode(
 			suffrage,
 			ballotMaker,
 			pv,
-			*config.Policy.IntervalBroadcastINITBallotInJoin,
-			*config.Policy.TimeoutWaitVoteResultInJoin,
+			policyKeeper,
 		)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		js.SetLogger(rootLog)
+		_ = js.SetSealFetcher(sealFetcher)
 
-		dp := newProposalMaker(config, home, rootLog)
+		dp := newProposalMaker(config, homeState, rootLog, policyKeeper, suffrage)
+		if r, ok := dp.(contest_module.SealReplacer); ok {
+			_ = nt.SetSealReplacer(r)
+		} else {
+			_ = nt.SetSealReplacer(nil)
+		}
 
 		cs, err := isaac.NewConsensusStateHandler(
 			homeState,
@@ -96,23 +181,30 @@ func NewNode(
 			ballotMaker,
 			pv,
 			dp,
-			*config.Policy.TimeoutWaitBallot,
-			*config.Policy.TimeoutWaitINITBallot,
+			policyKeeper,
 		)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		cs.SetLogger(rootLog)
+		_ = cs.SetSealFetcher(sealFetcher)
 
 		ss := isaac.NewStoppedStateHandler()
 		ss.SetLogger(rootLog)
 
-		ssr := newSealStorage(config, rootLog)
-
-		sc = isaac.NewStateController(homeState, cm, ssr, bs, js, cs, ss)
+		sc = isaac.NewStateController(homeState, cm, ssr, policyKeeper, bs, js, cs, ss)
 		sc.SetLogger(rootLog)
+		_ = sc.SetSuffrage(suffrage, thr)
+		_ = sc.SetHashAlgorithms(globalConfig.Hash.Algorithms())
+
+		if o, ok := suffrage.Suffrage.(isaac.VoteResultObserver); ok {
+			_ = sc.AddVoteResultObservers(o)
+		}
+		_ = sc.AddSealReceivers(suffrage)
 	}
 
+	_ = sc.SetSealVerifier(verifier)
+
 	log_.Info().
 		Object("config", config).
 		Object("home", home).
@@ -122,70 +214,207 @@ func NewNode(
 		Uint("number_of_acting", numberOfActing).
 		Msg("node created")
 
-	n := &Node{
-		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
-			return c.Str("node", home.Alias())
-		}),
-		homeState: homeState,
-		nt:        nt,
-		sc:        sc,
+	no.homeState = homeState
+	no.sc = sc
+	no.verifier = verifier
+	no.ballotMaker = ballotMaker
+
+	return nil
+}
+
+// response handles the requests from the other nodes; only the seal request is
+// served from the seal storage.
+func (no *Node) response(sl seal.Seal) (seal.Seal, error) {
+	if r, ok := sl.(isaac.Request); !ok || r.Request() != isaac.RequestSeal {
+		return sl, xerrors.Errorf("echo back")
 	}
 
-	return n, nil
+	// NOTE the storage is not replaced by Wipe(), so it is not locked by the
+	// lock of node, which is held while stopping.
+	return isaac.ResponseSealRequest(no.storage.SealStorage(), sl)
 }
 
 func (no *Node) Home() node.Home {
-	return no.homeState.Home()
+	return no.home
+}
+
+// SetClockSkew skews the clock of node; the other nodes are not affected.
+func (no *Node) SetClockSkew(d time.Duration) {
+	no.clock.SetSkew(d)
+
+	no.Log().Debug().Dur("skew", d).Msg("clock skewed")
 }
 
 func (no *Node) Start() error {
+	no.Lock()
+	defer no.Unlock()
+
+	if no.running {
+		return common.DaemonAleadyStartedError
+	}
+
 	started := time.Now()
 
 	if err := no.nt.Start(); err != nil {
 		return err
 	}
 
+	if err := no.verifier.Start(); err != nil {
+		return err
+	}
+
 	if err := no.sc.Start(); err != nil {
 		return err
 	}
 
-	go func() {
-		for m := range no.nt.Reader() {
-			go func(m interface{}) {
-				st := time.Now()
-				err := no.sc.Receive(m)
-				no.sc.Log().Debug().
-					Err(err).
-					Dur("elapsed", time.Since(st)).
-					Msg("message received")
-			}(m)
-		}
-	}()
+	// NOTE the reader of network is not closed by Stop, so the reading
+	// goroutine is started only once and it is reused after restarting.
+	if !no.reading {
+		no.reading = true
+		go no.read()
+	}
+
+	no.running = true
 	no.Log().Debug().Dur("elapsed", time.Since(started)).Msg("node started")
 
 	return nil
 }
 
+func (no *Node) read() {
+	for m := range no.nt.Reader() {
+		no.RLock()
+		running := no.running
+		sc := no.sc
+		if running {
+			no.receiving.Add(1)
+		}
+		no.RUnlock()
+
+		if !running {
+			continue
+		}
+
+		go func(m interface{}) {
+			defer no.receiving.Done()
+
+			st := time.Now()
+			err := sc.Receive(m)
+			sc.Log().Debug().
+				Err(err).
+				Dur("elapsed", time.Since(st)).
+				Msg("message received")
+		}(m)
+	}
+}
+
 func (no *Node) Stop() error {
+	no.Lock()
+	defer no.Unlock()
+
+	if !no.running {
+		return nil
+	}
+
+	no.running = false
+
 	if err := no.sc.Stop(); err != nil {
 		return err
 	}
 
+	// NOTE the seals received before stop are handled before the network and
+	// verifier are stopped
+	no.receiving.Wait()
+
 	if err := no.nt.Stop(); err != nil {
 		return err
 	}
 
+	if err := no.verifier.Stop(); err != nil {
+		return err
+	}
+
+	no.Log().Debug().Msg("node stopped")
+
+	return nil
+}
+
+func (no *Node) IsStopped() bool {
+	no.RLock()
+	defer no.RUnlock()
+
+	return !no.running
+}
+
+// Wipe drops the in-memory state of the stopped node, like ballots, pending
+// transactions and ballot maker; like the crashed node, the blocks and seals in
+// the storage of node are kept, so the node will be started from the last
+// stored block.
+func (no *Node) Wipe() error {
+	no.Lock()
+	defer no.Unlock()
+
+	if no.running {
+		return xerrors.Errorf("running node can not be wiped; node=%q", no.home.Alias())
+	}
+
+	if err := no.build(); err != nil {
+		return err
+	}
+
+	no.Log().Debug().Msg("node wiped")
+
+	return nil
+}
+
+// SetBallotMaker replaces the BallotMaker of the running node.
+func (no *Node) SetBallotMaker(bmc *BallotMakerConfig) error {
+	if err := bmc.IsValid(nil); err != nil {
+		return err
+	}
+
+	no.RLock()
+	defer no.RUnlock()
+
+	no.ballotMaker.Switch(newBallotMaker(bmc, no.homeState, no.rootLog))
+
 	return nil
 }
 
-func NewHome(i uint) node.Home {
-	pk, _ := keypair.NewStellarPrivateKey()
+// newThreshold returns the Threshold of node with the number of acting
+// suffrage members; INIT stage needs the majority of all the nodes.
+func newThreshold(globalConfig *Config, config *NodeConfig) (*isaac.Threshold, uint, error) {
+	numberOfActing := uint((*config.Modules.Suffrage)["number_of_acting"].(int))
+	if numberOfActing < 1 {
+		numberOfActing = globalConfig.NumberOfNodes()
+	}
+
+	thr, err := isaac.NewThreshold(numberOfActing, *config.Policy.Threshold)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := thr.Set(isaac.StageINIT, globalConfig.NumberOfNodes(), *config.Policy.Threshold); err != nil {
+		return nil, 0, err
+	}
+
+	return thr, numberOfActing, nil
+}
+
+// NewHome generates the Home of node from the seed and alias, so the same
+// seed makes the same keys.
+func NewHome(seed int64, alias string) node.Home {
+	pk, _ := keypair.Stellar{}.NewFromSeed([]byte(fmt.Sprintf("%d:%s", seed, alias)))
 
-	h, _ := node.NewAddress([]byte{uint8(i)})
-	return node.NewHome(h, pk)
+	h, _ := node.NewAddressFromPublicKey(node.DefaultNetworkID, pk.PublicKey())
+	return node.NewHome(h, pk).SetAlias(alias).(node.Home)
 }
 
-func newSuffrage(config *NodeConfig, nodes []node.Node, globalNumberOfNodes uint) isaac.Suffrage {
+func newSuffrage(
+	config *NodeConfig,
+	nodes []node.Node,
+	globalNumberOfNodes uint,
+	storage *nodeStorage,
+) isaac.Suffrage {
 	sc := *config.Modules.Suffrage
 
 	numberOfActing := uint(sc["number_of_acting"].(int))
@@ -193,6 +422,7 @@ func newSuffrage(config *NodeConfig, nodes []node.Node, globalNumberOfNodes uint
 		numberOfActing = globalNumberOfNodes
 	}
 
+	var suffrage isaac.Suffrage
 	switch sc["name"] {
 	case "FixedProposerSuffrage":
 		// find proposer
@@ -207,32 +437,96 @@ func newSuffrage(config *NodeConfig, nodes []node.Node, globalNumberOfNodes uint
 			panic(xerrors.Errorf("failed to find proposer: %v", config))
 		}
 
-		return contest_module.NewFixedProposerSuffrage(proposer, numberOfActing, nodes...)
+		suffrage = contest_module.NewFixedProposerSuffrage(proposer, numberOfActing, nodes...)
 	case "RoundrobinSuffrage":
-		return contest_module.NewRoundrobinSuffrage(numberOfActing, nodes...)
+		suffrage = contest_module.NewRoundrobinSuffrage(numberOfActing, nodes...)
 	default:
 		panic(xerrors.Errorf("unknown suffrage config: %v", config))
 	}
+
+	// NOTE the block recorder should be the innermost Suffrage
+	suffrage = newBlockRecorder(suffrage, storage)
+
+	if window, ok := sc["liveness_window"].(int); ok && window > 0 {
+		maxMissed := 1 // NOTE default of SuffrageConfig
+		if i, ok := sc["liveness_max_missed"].(int); ok {
+			maxMissed = i
+		}
+
+		ls := isaac.NewLivenessSuffrage(suffrage, uint(window), uint(maxMissed))
+
+		// NOTE the missed proposals are rebuilt from the imported blocks; the
+		// stored blocks are replayed by build()
+		imported, _ := storage.Blocks()
+		ls.Load(imported...)
+
+		suffrage = ls
+	}
+
+	return suffrage
 }
 
-func newProposalMaker(config *NodeConfig, home node.Home, l zerolog.Logger) isaac.ProposalMaker {
+func newProposalMaker(
+	config *NodeConfig,
+	homeState *isaac.HomeState,
+	l zerolog.Logger,
+	pools ...isaac.OperationPool,
+) isaac.ProposalMaker {
 	pc := *config.Modules.ProposalMaker
-	switch pc["name"] {
-	case "DefaultProposalMaker":
+
+	newDefault := func() isaac.ProposalMaker {
 		delay, err := time.ParseDuration(pc["delay"].(string))
 		if err != nil {
 			panic(err)
 		}
 
-		dp := isaac.NewDefaultProposalMaker(home, delay)
+		dp := isaac.NewDefaultProposalMaker(homeState.Home(), delay, pools...)
 		dp.SetLogger(l)
 
 		return dp
+	}
+
+	switch pc["name"] {
+	case "DefaultProposalMaker":
+		return newDefault()
+	case "ConditionProposalMaker":
+		conditions := map[string]contest_module.ConditionProposalHandler{}
+
+		if s, found := pc["conditions"]; found {
+			for n, c := range s.(ProposalMakerConfig) {
+				cc, err := parseConditionProposalHandler(c.(ProposalMakerConfig))
+				if err != nil {
+					panic(err)
+				}
+
+				conditions[n] = cc
+			}
+		}
+
+		cp := contest_module.NewConditionProposalMaker(newDefault(), homeState, conditions)
+		cp.SetLogger(l)
+
+		return cp
 	default:
 		panic(xerrors.Errorf("unknown proposal maker config: %v", config))
 	}
 }
 
+// authorities returns the public keys of the authority nodes of config.
+func authorities(config *NodeConfig, nodes []node.Node) []keypair.PublicKey {
+	var keys []keypair.PublicKey
+	for _, alias := range config.Policy.Authorities {
+		for _, n := range nodes {
+			if n.Alias() == alias {
+				keys = append(keys, n.PublicKey())
+				break
+			}
+		}
+	}
+
+	return keys
+}
+
 func newSealStorage(_ *NodeConfig, l zerolog.Logger) isaac.SealStorage {
 	ss := contest_module.NewMemorySealStorage()
 	ss.SetLogger(l)
@@ -240,14 +534,12 @@ func newSealStorage(_ *NodeConfig, l zerolog.Logger) isaac.SealStorage {
 	return ss
 }
 
-func newBallotMaker(config *NodeConfig, homeState *isaac.HomeState, l zerolog.Logger) isaac.BallotMaker {
-	pc := *config.Modules.BallotMaker
+func newBallotMaker(bmc *BallotMakerConfig, homeState *isaac.HomeState, l zerolog.Logger) isaac.BallotMaker {
+	pc := *bmc
 	switch pc["name"] {
 	case "DefaultBallotMaker":
 		return isaac.NewDefaultBallotMaker(homeState.Home())
 	case "DamangedBallotMaker":
-		bmc := config.Modules.BallotMaker
-
 		var height, round, stage string
 
 		if s, found := (*bmc)["height"]; !found {
@@ -274,7 +566,6 @@ func newBallotMaker(config *NodeConfig, homeState *isaac.HomeState, l zerolog.Lo
 
 		return db
 	case "ConditionBallotMaker":
-		bmc := config.Modules.BallotMaker
 		conditions := map[string]contest_module.ConditionBallotHandler{}
 
 		if s, found := (*bmc)["conditions"]; found {
@@ -296,7 +587,7 @@ func newBallotMaker(config *NodeConfig, homeState *isaac.HomeState, l zerolog.Lo
 	}
 }
 
-func getAllNodesFromConfig(config *Config) []node.Node {
+func getAllNodesFromConfig(config *Config, seed int64) []node.Node {
 	var nodeNames []string
 	for n := range config.Nodes {
 		nodeNames = append(nodeNames, n)
@@ -312,9 +603,8 @@ func getAllNodesFromConfig(config *Config) []node.Node {
 	)
 
 	var nodeList []node.Node
-	for i, name := range nodeNames[:config.NumberOfNodes()] {
-		n := NewHome(uint(i)).SetAlias(name)
-		nodeList = append(nodeList, n)
+	for _, name := range nodeNames[:config.NumberOfNodes()] {
+		nodeList = append(nodeList, NewHome(seed, name))
 	}
 
 	return nodeList
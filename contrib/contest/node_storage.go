@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/rs/zerolog"
+
+	"github.com/spikeekips/mitum/isaac"
+	"github.com/spikeekips/mitum/node"
+)
+
+// nodeStorage is the storage of node like the disk; it is not dropped when the
+// node is wiped, so the wiped node is rebuilt from the stored blocks and
+// seals.
+// * imported: the blocks of config; they do not have the proposals
+// * stored: the blocks, which are stored by node
+type nodeStorage struct {
+	sync.RWMutex
+	sealStorage isaac.SealStorage
+	imported    []isaac.Block
+	stored      []isaac.Block
+}
+
+func newNodeStorage(config *NodeConfig, l zerolog.Logger) *nodeStorage {
+	var imported []isaac.Block
+	last := config.LastBlock().Height()
+	for h := isaac.GenesisHeight; h.Cmp(last) <= 0; h = h.Add(1) {
+		if b := config.Block(h); !b.Empty() {
+			imported = append(imported, b)
+		}
+	}
+
+	return &nodeStorage{
+		sealStorage: newSealStorage(config, l),
+		imported:    imported,
+	}
+}
+
+func (ns *nodeStorage) SealStorage() isaac.SealStorage {
+	return ns.sealStorage
+}
+
+// Blocks returns the imported and the stored blocks, which are ordered by
+// height.
+func (ns *nodeStorage) Blocks() ([]isaac.Block, []isaac.Block) {
+	ns.RLock()
+	defer ns.RUnlock()
+
+	return ns.imported, ns.stored
+}
+
+// LastBlocks returns the previous and the last block.
+func (ns *nodeStorage) LastBlocks() (isaac.Block, isaac.Block) {
+	ns.RLock()
+	defer ns.RUnlock()
+
+	blocks := make([]isaac.Block, 0, len(ns.imported)+len(ns.stored))
+	blocks = append(blocks, ns.imported...)
+	blocks = append(blocks, ns.stored...)
+
+	if len(blocks) < 2 {
+		return isaac.Block{}, blocks[len(blocks)-1]
+	}
+
+	return blocks[len(blocks)-2], blocks[len(blocks)-1]
+}
+
+// storeBlock keeps the new block; the block, which is not higher than the last
+// one, is ignored, so the replayed blocks are not stored again.
+func (ns *nodeStorage) storeBlock(block isaac.Block) {
+	ns.Lock()
+	defer ns.Unlock()
+
+	last := ns.imported[len(ns.imported)-1]
+	if len(ns.stored) > 0 {
+		last = ns.stored[len(ns.stored)-1]
+	}
+
+	if block.Height().Cmp(last.Height()) < 1 {
+		return
+	}
+
+	ns.stored = append(ns.stored, block)
+}
+
+// blockRecorder records the blocks, which are stored by node, to the
+// nodeStorage; it should be the innermost Suffrage, so the block is recorded
+// after the PolicyKeeper and the outer Suffrages stored it.
+type blockRecorder struct {
+	isaac.Suffrage
+	storage *nodeStorage
+}
+
+func newBlockRecorder(suffrage isaac.Suffrage, storage *nodeStorage) *blockRecorder {
+	return &blockRecorder{Suffrage: suffrage, storage: storage}
+}
+
+func (br *blockRecorder) AddNodes(nodes ...node.Node) isaac.Suffrage {
+	br.Suffrage = br.Suffrage.AddNodes(nodes...)
+
+	return br
+}
+
+func (br *blockRecorder) RemoveNodes(nodes ...node.Node) isaac.Suffrage {
+	br.Suffrage = br.Suffrage.RemoveNodes(nodes...)
+
+	return br
+}
+
+func (br *blockRecorder) StoreBlock(block isaac.Block) error {
+	if bs, ok := br.Suffrage.(isaac.BlockStorer); ok {
+		if err := bs.StoreBlock(block); err != nil {
+			return err
+		}
+	}
+
+	br.storage.storeBlock(block)
+
+	return nil
+}
+
+func (br *blockRecorder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(br.Suffrage)
+}
@@ -2,16 +2,28 @@ package main
 
 import (
 	"sync"
+	"time"
 
+	"github.com/spikeekips/mitum/common"
+	contest_module "github.com/spikeekips/mitum/contrib/contest/module"
 	"github.com/spikeekips/mitum/node"
 )
 
 type Nodes struct {
 	sync.RWMutex
-	nodes []*Node
+	nodes  []*Node
+	faults *contest_module.NetworkFaults
 }
 
-func NewNodes(config *Config, nodeList []node.Node) (*Nodes, error) { // nolint
+func NewNodes(config *Config, nodeList []node.Node, seed int64) (*Nodes, error) { // nolint
+	faults, err := config.Network.Faults()
+	if err != nil {
+		return nil, err
+	}
+	faults.SetLogger(log)
+	faults.Interceptors().SetLogger(log)
+	_ = faults.SetSeed(seed)
+
 	var wg sync.WaitGroup
 	wg.Add(len(nodeList))
 
@@ -25,6 +37,7 @@ func NewNodes(config *Config, nodeList []node.Node) (*Nodes, error) { // nolint
 				nodeList,
 				config,
 				c,
+				faults,
 			)
 			if err != nil {
 				panic(err)
@@ -49,11 +62,29 @@ func NewNodes(config *Config, nodeList []node.Node) (*Nodes, error) { // nolint
 	// connect network
 	for _, n := range nodes {
 		for _, o := range nodes {
-			n.nt.AddMembers(o.nt)
+			n.nt.AddMembers(o.nt.ChannelNetwork)
+		}
+	}
+
+	return &Nodes{nodes: nodes, faults: faults}, nil
+}
+
+// Node returns the node by alias.
+func (ns *Nodes) Node(alias string) *Node {
+	ns.RLock()
+	defer ns.RUnlock()
+
+	for _, n := range ns.nodes {
+		if n.Home().Alias() == alias {
+			return n
 		}
 	}
 
-	return &Nodes{nodes: nodes}, nil
+	return nil
+}
+
+func (ns *Nodes) Faults() *contest_module.NetworkFaults {
+	return ns.faults
 }
 
 func (ns *Nodes) Start() error {
@@ -65,6 +96,8 @@ func (ns *Nodes) Start() error {
 
 	errChan := make(chan error, len(ns.nodes))
 
+	ns.faults.Start()
+
 	for _, n := range ns.nodes {
 		go func(n *Node) {
 			errChan <- n.Start()
@@ -100,9 +133,11 @@ func (ns *Nodes) Stop() error {
 		}(n)
 	}
 
-	wg.Wait()
+	waitStopped(&wg)
 	close(errChan)
 
+	ns.faults.Stop()
+
 	for err := range errChan {
 		if err != nil {
 			return err
@@ -111,3 +146,25 @@ func (ns *Nodes) Stop() error {
 
 	return nil
 }
+
+// waitStopped waits until the nodes are stopped. The nodes wait the seals in
+// handling, so the handlers waiting the stopped simulated clock are released
+// by moving the clock.
+func waitStopped(wg *sync.WaitGroup) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-time.After(time.Millisecond * 10):
+			if c, ok := common.CurrentClock().(*common.SimulatedClock); ok && c.IsStopped() {
+				_ = c.AdvanceToNext()
+			}
+		}
+	}
+}
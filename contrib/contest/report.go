@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spikeekips/mitum/contrib/contest/condition"
+)
+
+var stateChangedLogMessage = []byte(`state changed`)
+
+const (
+	reportJSONFile = "report.json"
+	reportTextFile = "report.txt"
+)
+
+// reportLog is the log of nodes for the report; the new block and the state
+// changes are collected.
+type reportLog struct {
+	Node         string `json:"node"`
+	T            string `json:"t"`
+	CurrentState string `json:"current_state"`
+	NewState     string `json:"new_state"`
+	Block        struct {
+		Hash   invariantHashLog `json:"hash"`
+		Height uint64           `json:"height"`
+		Round  uint64           `json:"round"`
+	} `json:"block"`
+}
+
+type ReportState struct {
+	At   string `json:"at"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ReportNode is the result of node.
+// * Height, Block: the last block
+// * Rounds: the number of blocks by the round of block
+// * States: the state transitions
+type ReportNode struct {
+	Height uint64          `json:"height"`
+	Block  string          `json:"block"`
+	State  string          `json:"state"`
+	Rounds map[uint64]uint `json:"rounds"`
+	States []ReportState   `json:"states"`
+}
+
+type ReportConditions struct {
+	Satisfied bool            `json:"satisfied"`
+	Queries   map[string]bool `json:"queries"`
+}
+
+type ReportViolation struct {
+	Invariant string    `json:"invariant"`
+	Height    uint64    `json:"height"`
+	Nodes     []string  `json:"nodes"`
+	Message   string    `json:"message"`
+	At        time.Time `json:"at"`
+}
+
+type ReportInvariants struct {
+	Violations []ReportViolation `json:"violations"`
+}
+
+// ReportResult is the report of run; Blocks has the block hashes of nodes by
+// height, so the runs can be compared.
+type ReportResult struct {
+	Seed       int64                        `json:"seed"`
+	Config     string                       `json:"config"`
+	Started    time.Time                    `json:"started"`
+	Stopped    time.Time                    `json:"stopped"`
+	Elapsed    string                       `json:"elapsed"`
+	ExitCode   int                          `json:"exit_code"`
+	Nodes      map[string]*ReportNode       `json:"nodes"`
+	Blocks     map[uint64]map[string]string `json:"blocks"`
+	Conditions *ReportConditions            `json:"conditions,omitempty"`
+	Invariants *ReportInvariants            `json:"invariants,omitempty"`
+}
+
+// Report collects the result of run from the log of nodes.
+type Report struct {
+	sync.RWMutex
+	seed       int64
+	config     string
+	started    time.Time
+	nodes      map[string]*ReportNode
+	blocks     map[uint64]map[string]string
+	conditions *condition.MultipleConditionChecker
+	invariants *Invariants
+}
+
+func NewReport(seed int64, config string, aliases []string) *Report {
+	nodes := map[string]*ReportNode{}
+	for _, a := range aliases {
+		nodes[a] = &ReportNode{Rounds: map[uint64]uint{}}
+	}
+
+	return &Report{
+		seed:    seed,
+		config:  config,
+		started: time.Now(),
+		nodes:   nodes,
+		blocks:  map[uint64]map[string]string{},
+	}
+}
+
+func (rp *Report) SetConditions(cp *condition.MultipleConditionChecker) *Report {
+	rp.Lock()
+	defer rp.Unlock()
+
+	rp.conditions = cp
+
+	return rp
+}
+
+func (rp *Report) SetInvariants(iv *Invariants) *Report {
+	rp.Lock()
+	defer rp.Unlock()
+
+	rp.invariants = iv
+
+	return rp
+}
+
+func (rp *Report) Write(b []byte) (int, error) {
+	isBlock := bytes.Contains(b, newBlockLogMessage)
+	if !isBlock && !bytes.Contains(b, stateChangedLogMessage) {
+		return len(b), nil
+	}
+
+	var l reportLog
+	if err := json.Unmarshal(b, &l); err != nil || len(l.Node) < 1 {
+		return len(b), nil
+	}
+
+	rp.Lock()
+	defer rp.Unlock()
+
+	rn, found := rp.nodes[l.Node]
+	if !found {
+		return len(b), nil
+	}
+
+	switch {
+	case isBlock && len(l.Block.Hash.Hash) > 0:
+		rn.Height = l.Block.Height
+		rn.Block = l.Block.Hash.Hash
+		rn.Rounds[l.Block.Round]++
+
+		hashes, found := rp.blocks[l.Block.Height]
+		if !found {
+			hashes = map[string]string{}
+			rp.blocks[l.Block.Height] = hashes
+		}
+		hashes[l.Node] = l.Block.Hash.Hash
+	case len(l.NewState) > 0:
+		rn.State = l.NewState
+		rn.States = append(rn.States, ReportState{At: l.T, From: l.CurrentState, To: l.NewState})
+	}
+
+	return len(b), nil
+}
+
+func (rp *Report) Result(exitCode int) ReportResult {
+	rp.RLock()
+	defer rp.RUnlock()
+
+	stopped := time.Now()
+
+	// NOTE copy the collected, the log can be written while the result is
+	// used
+	nodes := map[string]*ReportNode{}
+	for a, rn := range rp.nodes {
+		rounds := map[uint64]uint{}
+		for r, c := range rn.Rounds {
+			rounds[r] = c
+		}
+
+		nodes[a] = &ReportNode{
+			Height: rn.Height,
+			Block:  rn.Block,
+			State:  rn.State,
+			Rounds: rounds,
+			States: append([]ReportState(nil), rn.States...),
+		}
+	}
+
+	blocks := map[uint64]map[string]string{}
+	for h, hashes := range rp.blocks {
+		bh := map[string]string{}
+		for a, hash := range hashes {
+			bh[a] = hash
+		}
+		blocks[h] = bh
+	}
+
+	result := ReportResult{
+		Seed:     rp.seed,
+		Config:   rp.config,
+		Started:  rp.started,
+		Stopped:  stopped,
+		Elapsed:  stopped.Sub(rp.started).String(),
+		ExitCode: exitCode,
+		Nodes:    nodes,
+		Blocks:   blocks,
+	}
+
+	if rp.conditions != nil {
+		satisfied := rp.conditions.Satisfied()
+		queries := map[string]bool{}
+		for _, q := range rp.conditions.Queries() {
+			_, found := satisfied[q]
+			queries[q] = found
+		}
+
+		result.Conditions = &ReportConditions{
+			Satisfied: rp.conditions.AllSatisfied(),
+			Queries:   queries,
+		}
+	}
+
+	if rp.invariants != nil {
+		violations := []ReportViolation{}
+		for _, v := range rp.invariants.Violations() {
+			violations = append(violations, ReportViolation{
+				Invariant: v.Invariant,
+				Height:    v.Height,
+				Nodes:     v.Nodes,
+				Message:   v.Message,
+				At:        v.At,
+			})
+		}
+
+		result.Invariants = &ReportInvariants{Violations: violations}
+	}
+
+	return result
+}
+
+// WriteFiles writes the report to the directory as `report.json` and
+// `report.txt`.
+func (rp *Report) WriteFiles(dir string, exitCode int) error {
+	result := rp.Result(exitCode)
+
+	b, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, reportJSONFile), b, 0644); err != nil { // nolint
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(dir, reportTextFile))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return WriteReportSummary(f, result)
+}
+
+// WriteReportSummary writes the human-readable summary of report.
+func WriteReportSummary(w io.Writer, result ReportResult) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "seed: %d\n", result.Seed)
+	fmt.Fprintf(&sb, "config: %s\n", result.Config)
+	fmt.Fprintf(&sb, "elapsed: %s\n", result.Elapsed)
+	fmt.Fprintf(&sb, "exit: %d\n", result.ExitCode)
+
+	aliases := make([]string, 0, len(result.Nodes))
+	for a := range result.Nodes {
+		aliases = append(aliases, a)
+	}
+	sort.Strings(aliases)
+
+	fmt.Fprintln(&sb, "nodes:")
+	for _, a := range aliases {
+		rn := result.Nodes[a]
+
+		rounds := make([]uint64, 0, len(rn.Rounds))
+		for r := range rn.Rounds {
+			rounds = append(rounds, r)
+		}
+		sort.Slice(rounds, func(i, j int) bool { return rounds[i] < rounds[j] })
+
+		rs := make([]string, len(rounds))
+		for i, r := range rounds {
+			rs[i] = fmt.Sprintf("%d:%d", r, rn.Rounds[r])
+		}
+
+		fmt.Fprintf(
+			&sb,
+			"  %s: height=%d block=%s state=%s rounds=[%s] state_changes=%d\n",
+			a, rn.Height, rn.Block, rn.State, strings.Join(rs, " "), len(rn.States),
+		)
+	}
+
+	var diverged []uint64
+	for h, hashes := range result.Blocks {
+		var first string
+		for _, hash := range hashes {
+			if len(first) < 1 {
+				first = hash
+			} else if hash != first {
+				diverged = append(diverged, h)
+				break
+			}
+		}
+	}
+	sort.Slice(diverged, func(i, j int) bool { return diverged[i] < diverged[j] })
+
+	fmt.Fprintf(&sb, "heights: %d, diverged: %v\n", len(result.Blocks), diverged)
+
+	if c := result.Conditions; c != nil {
+		var n int
+		for _, ok := range c.Queries {
+			if ok {
+				n++
+			}
+		}
+		fmt.Fprintf(&sb, "conditions: satisfied=%v (%d/%d)\n", c.Satisfied, n, len(c.Queries))
+	}
+
+	if iv := result.Invariants; iv != nil {
+		fmt.Fprintf(&sb, "invariants: %d violation(s)\n", len(iv.Violations))
+		for _, v := range iv.Violations {
+			fmt.Fprintf(&sb, "  %s height=%d nodes=%v: %s\n", v.Invariant, v.Height, v.Nodes, v.Message)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
@@ -6,7 +6,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func run(cmd *cobra.Command, nodes *Nodes) error {
+func run(cmd *cobra.Command, nodes *Nodes, scenario *Scenario) error {
 	defer func() {
 		if err := nodes.Stop(); err != nil {
 			cmd.Println("Error: failed to stop nodes:", err.Error())
@@ -18,5 +18,11 @@ func run(cmd *cobra.Command, nodes *Nodes) error {
 		return err
 	}
 
+	if scenario != nil {
+		if err := scenario.Start(nodes); err != nil {
+			return err
+		}
+	}
+
 	select {}
 }
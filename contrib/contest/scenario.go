@@ -0,0 +1,319 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/contrib/contest/condition"
+)
+
+var ScenarioActions = []string{
+	"stop",
+	"start",
+	"restart",
+	"ballot-maker",
+	"open-partition",
+	"heal-partition",
+	"skew-clock",
+}
+
+// ScenarioActionConfig is the action of scenario, which is executed at the
+// given time after the nodes started, or when the condition is matched with
+// the log at first time.
+// * stop, start: stop and start `node`; with `wipe`, `start` drops the
+// in-memory state of node before starting, but the stored blocks are kept
+// * restart: stop `node` and start again after `duration`; `wipe` also can be
+// given
+// * ballot-maker: replace the ballot maker of `node` with `ballot_maker`
+// * open-partition, heal-partition: activate and deactivate `partition` of
+// network config
+// * skew-clock: skew the clock of `node` by `duration`
+type ScenarioActionConfig struct {
+	At          *time.Duration     `yaml:"at,omitempty"`
+	Condition   *string            `yaml:"condition,omitempty"`
+	Action      string             `yaml:"action"`
+	Node        *string            `yaml:"node,omitempty"`
+	Partition   *string            `yaml:"partition,omitempty"`
+	BallotMaker *BallotMakerConfig `yaml:"ballot_maker,omitempty"`
+	Duration    *time.Duration     `yaml:"duration,omitempty"`
+	Wipe        *bool              `yaml:"wipe,omitempty"`
+}
+
+func (sc *ScenarioActionConfig) IsValid(network *NetworkConfig) error {
+	if (sc.At == nil) == (sc.Condition == nil) {
+		return xerrors.Errorf("one of `at` or `condition` should be given; action=%q", sc.Action)
+	}
+
+	if sc.At != nil && *sc.At < 0 {
+		return xerrors.Errorf("`at` should not be negative; action=%q", sc.Action)
+	}
+
+	if sc.Condition != nil {
+		if _, err := condition.NewConditionChecker(*sc.Condition); err != nil {
+			return xerrors.Errorf("invalid condition of scenario, %q: %w", *sc.Condition, err)
+		}
+	}
+
+	var found bool
+	for _, a := range ScenarioActions {
+		if a == sc.Action {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return xerrors.Errorf("unknown scenario action found: %v", sc.Action)
+	}
+
+	switch sc.Action {
+	case "stop", "start", "restart", "ballot-maker":
+		if sc.Node == nil {
+			return xerrors.Errorf("`node` must be given for `%s`", sc.Action)
+		}
+
+		if sc.Wipe != nil && sc.Action != "start" && sc.Action != "restart" {
+			return xerrors.Errorf("`wipe` is only for `start` and `restart`; action=%q", sc.Action)
+		}
+
+		if sc.Duration != nil && *sc.Duration < 0 {
+			return xerrors.Errorf("`duration` should not be negative; action=%q", sc.Action)
+		}
+
+		if sc.Action == "ballot-maker" {
+			if sc.BallotMaker == nil {
+				return xerrors.Errorf("`ballot_maker` must be given for `%s`", sc.Action)
+			}
+
+			if err := sc.BallotMaker.IsValid(nil); err != nil {
+				return err
+			}
+		}
+	case "open-partition", "heal-partition":
+		if sc.Partition == nil {
+			return xerrors.Errorf("`partition` must be given for `%s`", sc.Action)
+		}
+
+		if _, found := network.Partitions[*sc.Partition]; !found {
+			return xerrors.Errorf("unknown partition found in scenario: %v", *sc.Partition)
+		}
+	case "skew-clock":
+		if sc.Node == nil {
+			return xerrors.Errorf("`node` must be given for `%s`", sc.Action)
+		}
+
+		if sc.Duration == nil {
+			return xerrors.Errorf("`duration` must be given for `%s`", sc.Action)
+		}
+	}
+
+	return nil
+}
+
+func (sc *ScenarioActionConfig) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("action", sc.Action)
+
+	if sc.At != nil {
+		e.Dur("at", *sc.At)
+	}
+
+	if sc.Condition != nil {
+		e.Str("condition", *sc.Condition)
+	}
+
+	if sc.Node != nil {
+		e.Str("node", *sc.Node)
+	}
+
+	if sc.Partition != nil {
+		e.Str("partition", *sc.Partition)
+	}
+
+	if sc.Duration != nil {
+		e.Dur("duration", *sc.Duration)
+	}
+
+	if sc.Wipe != nil {
+		e.Bool("wipe", *sc.Wipe)
+	}
+}
+
+func (sc *ScenarioActionConfig) wipe() bool {
+	return sc.Wipe != nil && *sc.Wipe
+}
+
+// Scenario executes the actions against Nodes. Scenario is also io.Writer
+// for the log, to check the conditions of actions.
+type Scenario struct {
+	sync.RWMutex
+	*common.Logger
+	actions []*ScenarioActionConfig
+	pending map[int]condition.ConditionChecker
+	nodes   *Nodes
+	stop    chan struct{}
+}
+
+func NewScenario(actions []*ScenarioActionConfig) (*Scenario, error) {
+	pending := map[int]condition.ConditionChecker{}
+	for i, a := range actions {
+		if a.Condition == nil {
+			continue
+		}
+
+		cc, err := condition.NewConditionChecker(*a.Condition)
+		if err != nil {
+			return nil, err
+		}
+
+		pending[i] = cc
+	}
+
+	return &Scenario{
+		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
+			return c.Str("module", "scenario")
+		}),
+		actions: actions,
+		pending: pending,
+	}, nil
+}
+
+// Start schedules the timed actions and starts to check the conditions.
+func (sn *Scenario) Start(nodes *Nodes) error {
+	sn.Lock()
+	defer sn.Unlock()
+
+	if sn.stop != nil {
+		return common.DaemonAleadyStartedError
+	}
+
+	sn.nodes = nodes
+	sn.stop = make(chan struct{})
+
+	for i, a := range sn.actions {
+		if a.At == nil {
+			continue
+		}
+
+		go func(i int, at time.Duration, stop chan struct{}) {
+			select {
+			case <-stop:
+			case <-common.After(at):
+				sn.run(i)
+			}
+		}(i, *a.At, sn.stop)
+	}
+
+	return nil
+}
+
+func (sn *Scenario) Stop() error {
+	sn.Lock()
+	defer sn.Unlock()
+
+	if sn.stop == nil {
+		return nil
+	}
+
+	close(sn.stop)
+	sn.stop = nil
+
+	return nil
+}
+
+func (sn *Scenario) Write(b []byte) (int, error) {
+	sn.Lock()
+	if sn.stop == nil || len(sn.pending) < 1 {
+		sn.Unlock()
+		return len(b), nil
+	}
+
+	li, err := condition.NewLogItem(b)
+	if err != nil {
+		sn.Unlock()
+		return len(b), nil
+	}
+
+	var matched []int
+	for i, cc := range sn.pending {
+		if cc.Check(li) {
+			matched = append(matched, i)
+			delete(sn.pending, i)
+		}
+	}
+	sn.Unlock()
+
+	// NOTE the action also writes log, so it runs in another goroutine
+	for _, i := range matched {
+		go sn.run(i)
+	}
+
+	return len(b), nil
+}
+
+func (sn *Scenario) run(i int) {
+	sn.RLock()
+	nodes := sn.nodes
+	stopped := sn.stop == nil
+	sn.RUnlock()
+
+	if stopped {
+		return
+	}
+
+	a := sn.actions[i]
+	err := sn.execute(nodes, a)
+
+	sn.Log().Info().Err(err).Int("index", i).Object("action", a).Msg("scenario action executed")
+}
+
+func (sn *Scenario) execute(nodes *Nodes, a *ScenarioActionConfig) error {
+	switch a.Action {
+	case "stop", "start", "restart", "ballot-maker", "skew-clock":
+		no := nodes.Node(*a.Node)
+		if no == nil {
+			return xerrors.Errorf("unknown node; node=%q", *a.Node)
+		}
+
+		switch a.Action {
+		case "stop":
+			return no.Stop()
+		case "start":
+			return sn.start(no, a.wipe())
+		case "restart":
+			if err := no.Stop(); err != nil {
+				return err
+			}
+
+			if a.Duration != nil {
+				<-common.After(*a.Duration)
+			}
+
+			return sn.start(no, a.wipe())
+		case "skew-clock":
+			no.SetClockSkew(*a.Duration)
+
+			return nil
+		default:
+			return no.SetBallotMaker(a.BallotMaker)
+		}
+	case "open-partition":
+		return nodes.Faults().Open(*a.Partition)
+	case "heal-partition":
+		return nodes.Faults().Heal(*a.Partition)
+	default:
+		return xerrors.Errorf("unknown scenario action found: %v", a.Action)
+	}
+}
+
+func (sn *Scenario) start(no *Node, wipe bool) error {
+	if wipe {
+		if err := no.Wipe(); err != nil {
+			return err
+		}
+	}
+
+	return no.Start()
+}
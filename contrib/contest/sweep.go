@@ -0,0 +1,620 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/contrib/contest/condition"
+	contest_module "github.com/spikeekips/mitum/contrib/contest/module"
+)
+
+const (
+	sweepJSONFile = "sweep.json"
+	sweepTextFile = "sweep.txt"
+)
+
+// sweepCase is the one run of sweep.
+type sweepCase struct {
+	index         int
+	values        map[string]string
+	seed          int64
+	numberOfNodes uint
+	modifiers     []ConfigModifier
+}
+
+// sweepParameter is the parameter of sweep; set applies the value to the
+// case.
+type sweepParameter struct {
+	name   string
+	values []string
+	set    func(*sweepCase, string) error
+}
+
+func newSweepParameter(
+	name string,
+	values []string,
+	ranged bool,
+	set func(*sweepCase, string) error,
+) (*sweepParameter, error) {
+	var vs []string
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if len(v) < 1 {
+			continue
+		}
+
+		if !ranged || !strings.Contains(v, "..") {
+			vs = append(vs, v)
+			continue
+		}
+
+		expanded, err := expandSweepRange(v)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid range of %q: %w", name, err)
+		}
+		vs = append(vs, expanded...)
+	}
+
+	// NOTE the values are checked before run
+	for _, v := range vs {
+		if err := set(&sweepCase{}, v); err != nil {
+			return nil, xerrors.Errorf("invalid value of %q; value=%q: %w", name, v, err)
+		}
+	}
+
+	return &sweepParameter{name: name, values: vs, set: set}, nil
+}
+
+// expandSweepRange expands the integer range, `a..b`, including b.
+func expandSweepRange(s string) ([]string, error) {
+	l := strings.SplitN(s, "..", 2)
+
+	from, err := strconv.ParseInt(l[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := strconv.ParseInt(l[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	if from > to {
+		return nil, xerrors.Errorf("start should not be greater than end; range=%q", s)
+	}
+
+	var vs []string
+	for i := from; i <= to; i++ {
+		vs = append(vs, strconv.FormatInt(i, 10))
+	}
+
+	return vs, nil
+}
+
+func setSweepNodes(c *sweepCase, s string) error {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return err
+	} else if n < 1 {
+		return xerrors.Errorf("number of nodes should be greater than 0")
+	}
+
+	c.numberOfNodes = uint(n)
+
+	return nil
+}
+
+func setSweepSeed(c *sweepCase, s string) error {
+	seed, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	c.seed = seed
+
+	return nil
+}
+
+func setSweepThreshold(c *sweepCase, s string) error {
+	th, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	} else if th <= 0 || th > 100 {
+		return xerrors.Errorf("threshold should be in (0, 100]")
+	}
+
+	c.modifiers = append(c.modifiers, modifyPolicy(func(pc *PolicyConfig) {
+		pc.Threshold = &th
+	}))
+
+	return nil
+}
+
+func setSweepPolicyTimeout(f func(*PolicyConfig, *time.Duration)) func(*sweepCase, string) error {
+	return func(c *sweepCase, s string) error {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		} else if d < time.Nanosecond {
+			return xerrors.Errorf("timeout should be greater than 0")
+		}
+
+		c.modifiers = append(c.modifiers, modifyPolicy(func(pc *PolicyConfig) {
+			f(pc, &d)
+		}))
+
+		return nil
+	}
+}
+
+func setSweepFault(f func(*LinkFaultConfig, *float64)) func(*sweepCase, string) error {
+	return func(c *sweepCase, s string) error {
+		p, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		} else if p < 0 || p > 1 {
+			return xerrors.Errorf("fault rate should be in [0, 1]")
+		}
+
+		c.modifiers = append(c.modifiers, modifyNetworkDefault(func(lc *LinkFaultConfig) {
+			f(lc, &p)
+		}))
+
+		return nil
+	}
+}
+
+// modifyPolicy applies f to the policy of global and the nodes of config, so
+// the nodes, which have their own policy, also follow the sweep.
+func modifyPolicy(f func(*PolicyConfig)) ConfigModifier {
+	return func(config *Config) error {
+		if config.Global == nil {
+			config.Global = defaultNodeConfig()
+		}
+
+		if config.Global.Policy == nil {
+			config.Global.Policy = defaultPolicyConfig()
+		}
+
+		f(config.Global.Policy)
+
+		for name, n := range config.Nodes {
+			if n == nil {
+				n = defaultNodeConfig()
+				config.Nodes[name] = n
+			}
+
+			if n.Policy != nil {
+				f(n.Policy)
+			}
+		}
+
+		return nil
+	}
+}
+
+// modifyNetworkDefault applies f to the default link fault; the faults of
+// nodes and links, which are set in config, are kept.
+func modifyNetworkDefault(f func(*LinkFaultConfig)) ConfigModifier {
+	return func(config *Config) error {
+		if config.Network == nil {
+			config.Network = &NetworkConfig{}
+		}
+
+		if config.Network.Default == nil {
+			config.Network.Default = &LinkFaultConfig{}
+		}
+
+		f(config.Network.Default)
+
+		return nil
+	}
+}
+
+// sweepCases makes the cases from the values of parameters. Without sample,
+// all the combinations of values are made; with sample, the values of each
+// case are picked randomly. If the seeds are not given by the parameter, the
+// seed of case is random.
+func sweepCases(params []*sweepParameter, sample uint, r *rand.Rand) ([]*sweepCase, error) {
+	var picks [][]int
+	if sample > 0 {
+		for i := uint(0); i < sample; i++ {
+			pick := make([]int, len(params))
+			for j, p := range params {
+				pick[j] = r.Intn(len(p.values))
+			}
+			picks = append(picks, pick)
+		}
+	} else {
+		pick := make([]int, len(params))
+		for {
+			picks = append(picks, append([]int(nil), pick...))
+
+			// NOTE the last parameter changes first
+			j := len(params) - 1
+			for ; j >= 0; j-- {
+				pick[j]++
+				if pick[j] < len(params[j].values) {
+					break
+				}
+				pick[j] = 0
+			}
+
+			if j < 0 {
+				break
+			}
+		}
+	}
+
+	cases := make([]*sweepCase, len(picks))
+	for i, pick := range picks {
+		c := &sweepCase{index: i, values: map[string]string{}, seed: r.Int63()}
+		for j, p := range params {
+			v := p.values[pick[j]]
+			if err := p.set(c, v); err != nil {
+				return nil, err
+			}
+			c.values[p.name] = v
+		}
+
+		cases[i] = c
+	}
+
+	return cases, nil
+}
+
+// SweepResult is the result of case.
+// * result: `ok`, `violated` by the conditions or invariants, or `error`
+type SweepResult struct {
+	Index      int               `json:"index"`
+	Parameters map[string]string `json:"parameters"`
+	Seed       int64             `json:"seed"`
+	Result     string            `json:"result"`
+	Error      string            `json:"error,omitempty"`
+	Report     *ReportResult     `json:"report,omitempty"`
+}
+
+// Sweep runs the cases of the config one by one in process.
+type Sweep struct {
+	sync.RWMutex
+	config   string
+	params   []*sweepParameter
+	cases    []*sweepCase
+	duration time.Duration
+	dir      string
+	results  []SweepResult
+}
+
+func NewSweep(config string, params []*sweepParameter, cases []*sweepCase, duration time.Duration, dir string) *Sweep {
+	return &Sweep{
+		config:   config,
+		params:   params,
+		cases:    cases,
+		duration: duration,
+		dir:      dir,
+	}
+}
+
+// Run runs all the cases; it returns false if any case is violated or failed.
+func (sw *Sweep) Run() bool {
+	ok := true
+	for _, c := range sw.cases {
+		l := log.Info().Int("case", c.index).Int64("seed", c.seed)
+		for k, v := range c.values {
+			l = l.Str(k, v)
+		}
+		l.Msg("case started")
+
+		result := SweepResult{Index: c.index, Parameters: c.values, Seed: c.seed}
+
+		rr, err := sw.runCase(c)
+		switch {
+		case err != nil:
+			result.Result = "error"
+			result.Error = err.Error()
+		case rr.ExitCode != 0:
+			result.Result = "violated"
+		default:
+			result.Result = "ok"
+		}
+
+		if err == nil {
+			result.Report = &rr
+		}
+
+		if result.Result != "ok" {
+			ok = false
+		}
+
+		log.Info().Int("case", c.index).Str("result", result.Result).Str("error", result.Error).Msg("case finished")
+
+		sw.Lock()
+		sw.results = append(sw.results, result)
+		sw.Unlock()
+	}
+
+	return ok
+}
+
+func (sw *Sweep) Results() []SweepResult {
+	sw.RLock()
+	defer sw.RUnlock()
+
+	return append([]SweepResult(nil), sw.results...)
+}
+
+func (sw *Sweep) runCase(c *sweepCase) (ReportResult, error) { // nolint
+	// NOTE the seed should be set before config is loaded
+	contest_module.SetSeed(c.seed)
+
+	config, err := LoadConfig(sw.config, c.numberOfNodes, c.modifiers...)
+	if err != nil {
+		return ReportResult{}, err
+	}
+
+	// NOTE the globals changed by the case are restored, so the next case
+	// starts from the same state
+	previousLog := log
+	previousTimestampFunc := zerolog.TimestampFunc
+	restoreHash := config.Hash.SetDefaultAlgorithms()
+	defer func() {
+		log = previousLog
+		zerolog.TimestampFunc = previousTimestampFunc
+		common.SetClock(nil)
+		restoreHash()
+	}()
+
+	nodeList := getAllNodesFromConfig(config, c.seed)
+
+	var dir string
+	var out io.Writer = ioutil.Discard
+	if len(sw.dir) > 0 {
+		dir = filepath.Join(sw.dir, fmt.Sprintf("%04d", c.index))
+		if err := os.MkdirAll(dir, 0755); err != nil { // nolint
+			return ReportResult{}, err
+		}
+
+		f, err := os.Create(filepath.Join(dir, "all.log"))
+		if err != nil {
+			return ReportResult{}, err
+		}
+		defer f.Close()
+
+		out = f
+	}
+
+	caseLog := zerolog.New(out).With().Timestamp().Logger().Level(flagLogLevel.lvl)
+
+	invariants, err := NewInvariants(config, nodeList)
+	if err != nil {
+		return ReportResult{}, err
+	}
+	_ = invariants.SetLogger(caseLog)
+
+	aliases := make([]string, len(nodeList))
+	for i, n := range nodeList {
+		aliases[i] = n.Alias()
+	}
+
+	report := NewReport(c.seed, sw.config, aliases).SetInvariants(invariants)
+
+	logWriters := []io.Writer{out, invariants, report}
+
+	// NOTE buffered; the case can be finished before the conditions are
+	// satisfied
+	satisfiedChan := make(chan bool, 1)
+
+	var conditionChecker *condition.MultipleConditionChecker
+	var lw *condition.LogWatcher
+	if config.Condition != nil {
+		conditionChecker = condition.NewMultipleConditionCheckerFromConditions(prepareConditions(config, nodeList), 1)
+		_ = report.SetConditions(conditionChecker)
+
+		lw = condition.NewLogWatcher(conditionChecker, satisfiedChan)
+		_ = lw.SetLogger(caseLog)
+
+		logWriters = append(logWriters, lw)
+	}
+
+	var scenario *Scenario
+	if len(config.Scenario) > 0 {
+		if scenario, err = NewScenario(config.Scenario); err != nil {
+			return ReportResult{}, err
+		}
+
+		logWriters = append(logWriters, scenario)
+	}
+
+	// NOTE the nodes log with the global log
+	log = caseLog.Output(io.MultiWriter(logWriters...))
+
+	if scenario != nil {
+		scenario.SetLogger(log)
+	}
+
+	var clock *common.SimulatedClock
+	if config.Clock.IsSimulated() {
+		clock = common.NewSimulatedClock(time.Now(), *config.Clock.Idle)
+		clock.SetLogger(log)
+
+		common.SetClock(clock)
+		zerolog.TimestampFunc = func() time.Time {
+			return common.Now().Time
+		}
+
+		if err := clock.Start(); err != nil {
+			return ReportResult{}, err
+		}
+	}
+
+	nodes, err := NewNodes(config, nodeList, c.seed)
+	if err != nil {
+		if clock != nil {
+			_ = clock.Stop()
+		}
+
+		return ReportResult{}, err
+	}
+
+	if lw != nil {
+		_ = lw.Start()
+	}
+
+	if err = invariants.Start(); err == nil {
+		if err = nodes.Start(); err == nil && scenario != nil {
+			err = scenario.Start(nodes)
+		}
+	}
+
+	if err == nil {
+		select {
+		case <-time.After(sw.duration):
+		case <-satisfiedChan:
+		}
+	}
+
+	// NOTE the clock is stopped at first like `run`
+	if clock != nil {
+		_ = clock.Stop()
+	}
+
+	_ = nodes.Stop()
+
+	if scenario != nil {
+		_ = scenario.Stop()
+	}
+
+	if lw != nil {
+		_ = lw.Stop()
+	}
+
+	_ = invariants.Stop()
+
+	if err != nil {
+		return ReportResult{}, err
+	}
+
+	var exitCode int
+	if len(invariants.Violations()) > 0 {
+		exitCode = 1
+	} else if conditionChecker != nil && !conditionChecker.AllSatisfied() {
+		exitCode = 1
+	}
+
+	if len(dir) > 0 {
+		if err := report.WriteFiles(dir, exitCode); err != nil {
+			return ReportResult{}, err
+		}
+	}
+
+	return report.Result(exitCode), nil
+}
+
+// WriteFiles writes the results to the directory as `sweep.json` and
+// `sweep.txt`.
+func (sw *Sweep) WriteFiles(dir string) error {
+	b, err := json.MarshalIndent(sw.Results(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, sweepJSONFile), b, 0644); err != nil { // nolint
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(dir, sweepTextFile))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return sw.WriteTable(f)
+}
+
+// WriteTable writes the results as table; the height is the highest height
+// of nodes.
+func (sw *Sweep) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	header := []string{"case"}
+	for _, p := range sw.params {
+		if p.name == "seed" {
+			continue
+		}
+		header = append(header, p.name)
+	}
+	header = append(header, "seed", "height", "conditions", "violations", "result")
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	counts := map[string]int{}
+	results := sw.Results()
+	for _, r := range results {
+		counts[r.Result]++
+
+		row := []string{strconv.Itoa(r.Index)}
+		for _, p := range sw.params {
+			if p.name == "seed" {
+				continue
+			}
+			row = append(row, r.Parameters[p.name])
+		}
+
+		height, conditions, violations := "-", "-", "-"
+		if rr := r.Report; rr != nil {
+			var h uint64
+			for _, rn := range rr.Nodes {
+				if rn.Height > h {
+					h = rn.Height
+				}
+			}
+			height = strconv.FormatUint(h, 10)
+
+			if c := rr.Conditions; c != nil {
+				var n int
+				for _, ok := range c.Queries {
+					if ok {
+						n++
+					}
+				}
+				conditions = fmt.Sprintf("%d/%d", n, len(c.Queries))
+			}
+
+			if iv := rr.Invariants; iv != nil {
+				violations = strconv.Itoa(len(iv.Violations))
+			}
+		}
+
+		result := r.Result
+		if len(r.Error) > 0 {
+			result += ": " + r.Error
+		}
+
+		row = append(row, strconv.FormatInt(r.Seed, 10), height, conditions, violations, result)
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(
+		w, "cases: %d/%d, ok=%d violated=%d error=%d\n",
+		len(results), len(sw.cases), counts["ok"], counts["violated"], counts["error"],
+	)
+
+	return err
+}
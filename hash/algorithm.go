@@ -0,0 +1,268 @@
+package hash
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// Algorithm is the hash algorithm. The zero value, DoubleSHA256, is the
+// algorithm of the hash, which is created before the algorithm is recorded.
+type Algorithm uint
+
+const (
+	DoubleSHA256 Algorithm = iota
+	SHA3256
+	Blake2b256
+)
+
+type algorithmInfo struct {
+	name string
+	sum  func([]byte) []byte
+}
+
+var (
+	algorithmsLock sync.RWMutex
+	algorithms     = map[Algorithm]algorithmInfo{
+		DoubleSHA256: {name: "double-sha256", sum: sumDoubleSHA256},
+		SHA3256:      {name: "sha3-256", sum: sumSHA3256},
+		Blake2b256:   {name: "blake2b-256", sum: sumBlake2b256},
+	}
+)
+
+func sumDoubleSHA256(b []byte) []byte {
+	f := sha256.Sum256(b)
+	s := sha256.Sum256(f[:])
+
+	return s[:]
+}
+
+func sumSHA3256(b []byte) []byte {
+	s := sha3.Sum256(b)
+
+	return s[:]
+}
+
+func sumBlake2b256(b []byte) []byte {
+	s := blake2b.Sum256(b)
+
+	return s[:]
+}
+
+// RegisterAlgorithm registers the new hash algorithm.
+func RegisterAlgorithm(a Algorithm, name string, sum func([]byte) []byte) error {
+	algorithmsLock.Lock()
+	defer algorithmsLock.Unlock()
+
+	if _, found := algorithms[a]; found {
+		return HashAlgorithmAlreadyRegisteredError.Newf("algorithm=%d", a)
+	}
+
+	for _, info := range algorithms {
+		if info.name == name {
+			return HashAlgorithmAlreadyRegisteredError.Newf("name=%q", name)
+		}
+	}
+
+	algorithms[a] = algorithmInfo{name: name, sum: sum}
+
+	return nil
+}
+
+// ParseAlgorithm finds the registered Algorithm by name.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	algorithmsLock.RLock()
+	defer algorithmsLock.RUnlock()
+
+	for a, info := range algorithms {
+		if info.name == name {
+			return a, nil
+		}
+	}
+
+	return 0, UnknownHashAlgorithmError.Newf("name=%q", name)
+}
+
+func (a Algorithm) info() (algorithmInfo, bool) {
+	algorithmsLock.RLock()
+	defer algorithmsLock.RUnlock()
+
+	info, found := algorithms[a]
+
+	return info, found
+}
+
+func (a Algorithm) IsValid() error {
+	if _, found := a.info(); !found {
+		return UnknownHashAlgorithmError.Newf("algorithm=%d", a)
+	}
+
+	return nil
+}
+
+func (a Algorithm) String() string {
+	info, found := a.info()
+	if !found {
+		return ""
+	}
+
+	return info.name
+}
+
+func (a Algorithm) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// Sum returns the digest of b.
+func (a Algorithm) Sum(b []byte) ([]byte, error) {
+	info, found := a.info()
+	if !found {
+		return nil, UnknownHashAlgorithmError.Newf("algorithm=%d", a)
+	}
+
+	return info.sum(b), nil
+}
+
+// Algorithms selects the Algorithm by hint; if the hint is not set, the default
+// Algorithm is selected.
+type Algorithms struct {
+	sync.RWMutex
+	def   Algorithm
+	hints map[string]Algorithm
+}
+
+func NewAlgorithms(def Algorithm) *Algorithms {
+	return &Algorithms{def: def, hints: map[string]Algorithm{}}
+}
+
+func (as *Algorithms) Default() Algorithm {
+	as.RLock()
+	defer as.RUnlock()
+
+	return as.def
+}
+
+func (as *Algorithms) SetDefault(a Algorithm) error {
+	if err := a.IsValid(); err != nil {
+		return err
+	}
+
+	as.Lock()
+	defer as.Unlock()
+
+	as.def = a
+
+	return nil
+}
+
+func (as *Algorithms) Set(hint string, a Algorithm) error {
+	if err := a.IsValid(); err != nil {
+		return err
+	}
+
+	as.Lock()
+	defer as.Unlock()
+
+	as.hints[hint] = a
+
+	return nil
+}
+
+// Reset removes the hints and sets the default Algorithm.
+func (as *Algorithms) Reset(def Algorithm) {
+	as.Lock()
+	defer as.Unlock()
+
+	as.def = def
+	as.hints = map[string]Algorithm{}
+}
+
+// Copy returns the new Algorithms, which has the same default and hints.
+func (as *Algorithms) Copy() *Algorithms {
+	as.RLock()
+	defer as.RUnlock()
+
+	hints := map[string]Algorithm{}
+	for hint, a := range as.hints {
+		hints[hint] = a
+	}
+
+	return &Algorithms{def: as.def, hints: hints}
+}
+
+// Load replaces the default and hints with the ones of the given Algorithms.
+func (as *Algorithms) Load(o *Algorithms) {
+	c := o.Copy()
+
+	as.Lock()
+	defer as.Unlock()
+
+	as.def = c.def
+	as.hints = c.hints
+}
+
+func (as *Algorithms) Algorithm(hint string) Algorithm {
+	as.RLock()
+	defer as.RUnlock()
+
+	if a, found := as.hints[hint]; found {
+		return a
+	}
+
+	return as.def
+}
+
+func (as *Algorithms) New(hint string, b []byte) (Hash, error) {
+	return NewHashByAlgorithm(as.Algorithm(hint), hint, b)
+}
+
+// Check checks the algorithm of the hash is the Algorithm of it's hint; the
+// algorithm recorded in the hash is chosen by the maker of hash, so the hash
+// from the others should be checked with the Algorithms of the network.
+func (as *Algorithms) Check(h Hash) error {
+	if a := as.Algorithm(h.Hint()); h.Algorithm() != a {
+		return HashVerificationFailedError.Newf(
+			"unexpected algorithm; hint=%q algorithm=%s expected=%s", h.Hint(), h.Algorithm(), a,
+		)
+	}
+
+	return nil
+}
+
+// Verify checks the algorithm of the hash and the hash is made from b.
+func (as *Algorithms) Verify(h Hash, b []byte) error {
+	if err := as.Check(h); err != nil {
+		return err
+	}
+
+	return h.Verify(b)
+}
+
+// DefaultAlgorithms is the Algorithms, which makes the new hash by
+// NewHashByHint; to check the hashes from the others, the Algorithms of the
+// network should be used.
+var DefaultAlgorithms = NewAlgorithms(DoubleSHA256)
+
+// NewHashByHint creates Hash by the Algorithm of the hint in
+// DefaultAlgorithms.
+func NewHashByHint(hint string, b []byte) (Hash, error) {
+	return DefaultAlgorithms.New(hint, b)
+}
+
+func NewHashByAlgorithm(a Algorithm, hint string, b []byte) (Hash, error) {
+	sum, err := a.Sum(b)
+	if err != nil {
+		return Hash{}, err
+	}
+
+	h, err := NewHash(hint, sum)
+	if err != nil {
+		return Hash{}, err
+	}
+	h.algorithm = a
+
+	return h, nil
+}
@@ -0,0 +1,159 @@
+package hash
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type testAlgorithm struct {
+	suite.Suite
+}
+
+func (t *testAlgorithm) TestDoubleSHACompatible() {
+	h0, err := NewDoubleSHAHash("block", []byte("show me"))
+	t.NoError(err)
+	t.Equal(DoubleSHA256, h0.Algorithm())
+
+	// NOTE the encoded form of DoubleSHA256 hash is same with the hash without
+	// algorithm
+	b, err := rlp.EncodeToBytes(h0)
+	t.NoError(err)
+
+	old, err := rlp.EncodeToBytes(struct {
+		Hint string
+		Body []byte
+	}{h0.Hint(), h0.Body()})
+	t.NoError(err)
+	t.Equal(old, b)
+
+	var h1 Hash
+	t.NoError(rlp.DecodeBytes(old, &h1))
+	t.True(h0.Equal(h1))
+	t.Equal(DoubleSHA256, h1.Algorithm())
+}
+
+func (t *testAlgorithm) TestAlgorithms() {
+	input := []byte("show me")
+
+	var bodies [][]byte
+	for _, a := range []Algorithm{DoubleSHA256, SHA3256, Blake2b256} {
+		h, err := NewHashByAlgorithm(a, "block", input)
+		t.NoError(err)
+		t.Equal(a, h.Algorithm())
+		t.Equal(32, len(h.Body()))
+		t.NoError(h.Verify(input))
+
+		for _, b := range bodies {
+			t.NotEqual(b, h.Body())
+		}
+		bodies = append(bodies, h.Body())
+
+		b, err := rlp.EncodeToBytes(h)
+		t.NoError(err)
+
+		var uh Hash
+		t.NoError(rlp.DecodeBytes(b, &uh))
+		t.True(h.Equal(uh))
+		t.Equal(a, uh.Algorithm())
+		t.NoError(uh.Verify(input))
+	}
+}
+
+func (t *testAlgorithm) TestVerify() {
+	h, err := NewHashByAlgorithm(Blake2b256, "block", []byte("show me"))
+	t.NoError(err)
+
+	err = h.Verify([]byte("killme"))
+	t.True(xerrors.Is(err, HashVerificationFailedError))
+}
+
+func (t *testAlgorithm) TestParseAlgorithm() {
+	for _, a := range []Algorithm{DoubleSHA256, SHA3256, Blake2b256} {
+		pa, err := ParseAlgorithm(a.String())
+		t.NoError(err)
+		t.Equal(a, pa)
+	}
+
+	_, err := ParseAlgorithm("md5")
+	t.True(xerrors.Is(err, UnknownHashAlgorithmError))
+
+	t.True(xerrors.Is(Algorithm(100).IsValid(), UnknownHashAlgorithmError))
+}
+
+func (t *testAlgorithm) TestSelectByHint() {
+	as := NewAlgorithms(SHA3256)
+	t.NoError(as.Set("ballot", Blake2b256))
+
+	t.Equal(SHA3256, as.Algorithm("block"))
+	t.Equal(Blake2b256, as.Algorithm("ballot"))
+
+	h, err := as.New("ballot", []byte("show me"))
+	t.NoError(err)
+	t.Equal(Blake2b256, h.Algorithm())
+
+	t.True(xerrors.Is(as.Set("block", Algorithm(100)), UnknownHashAlgorithmError))
+
+	as.Reset(DoubleSHA256)
+	t.Equal(DoubleSHA256, as.Algorithm("ballot"))
+}
+
+func (t *testAlgorithm) TestCheckByHint() {
+	as := NewAlgorithms(SHA3256)
+	t.NoError(as.Set("ballot", Blake2b256))
+
+	h, err := as.New("ballot", []byte("show me"))
+	t.NoError(err)
+	t.NoError(as.Verify(h, []byte("show me")))
+
+	// NOTE valid hash by it's own algorithm, but not the algorithm of hint
+	other, err := NewHashByAlgorithm(DoubleSHA256, "ballot", []byte("show me"))
+	t.NoError(err)
+	t.NoError(other.Verify([]byte("show me")))
+
+	err = as.Verify(other, []byte("show me"))
+	t.True(xerrors.Is(err, HashVerificationFailedError))
+	t.Contains(err.Error(), "unexpected algorithm")
+}
+
+func (t *testAlgorithm) TestCopyAndLoad() {
+	as := NewAlgorithms(SHA3256)
+	t.NoError(as.Set("ballot", Blake2b256))
+
+	c := as.Copy()
+	t.NoError(as.Set("ballot", DoubleSHA256))
+	t.Equal(Blake2b256, c.Algorithm("ballot"))
+
+	other := NewAlgorithms(DoubleSHA256)
+	other.Load(c)
+	t.Equal(SHA3256, other.Default())
+	t.Equal(Blake2b256, other.Algorithm("ballot"))
+	t.Equal(SHA3256, other.Algorithm("proposal"))
+}
+
+func (t *testAlgorithm) TestRegister() {
+	err := RegisterAlgorithm(SHA3256, "findme", sumSHA3256)
+	t.True(xerrors.Is(err, HashAlgorithmAlreadyRegisteredError))
+
+	err = RegisterAlgorithm(Algorithm(200), SHA3256.String(), sumSHA3256)
+	t.True(xerrors.Is(err, HashAlgorithmAlreadyRegisteredError))
+}
+
+func (t *testAlgorithm) TestDecodeUnknownAlgorithm() {
+	b, err := rlp.EncodeToBytes(struct {
+		Hint      string
+		Body      []byte
+		Algorithm uint
+	}{"block", []byte("show me"), 100})
+	t.NoError(err)
+
+	var h Hash
+	err = rlp.DecodeBytes(b, &h)
+	t.True(xerrors.Is(err, InvalidHashInputError))
+}
+
+func TestAlgorithm(t *testing.T) {
+	suite.Run(t, new(testAlgorithm))
+}
@@ -6,10 +6,18 @@ const (
 	HashFailedErrorCode common.ErrorCode = iota + 1
 	EmptyHashErrorCode
 	InvalidHashInputErrorCode
+	UnknownHashAlgorithmErrorCode
+	HashAlgorithmAlreadyRegisteredErrorCode
+	HashVerificationFailedErrorCode
 )
 
 var (
-	HashFailedError       = common.NewError("hash", HashFailedErrorCode, "failed to make hash")
-	EmptyHashError        = common.NewError("hash", EmptyHashErrorCode, "hash is empty")
-	InvalidHashInputError = common.NewError("hash", InvalidHashInputErrorCode, "invalid hash input value")
+	HashFailedError                     = common.NewError("hash", HashFailedErrorCode, "failed to make hash")
+	EmptyHashError                      = common.NewError("hash", EmptyHashErrorCode, "hash is empty")
+	InvalidHashInputError               = common.NewError("hash", InvalidHashInputErrorCode, "invalid hash input value")
+	UnknownHashAlgorithmError           = common.NewError("hash", UnknownHashAlgorithmErrorCode, "unknown hash algorithm")
+	HashAlgorithmAlreadyRegisteredError = common.NewError(
+		"hash", HashAlgorithmAlreadyRegisteredErrorCode, "hash algorithm already registered",
+	)
+	HashVerificationFailedError = common.NewError("hash", HashVerificationFailedErrorCode, "hash does not match")
 )
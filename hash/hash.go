@@ -4,21 +4,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/rs/zerolog"
 )
 
+// MaxBodyLength is the maximum length of hash body.
+const MaxBodyLength int = 100
+
 var (
-	zeroBody [100]byte = [100]byte{}
-	nilBody  [5]byte   = [5]byte{186, 47, 126, 25, 238}
+	nilBody [5]byte = [5]byte{186, 47, 126, 25, 238}
 )
 
 type Hash struct {
-	hint   string
-	body   [100]byte // NOTE the fixed length array can be possible to make Hash to be comparable
-	length int
+	hint      string
+	body      string // NOTE string keeps Hash comparable and holds only the real digest
+	algorithm Algorithm
 }
 
 func NewHash(hint string, body []byte) (Hash, error) {
@@ -26,74 +29,96 @@ func NewHash(hint string, body []byte) (Hash, error) {
 		return Hash{}, HashFailedError.Newf("zero hint length")
 	}
 
-	var b [100]byte
-	copy(b[:], body)
+	if len(body) > MaxBodyLength {
+		return Hash{}, HashFailedError.Newf("too long body; length=%d", len(body))
+	}
 
 	return Hash{
-		hint:   hint,
-		body:   b,
-		length: len(body),
+		hint: hint,
+		body: string(body),
 	}, nil
 }
 
 func NilHash(hint string) Hash {
-	h, _ := NewHash(hint, nilBody[:]) // nolint
-	return h
+	if len(hint) < 1 {
+		return Hash{}
+	}
+
+	return Hash{hint: hint, body: string(nilBody[:])}
 }
 
+// EncodeRLP encodes Hash; the algorithm is recorded only when it is not
+// DoubleSHA256, so the hashes of the existing data keep their form.
 func (h Hash) EncodeRLP(w io.Writer) error {
+	if h.algorithm == DoubleSHA256 {
+		return rlp.Encode(w, struct {
+			Hint string
+			Body string
+		}{
+			h.hint,
+			h.body,
+		})
+	}
+
 	return rlp.Encode(w, struct {
-		Hint string
-		Body []byte
+		Hint      string
+		Body      string
+		Algorithm uint
 	}{
 		h.hint,
-		h.Body(),
+		h.body,
+		uint(h.algorithm),
 	})
 }
 
 func (h *Hash) DecodeRLP(s *rlp.Stream) error {
 	var d struct {
 		Hint string
-		Body []byte
+		Body string
+		Rest []rlp.RawValue `rlp:"tail"`
 	}
 	if err := s.Decode(&d); err != nil {
 		return InvalidHashInputError.New(err)
 	}
 
-	h.hint = d.Hint
-	var b [100]byte
-	copy(b[:], d.Body)
+	var algorithm Algorithm
+	switch len(d.Rest) {
+	case 0:
+	case 1:
+		var a uint
+		if err := rlp.DecodeBytes(d.Rest[0], &a); err != nil {
+			return InvalidHashInputError.New(err)
+		}
+		algorithm = Algorithm(a)
 
-	h.body = b
-	h.length = len(d.Body)
+		if err := algorithm.IsValid(); err != nil {
+			return InvalidHashInputError.New(err)
+		}
+	default:
+		return InvalidHashInputError.Newf("too many fields; fields=%d", len(d.Rest)+2)
+	}
+
+	if len(d.Body) > MaxBodyLength {
+		return InvalidHashInputError.Newf("too long body; length=%d", len(d.Body))
+	}
+
+	h.hint = d.Hint
+	h.body = d.Body
+	h.algorithm = algorithm
 
 	return nil
 }
 
 func (h Hash) Empty() bool {
-	if len(h.hint) > 0 || h.body != zeroBody {
-		return false
-	}
-
-	return true
+	return len(h.hint) < 1 && len(h.body) < 1
 }
 
 func (h Hash) IsNil() bool {
-	if len(h.hint) < 1 || h.length != len(nilBody) {
-		return false
-	}
-
-	for i, a := range nilBody {
-		if a != h.body[i] {
-			return false
-		}
-	}
-
-	return true
+	return len(h.hint) > 0 && h.body == string(nilBody[:])
 }
 
 func (h Hash) IsValid() error {
-	if h.length < 1 {
+	if len(h.body) < 1 {
 		return EmptyHashError.Newf("empty body")
 	}
 
@@ -104,21 +129,10 @@ func (h Hash) IsValid() error {
 	return nil
 }
 
+// Equal compares the hint, body and algorithm like the map key of Hash does;
+// the same body by the different algorithms is not the same Hash.
 func (h Hash) Equal(a Hash) bool {
-	if h.hint != a.hint {
-		return false
-	}
-	if h.body != a.body {
-		return false
-	}
-
-	for i, b := range h.Body() {
-		if b != a.body[i] {
-			return false
-		}
-	}
-
-	return true
+	return h.hint == a.hint && h.body == a.body && h.algorithm == a.algorithm
 }
 
 func (h Hash) MarshalJSON() ([]byte, error) {
@@ -143,18 +157,80 @@ func (h Hash) Hint() string {
 	return h.hint
 }
 
+// Algorithm returns the hash algorithm, which made the hash.
+func (h Hash) Algorithm() Algorithm {
+	return h.algorithm
+}
+
+// Verify checks the hash is made from b by it's algorithm.
+func (h Hash) Verify(b []byte) error {
+	n, err := NewHashByAlgorithm(h.algorithm, h.hint, b)
+	if err != nil {
+		return err
+	}
+
+	if !h.Equal(n) {
+		return HashVerificationFailedError.Newf("algorithm=%s", h.algorithm)
+	}
+
+	return nil
+}
+
 func (h Hash) Body() []byte {
-	return h.body[:h.length]
+	return []byte(h.body)
 }
 
 func (h Hash) Bytes() []byte {
-	var n []byte
-	n = append(n, []byte(h.hint)...)
-	n = append(n, h.body[:h.length]...)
+	n := make([]byte, len(h.hint)+len(h.body))
+	copy(n, h.hint)
+	copy(n[len(h.hint):], h.body)
 
 	return n
 }
 
+// String returns `<hint>:<base58 encoded body>`; like EncodeRLP, the algorithm
+// is appended, `:<algorithm>`, only when it is not DoubleSHA256, so the parsed
+// Hash can be verified again.
 func (h Hash) String() string {
-	return fmt.Sprintf("%s:%s", h.hint, base58.Encode(h.Body()))
+	if h.algorithm == DoubleSHA256 {
+		return fmt.Sprintf("%s:%s", h.hint, base58.Encode(h.Body()))
+	}
+
+	return fmt.Sprintf("%s:%s:%s", h.hint, base58.Encode(h.Body()), h.algorithm)
+}
+
+// ParseHash parses the string form of Hash, `<hint>:<base58 encoded body>` or
+// `<hint>:<base58 encoded body>:<algorithm>`.
+func ParseHash(s string) (Hash, error) {
+	var algorithm Algorithm
+	if i := strings.LastIndex(s, ":"); i > 0 && strings.Contains(s[:i], ":") {
+		if a, err := ParseAlgorithm(s[i+1:]); err == nil {
+			algorithm = a
+			s = s[:i]
+		}
+	}
+
+	i := strings.LastIndex(s, ":")
+	if i < 1 || i == len(s)-1 {
+		return Hash{}, InvalidHashInputError.Newf("invalid hash string; %q", s)
+	}
+
+	body := base58.Decode(s[i+1:])
+	if len(body) < 1 {
+		return Hash{}, InvalidHashInputError.Newf("invalid base58 body; %q", s)
+	} else if len(body) > MaxBodyLength {
+		return Hash{}, InvalidHashInputError.Newf("too long body; length=%d", len(body))
+	}
+
+	h, err := NewHash(s[:i], body)
+	if err != nil {
+		return Hash{}, InvalidHashInputError.New(err)
+	}
+
+	if err := h.IsValid(); err != nil {
+		return Hash{}, InvalidHashInputError.New(err)
+	}
+	h.algorithm = algorithm
+
+	return h, nil
 }
@@ -1,6 +1,8 @@
 package hash
 
 import (
+	"encoding/json"
+	"strconv"
 	"testing"
 
 	"github.com/btcsuite/btcutil/base58"
@@ -88,6 +90,149 @@ func (t *testHash) TestNilHash() {
 	t.Equal("block:N1LHASH", h.String())
 }
 
+func (t *testHash) TestParse() {
+	hash, err := NewDoubleSHAHash("block", []byte("show me"))
+	t.NoError(err)
+
+	uhash, err := ParseHash(hash.String())
+	t.NoError(err)
+	t.True(hash.Equal(uhash))
+	t.Equal(hash.String(), uhash.String())
+
+	{ // with algorithm
+		hash, err := NewHashByAlgorithm(Blake2b256, "block", []byte("show me"))
+		t.NoError(err)
+		t.Contains(hash.String(), ":"+Blake2b256.String())
+
+		uhash, err := ParseHash(hash.String())
+		t.NoError(err)
+		t.Equal(hash, uhash)
+		t.NoError(uhash.Verify([]byte("show me")))
+
+		b, err := json.Marshal(hash)
+		t.NoError(err)
+
+		var s string
+		t.NoError(json.Unmarshal(b, &s))
+
+		uhash, err = ParseHash(s)
+		t.NoError(err)
+		t.Equal(hash, uhash)
+	}
+
+	nilHash, err := ParseHash("block:N1LHASH")
+	t.NoError(err)
+	t.True(nilHash.IsNil())
+
+	for _, s := range []string{
+		"",
+		"block",
+		"block:",
+		":5NfGRdg6ex",
+		"block:0OIl", // NOTE invalid base58 characters
+	} {
+		_, err := ParseHash(s)
+		t.True(xerrors.Is(InvalidHashInputError, err), s)
+	}
+}
+
+func (t *testHash) TestEqualAlgorithm() {
+	hash, err := NewHashByAlgorithm(Blake2b256, "block", []byte("show me"))
+	t.NoError(err)
+
+	// NOTE same hint and body, but different algorithm
+	other, err := NewHash(hash.Hint(), hash.Body())
+	t.NoError(err)
+	t.Equal(DoubleSHA256, other.Algorithm())
+
+	t.False(hash.Equal(other))
+	t.False(other.Equal(hash))
+	t.NotEqual(hash, other)
+}
+
+func (t *testHash) TestMapKey() {
+	for _, algorithm := range []Algorithm{DoubleSHA256, Blake2b256} {
+		computed, err := NewHashByAlgorithm(algorithm, "block", []byte("show me"))
+		t.NoError(err)
+
+		parsed, err := ParseHash(computed.String())
+		t.NoError(err)
+		t.True(computed.Equal(parsed))
+
+		m := map[Hash]struct{}{computed: {}}
+		_, found := m[parsed]
+		t.True(found, algorithm.String())
+
+		m[parsed] = struct{}{}
+		t.Equal(1, len(m), algorithm.String())
+
+		// NOTE the hash of the other algorithm is not the same key
+		other, err := NewHash(computed.Hint(), computed.Body())
+		t.NoError(err)
+		other.algorithm = SHA3256
+
+		_, found = m[other]
+		t.False(found)
+		t.False(computed.Equal(other))
+	}
+}
+
 func TestHash(t *testing.T) {
 	suite.Run(t, new(testHash))
 }
+
+func newBenchmarkHashes(n int) []Hash {
+	hs := make([]Hash, n)
+	for i := range hs {
+		hs[i], _ = NewDoubleSHAHash("block", []byte(strconv.Itoa(i)))
+	}
+
+	return hs
+}
+
+func BenchmarkHashEncodeRLP(b *testing.B) {
+	h, _ := NewDoubleSHAHash("block", []byte("show me"))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = rlp.EncodeToBytes(h)
+	}
+}
+
+func BenchmarkHashDecodeRLP(b *testing.B) {
+	h, _ := NewDoubleSHAHash("block", []byte("show me"))
+	e, _ := rlp.EncodeToBytes(h)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var uh Hash
+		_ = rlp.DecodeBytes(e, &uh)
+	}
+}
+
+func BenchmarkHashMapKey(b *testing.B) {
+	hs := newBenchmarkHashes(1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := map[Hash]struct{}{}
+		for _, h := range hs {
+			m[h] = struct{}{}
+		}
+	}
+}
+
+func BenchmarkHashCopy(b *testing.B) {
+	hs := newBenchmarkHashes(1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var c []Hash
+		c = append(c, hs...)
+		_ = c
+	}
+}
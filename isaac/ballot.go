@@ -20,7 +20,7 @@ var (
 )
 
 func NewBallotHash(b []byte) (hash.Hash, error) {
-	return hash.NewDoubleSHAHash(BallotHashHint, b)
+	return hash.NewHashByHint(BallotHashHint, b)
 }
 
 type Ballot struct {
@@ -154,11 +154,11 @@ func (ib Ballot) IsValid() error {
 		)
 	}
 
-	h0, err := ib.body.makeHash()
+	b, err := ib.body.hashBytes()
 	if err != nil {
 		return err
-	} else if !h0.Equal(ib.Body().Hash()) {
-		return xerrors.Errorf("hash does not match; expected=%q hash=Tq", h0, ib.Body().Hash())
+	} else if err := ib.Body().Hash().Verify(b); err != nil {
+		return err
 	}
 
 	return nil
@@ -170,7 +170,7 @@ func (ib Ballot) Empty() bool {
 
 type BallotBody interface {
 	seal.Body
-	makeHash() (hash.Hash, error)
+	hashBytes() ([]byte, error)
 	Node() node.Address
 	Stage() Stage
 	Height() Height
@@ -326,6 +326,15 @@ func (bbb *BaseBallotBody) DecodeRLP(s *rlp.Stream) error {
 }
 
 func (bbb BaseBallotBody) makeHash() (hash.Hash, error) {
+	b, err := bbb.hashBytes()
+	if err != nil {
+		return hash.Hash{}, err
+	}
+
+	return NewBallotHash(b)
+}
+
+func (bbb BaseBallotBody) hashBytes() ([]byte, error) {
 	ib := BaseBallotBody{
 		node:      bbb.node,
 		stage:     bbb.stage,
@@ -337,10 +346,5 @@ func (bbb BaseBallotBody) makeHash() (hash.Hash, error) {
 		lastRound: bbb.lastRound,
 	}
 
-	b, err := rlp.EncodeToBytes(ib)
-	if err != nil {
-		return hash.Hash{}, err
-	}
-
-	return NewBallotHash(b)
+	return rlp.EncodeToBytes(ib)
 }
@@ -25,6 +25,7 @@ func NewCompilerBallotChecker(homeState *HomeState, suffrage Suffrage) *common.C
 		context.Background(),
 		cbc.initialize,
 		cbc.checkInSuffrage,
+		cbc.checkSigner,
 		cbc.checkHeightAndRound,
 		cbc.checkINIT,
 		cbc.checkNotINIT,
@@ -73,6 +74,16 @@ func (cbc CompilerBallotChecker) checkInSuffrage(c *common.ChainChecker) error {
 	return nil
 }
 
+// checkSigner checks the signer of ballot is the key of node at the height.
+func (cbc CompilerBallotChecker) checkSigner(c *common.ChainChecker) error {
+	var ballot Ballot
+	if err := c.ContextValue("ballot", &ballot); err != nil {
+		return err
+	}
+
+	return VerifySigner(cbc.suffrage, ballot.Node(), ballot.Height(), ballot.Signer())
+}
+
 func (cbc CompilerBallotChecker) checkHeightAndRound(c *common.ChainChecker) error {
 	var ballot Ballot
 	if err := c.ContextValue("ballot", &ballot); err != nil {
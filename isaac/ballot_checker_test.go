@@ -5,7 +5,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
 
+	"github.com/spikeekips/mitum/keypair"
 	"github.com/spikeekips/mitum/node"
 )
 
@@ -29,6 +31,7 @@ func (t *testCompilerBallotChecker) TestEmptyLastVoteResult() {
 		Round(1),
 		nextBlock.Proposal(),
 	)
+	_ = ballot.Sign(home.PrivateKey(), nil)
 
 	suffrage := NewFixedProposerSuffrage(home, home)
 	checker := NewCompilerBallotChecker(homeState, suffrage)
@@ -57,6 +60,7 @@ func (t *testCompilerBallotChecker) TestINITBallotHeightNotHigherThanHomeState()
 		Round(0),
 		nextBlock.Proposal(),
 	)
+	_ = ballot.Sign(home.PrivateKey(), nil)
 
 	suffrage := NewFixedProposerSuffrage(home, home)
 	checker := NewCompilerBallotChecker(homeState, suffrage)
@@ -93,6 +97,7 @@ func (t *testCompilerBallotChecker) TestINITBallotHeightLowerThanLastINITVoteRes
 		Round(0),
 		nextBlock.Proposal(),
 	)
+	_ = ballot.Sign(home.PrivateKey(), nil)
 
 	suffrage := NewFixedProposerSuffrage(home, home)
 	checker := NewCompilerBallotChecker(homeState, suffrage)
@@ -121,6 +126,7 @@ func (t *testCompilerBallotChecker) TestSIGNBallotHeightNotSameWithLastINITVoteR
 		nextBlock.Round(),
 		nextBlock.Proposal(),
 	)
+	_ = ballot.Sign(home.PrivateKey(), nil)
 
 	lastINITVoteResult := NewVoteResult(
 		lastBlock.Height(),
@@ -157,6 +163,7 @@ func (t *testCompilerBallotChecker) TestSIGNBallotRoundNotSameWithLastINITVoteRe
 		nextBlock.Round(),
 		nextBlock.Proposal(),
 	)
+	_ = ballot.Sign(home.PrivateKey(), nil)
 
 	lastINITVoteResult := NewVoteResult(
 		nextBlock.Height(),
@@ -205,6 +212,7 @@ func (t *testCompilerBallotChecker) TestBallotFromNotActingNode() {
 		nextBlock.Round(),
 		nextBlock.Proposal(),
 	)
+	_ = ballotInActing.Sign(home.PrivateKey(), nil)
 
 	err := checker.
 		New(context.TODO()).
@@ -225,6 +233,7 @@ func (t *testCompilerBallotChecker) TestBallotFromNotActingNode() {
 		nextBlock.Round(),
 		nextBlock.Proposal(),
 	)
+	_ = ballotOther.Sign(other.PrivateKey(), nil)
 
 	err = checker.
 		New(context.TODO()).
@@ -235,6 +244,39 @@ func (t *testCompilerBallotChecker) TestBallotFromNotActingNode() {
 	t.Contains(err.Error(), "not in acting suffrage")
 }
 
+func (t *testCompilerBallotChecker) TestSignerNotOwner() {
+	home := node.NewRandomHome()
+	lastBlock := NewRandomBlock()
+	nextBlock := NewRandomNextBlock(lastBlock)
+
+	homeState := NewHomeState(home, lastBlock)
+
+	ballot, _ := NewINITBallot(
+		home.Address(),
+		lastBlock.Hash(),
+		nextBlock.Round(),
+		nextBlock.Height().Add(1),
+		nextBlock.Hash(),
+		Round(1),
+		nextBlock.Proposal(),
+	)
+
+	// NOTE signed by the key, which is not the owner of home address
+	pk, _ := keypair.NewStellarPrivateKey()
+	_ = ballot.Sign(pk, nil)
+	t.NoError(ballot.IsValid())
+
+	suffrage := NewFixedProposerSuffrage(home, home)
+	checker := NewCompilerBallotChecker(homeState, suffrage)
+	err := checker.
+		New(context.TODO()).
+		SetContext("ballot", ballot).
+		SetContext("lastINITVoteResult", VoteResult{}).
+		SetContext("lastStagesVoteResult", VoteResult{}).
+		Check()
+	t.True(xerrors.Is(err, node.InvalidAddressError))
+}
+
 func TestCompilerBallotChecker(t *testing.T) {
 	suite.Run(t, new(testCompilerBallotChecker))
 }
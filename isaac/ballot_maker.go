@@ -30,6 +30,14 @@ type BallotMaker interface {
 		currentRound Round,
 		currentProposal hash.Hash,
 	) (Ballot, error) // NOTE signed seal
+	ALLCONFIRM(
+		lastBlock hash.Hash,
+		lastRound Round,
+		nextHeight Height,
+		nextBlock hash.Hash,
+		currentRound Round,
+		currentProposal hash.Hash,
+	) (Ballot, error) // NOTE signed seal
 }
 
 type DefaultBallotMaker struct {
@@ -94,8 +102,26 @@ func (db DefaultBallotMaker) ACCEPT(
 	return db.sign(ballot)
 }
 
+func (db DefaultBallotMaker) ALLCONFIRM(
+	lastBlock hash.Hash,
+	lastRound Round,
+	nextHeight Height,
+	nextBlock hash.Hash,
+	currentRound Round,
+	currentProposal hash.Hash,
+) (Ballot, error) {
+	ballot, err := NewALLCONFIRMBallot(
+		db.home.Address(), lastBlock, lastRound, nextHeight, nextBlock, currentRound, currentProposal,
+	)
+	if err != nil {
+		return Ballot{}, err
+	}
+
+	return db.sign(ballot)
+}
+
 func (db DefaultBallotMaker) sign(ballot Ballot) (Ballot, error) {
-	if err := ballot.Sign(db.home.PrivateKey(), nil); err != nil {
+	if err := ballot.SignAt(db.home.Signer(), nil, db.home.Now()); err != nil {
 		return Ballot{}, err
 	}
 
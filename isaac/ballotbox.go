@@ -9,6 +9,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/spikeekips/mitum/common"
 	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/keypair"
 	"github.com/spikeekips/mitum/node"
 )
 
@@ -16,6 +17,7 @@ type Ballotbox struct {
 	*common.Logger
 	voted     *sync.Map
 	threshold *Threshold
+	suffrage  Suffrage
 }
 
 func NewBallotbox(threshold *Threshold) *Ballotbox {
@@ -28,42 +30,84 @@ func NewBallotbox(threshold *Threshold) *Ballotbox {
 	}
 }
 
-func (bb *Ballotbox) Vote(
-	n node.Address,
-	height Height,
-	round Round,
-	stage Stage,
-	block hash.Hash,
-	lastBlock hash.Hash,
-	lastRound Round,
-	proposal hash.Hash,
-) (VoteResult, error) {
+// SetSuffrage sets the suffrage; with suffrage, the majority VoteResult of the
+// ballots, which are signed by BLS keypair, has the AggregatedVoteProof.
+func (bb *Ballotbox) SetSuffrage(suffrage Suffrage) *Ballotbox {
+	bb.suffrage = suffrage
+
+	return bb
+}
+
+func (bb *Ballotbox) Vote(ballot Ballot) (VoteResult, error) {
 	key := fmt.Sprintf(
 		"%v-%v-%v",
-		height.String(),
-		round,
-		stage.String(),
+		ballot.Height().String(),
+		ballot.Round(),
+		ballot.Stage().String(),
 	)
 
 	var rs *Records
 	if i, found := bb.voted.Load(key); !found {
-		rs = NewRecords(height, round, stage)
+		rs = NewRecords(ballot.Height(), ballot.Round(), ballot.Stage())
 		_ = rs.SetLogger(*bb.Log())
 		bb.voted.Store(key, rs)
 	} else {
 		rs = i.(*Records)
 	}
 
-	if err := rs.Vote(n, block, lastBlock, lastRound, proposal); err != nil {
+	if err := rs.Vote(ballot); err != nil {
 		return VoteResult{}, err
 	}
 
 	total, threshold := bb.threshold.Get(rs.stage)
 	vr := rs.CheckMajority(total, threshold)
+	if vr.GotMajority() && !vr.IsClosed() {
+		vr = rs.SetResult(bb.voteProof(vr, threshold))
+	}
 
 	return vr, nil
 }
 
+// voteProof attaches the AggregatedVoteProof to the majority VoteResult. The
+// ballots of the nodes, which are not in the acting suffrage, are excluded;
+// if any signer of the acting suffrage does not use BLS keypair or the
+// remaining ballots are not over threshold, the VoteResult has no proof.
+func (bb *Ballotbox) voteProof(vr VoteResult, threshold uint) VoteResult {
+	if bb.suffrage == nil {
+		return vr
+	}
+
+	acting := bb.suffrage.Acting(vr.Height(), vr.Round())
+
+	var ballots []Ballot
+	for _, r := range vr.Records() {
+		if !r.block.Equal(vr.Block()) ||
+			!r.lastBlock.Equal(vr.LastBlock()) ||
+			r.lastRound != vr.LastRound() ||
+			!r.proposal.Equal(vr.Proposal()) {
+			continue
+		} else if !acting.Exists(r.node) {
+			continue
+		} else if !r.ballot.Signer().Type().Equal(keypair.BLSType) {
+			return vr
+		}
+
+		ballots = append(ballots, r.ballot)
+	}
+
+	if uint(len(ballots)) < threshold {
+		return vr
+	}
+
+	avp, err := NewAggregatedVoteProof(vr, acting, ballots)
+	if err != nil {
+		bb.Log().Error().Err(err).Object("vr", vr).Msg("failed to make AggregatedVoteProof")
+		return vr
+	}
+
+	return vr.SetVoteProof(avp)
+}
+
 func (bb *Ballotbox) Tidy(height Height, round Round) {
 	var keys []interface{}
 	prefix := fmt.Sprintf("%v-", height.String())
@@ -131,17 +175,11 @@ func (rs *Records) key(
 	)
 }
 
-func (rs *Records) Vote(
-	n node.Address,
-	block hash.Hash,
-	lastBlock hash.Hash,
-	lastRound Round,
-	proposal hash.Hash,
-) error {
+func (rs *Records) Vote(ballot Ballot) error {
 	rs.Lock()
 	defer rs.Unlock()
 
-	key := rs.key(block, lastBlock, lastRound, proposal)
+	key := rs.key(ballot.Block(), ballot.LastBlock(), ballot.LastRound(), ballot.Proposal())
 
 	var nr *NodesRecord
 	if i, found := rs.voted.Load(key); !found {
@@ -151,7 +189,7 @@ func (rs *Records) Vote(
 		nr = i.(*NodesRecord)
 	}
 
-	_ = nr.Vote(n, block, lastBlock, lastRound, proposal)
+	_ = nr.Vote(ballot)
 
 	return nil
 }
@@ -239,6 +277,18 @@ func (rs *Records) IsClosed() bool {
 	return rs.result.IsClosed()
 }
 
+// SetResult replaces the finished result.
+func (rs *Records) SetResult(vr VoteResult) VoteResult {
+	rs.Lock()
+	defer rs.Unlock()
+
+	if rs.result.IsFinished() {
+		rs.result = vr
+	}
+
+	return vr
+}
+
 func (rs *Records) Result() VoteResult {
 	rs.RLock()
 	defer rs.RUnlock()
@@ -263,18 +313,18 @@ type Record struct {
 	lastRound Round
 	proposal  hash.Hash
 	votedAt   common.Time
-
-	// TODO needs ballot hash
+	ballot    Ballot
 }
 
-func NewRecord(n node.Address, block hash.Hash, lastBlock hash.Hash, lastRound Round, proposal hash.Hash) Record {
+func NewRecord(ballot Ballot) Record {
 	return Record{
-		node:      n,
-		block:     block,
-		lastBlock: lastBlock,
-		lastRound: lastRound,
-		proposal:  proposal,
+		node:      ballot.Node(),
+		block:     ballot.Block(),
+		lastBlock: ballot.LastBlock(),
+		lastRound: ballot.LastRound(),
+		proposal:  ballot.Proposal(),
 		votedAt:   common.Now(),
+		ballot:    ballot,
 	}
 }
 
@@ -302,6 +352,11 @@ func (rc Record) VotedAt() common.Time {
 	return rc.votedAt
 }
 
+// Ballot is the voted ballot.
+func (rc Record) Ballot() Ballot {
+	return rc.ballot
+}
+
 func (rc Record) MarshalJSON() ([]byte, error) {
 	return json.Marshal(map[string]interface{}{
 		"node":       rc.node,
@@ -335,14 +390,8 @@ func NewNodesRecord() *NodesRecord {
 	return &NodesRecord{voted: &sync.Map{}}
 }
 
-func (nr *NodesRecord) Vote(
-	n node.Address,
-	block hash.Hash,
-	lastBlock hash.Hash,
-	lastRound Round,
-	proposal hash.Hash,
-) *NodesRecord {
-	nr.voted.Store(n, NewRecord(n, block, lastBlock, lastRound, proposal))
+func (nr *NodesRecord) Vote(ballot Ballot) *NodesRecord {
+	nr.voted.Store(ballot.Node(), NewRecord(ballot))
 
 	return nr
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/keypair"
 	"github.com/spikeekips/mitum/node"
 )
 
@@ -20,16 +21,25 @@ func (t *testBallotbox) vote(
 	lastBlock,
 	nextBlock Block,
 ) (VoteResult, error) {
-	return bb.Vote(
-		n,
-		nextBlock.Height(),
-		nextBlock.Round(),
-		stage,
-		nextBlock.Hash(),
-		lastBlock.Hash(),
-		lastBlock.Round(),
-		nextBlock.Proposal(),
-	)
+	return bb.Vote(newBallot(n, stage, lastBlock, nextBlock))
+}
+
+func newBallot(n node.Address, stage Stage, lastBlock, nextBlock Block) Ballot {
+	body := BaseBallotBody{
+		node:      n,
+		stage:     stage,
+		height:    nextBlock.Height(),
+		round:     nextBlock.Round(),
+		proposal:  nextBlock.Proposal(),
+		block:     nextBlock.Hash(),
+		lastBlock: lastBlock.Hash(),
+		lastRound: lastBlock.Round(),
+	}
+	body.hash, _ = body.makeHash()
+
+	ballot, _ := NewBallot(body)
+
+	return ballot
 }
 
 func (t *testBallotbox) TestVote() {
@@ -294,6 +304,107 @@ func (t *testBallotbox) TestGotDrawAnotherLastBlock() {
 	t.Equal(int(total), len(lastVR.Records()))
 }
 
+func (t *testBallotbox) votePrivateKeys(bb *Ballotbox, pks []keypair.PrivateKey) VoteResult {
+	var homes []node.Node
+	for _, pk := range pks {
+		homes = append(homes, node.NewHome(node.NewRandomAddress(), pk))
+	}
+	_ = bb.SetSuffrage(NewFixedProposerSuffrage(homes[0], homes...))
+
+	lastBlock := NewRandomBlock()
+	nextBlock := NewRandomNextBlock(lastBlock)
+
+	var vr VoteResult
+	for _, n := range homes {
+		ballot, err := NewACCEPTBallot(
+			n.Address(),
+			lastBlock.Hash(),
+			lastBlock.Round(),
+			nextBlock.Height(),
+			nextBlock.Hash(),
+			nextBlock.Round(),
+			nextBlock.Proposal(),
+		)
+		t.NoError(err)
+		t.NoError(ballot.Sign(n.(node.Home).PrivateKey(), nil))
+
+		vr, err = bb.Vote(ballot)
+		t.NoError(err)
+		if vr.GotMajority() {
+			break
+		}
+	}
+
+	t.True(vr.GotMajority())
+
+	return vr
+}
+
+func (t *testBallotbox) TestVoteProof() {
+	thr, err := NewThreshold(4, 67)
+	t.NoError(err)
+
+	var pks []keypair.PrivateKey
+	for i := 0; i < 4; i++ {
+		pk, _ := keypair.NewBLSPrivateKey()
+		pks = append(pks, pk)
+	}
+
+	bb := NewBallotbox(thr)
+	vr := t.votePrivateKeys(bb, pks)
+
+	avp, found := vr.VoteProof()
+	t.True(found)
+
+	_, threshold := thr.Get(StageACCEPT)
+	acting := bb.suffrage.Acting(vr.Height(), vr.Round())
+	t.NoError(avp.Verify(acting, threshold))
+	t.True(vr.Block().Equal(avp.Block()))
+}
+
+func (t *testBallotbox) TestVoteProofNotBLS() {
+	thr, err := NewThreshold(4, 67)
+	t.NoError(err)
+
+	var pks []keypair.PrivateKey
+	for i := 0; i < 4; i++ {
+		pk, _ := keypair.NewBLSPrivateKey()
+		pks = append(pks, pk)
+	}
+	pks[1], _ = keypair.NewStellarPrivateKey()
+
+	vr := t.votePrivateKeys(NewBallotbox(thr), pks)
+
+	_, found := vr.VoteProof()
+	t.False(found)
+}
+
 func TestBallotbox(t *testing.T) {
 	suite.Run(t, new(testBallotbox))
 }
+
+func BenchmarkBallotboxVote(b *testing.B) {
+	thr, _ := NewThreshold(100, 67)
+
+	nodes := make([]node.Address, 100)
+	for i := range nodes {
+		nodes[i] = node.NewRandomAddress()
+	}
+
+	lastBlock := NewRandomBlock()
+	nextBlock := NewRandomNextBlock(lastBlock)
+
+	ballots := map[node.Address]Ballot{}
+	for _, n := range nodes {
+		ballots[n] = newBallot(n, StageSIGN, lastBlock, nextBlock)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bb := NewBallotbox(thr)
+		for _, n := range nodes {
+			_, _ = bb.Vote(ballots[n])
+		}
+	}
+}
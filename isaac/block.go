@@ -16,7 +16,7 @@ var (
 )
 
 func NewBlockHash(b []byte) (hash.Hash, error) {
-	return hash.NewDoubleSHAHash(BlockHashHint, b)
+	return hash.NewHashByHint(BlockHashHint, b)
 }
 
 func IsBlockHash(h hash.Hash) bool {
@@ -13,7 +13,7 @@ type BootingStateHandler struct {
 	sync.RWMutex
 	*common.Logger
 	started         bool
-	chanState       chan StateContext
+	chanState       stateChannel
 	proposalChecker *common.ChainChecker
 }
 
@@ -56,9 +56,11 @@ func (bs *BootingStateHandler) IsStopped() bool {
 	return bs.started
 }
 
-func (bs *BootingStateHandler) Activate(StateContext) error {
+func (bs *BootingStateHandler) Activate(sct StateContext) error {
+	bs.chanState.activate(sct)
+
 	go func() {
-		bs.chanState <- NewStateContext(node.StateJoining)
+		bs.chanState.sendWithGeneration(sct.generation, NewStateContext(node.StateJoining))
 	}()
 
 	return nil
@@ -68,8 +70,8 @@ func (bs *BootingStateHandler) Deactivate() error {
 	return nil
 }
 
-func (bs *BootingStateHandler) SetChanState(ch chan StateContext) StateHandler {
-	bs.chanState = ch
+func (bs *BootingStateHandler) SetChanState(ch chan StateContext, stop chan struct{}) StateHandler {
+	bs.chanState.set(ch, stop)
 	return bs
 }
 
@@ -20,7 +20,7 @@ func (t *testBootingStateHandler) TestMoveToNextState() {
 
 	chanState := make(chan StateContext)
 	bs := NewBootingStateHandler(homeState)
-	_ = bs.SetChanState(chanState)
+	_ = bs.SetChanState(chanState, nil)
 
 	t.NoError(bs.Start())
 	defer bs.Stop()
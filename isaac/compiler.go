@@ -58,16 +58,7 @@ func (cm *Compiler) Vote(ballot Ballot) (VoteResult, error) {
 
 	cm.Log().Debug().Object("ballot", ballot.Hash()).Msg("ballot checked")
 
-	vr, err := cm.ballotbox.Vote(
-		ballot.Node(),
-		ballot.Height(),
-		ballot.Round(),
-		ballot.Stage(),
-		ballot.Block(),
-		ballot.LastBlock(),
-		ballot.LastRound(),
-		ballot.Proposal(),
-	)
+	vr, err := cm.ballotbox.Vote(ballot)
 	if err != nil {
 		return VoteResult{}, err
 	}
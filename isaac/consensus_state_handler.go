@@ -3,12 +3,12 @@ package isaac
 import (
 	"context"
 	"sync"
-	"time"
 
 	"golang.org/x/xerrors"
 
 	"github.com/rs/zerolog"
 	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/hash"
 	"github.com/spikeekips/mitum/network"
 	"github.com/spikeekips/mitum/node"
 )
@@ -17,20 +17,32 @@ import (
 type ConsensusStateHandler struct {
 	sync.RWMutex
 	*common.Logger
-	homeState             *HomeState
-	compiler              *Compiler
-	nt                    network.Network
-	suffrage              Suffrage
-	ballotMaker           BallotMaker
-	proposalValidator     ProposalValidator
-	proposalMaker         ProposalMaker
-	timeoutWaitBallot     time.Duration
-	timeoutWaitINITBallot time.Duration
-	started               bool
-	chanState             chan StateContext
-	timer                 *common.CallbackTimer
-	proposalChecker       *common.ChainChecker
-	voteResultChecker     *common.ChainChecker
+	homeState         *HomeState
+	compiler          *Compiler
+	nt                network.Network
+	suffrage          Suffrage
+	ballotMaker       BallotMaker
+	proposalValidator ProposalValidator
+	proposalMaker     ProposalMaker
+	policyKeeper      *PolicyKeeper
+	started           bool
+	chanState         stateChannel
+	timer             *common.CallbackTimer
+	proposalChecker   *common.ChainChecker
+	voteResultChecker *common.ChainChecker
+	pipelined         *pipelinedProposal
+	sealFetcher       *SealFetcher
+}
+
+// pipelinedProposal is the proposal, which is speculatively prepared for the
+// next block.
+type pipelinedProposal struct {
+	height    Height
+	round     Round
+	lastBlock hash.Hash
+	done      chan struct{}
+	proposal  Proposal
+	err       error
 }
 
 func NewConsensusStateHandler(
@@ -41,8 +53,7 @@ func NewConsensusStateHandler(
 	ballotMaker BallotMaker,
 	proposalValidator ProposalValidator,
 	proposalMaker ProposalMaker,
-	timeoutWaitBallot time.Duration,
-	timeoutWaitINITBallot time.Duration,
+	policyKeeper *PolicyKeeper,
 ) (*ConsensusStateHandler, error) {
 	if homeState.PreviousBlock().Empty() {
 		return nil, xerrors.Errorf("previous block is empty")
@@ -52,25 +63,20 @@ func NewConsensusStateHandler(
 		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
 			return c.Str("module", "s.h.consensus")
 		}),
-		homeState:             homeState,
-		compiler:              compiler,
-		nt:                    nt,
-		suffrage:              suffrage,
-		ballotMaker:           ballotMaker,
-		proposalValidator:     proposalValidator,
-		proposalMaker:         proposalMaker,
-		timeoutWaitBallot:     timeoutWaitBallot,
-		timeoutWaitINITBallot: timeoutWaitINITBallot,
-		proposalChecker:       NewProposalCheckerConsensus(homeState, suffrage),
-		voteResultChecker:     NewConsensusVoteResultChecker(homeState),
+		homeState:         homeState,
+		compiler:          compiler,
+		nt:                nt,
+		suffrage:          suffrage,
+		ballotMaker:       ballotMaker,
+		proposalValidator: proposalValidator,
+		proposalMaker:     proposalMaker,
+		policyKeeper:      policyKeeper,
+		proposalChecker:   NewProposalCheckerConsensus(homeState, suffrage, policyKeeper),
+		voteResultChecker: NewConsensusVoteResultChecker(homeState),
 	}, nil
 }
 
 func (cs *ConsensusStateHandler) Start() error {
-	if cs.timeoutWaitBallot < time.Nanosecond {
-		cs.Log().Warn().Dur("timeout", cs.timeoutWaitBallot).Msg("timeoutWaitBallot is too short")
-	}
-
 	_ = cs.Stop() // nolint
 
 	cs.Lock()
@@ -102,6 +108,8 @@ func (cs *ConsensusStateHandler) IsStopped() bool {
 func (cs *ConsensusStateHandler) Activate(sct StateContext) error {
 	_ = cs.stopTimer() // nolint
 
+	cs.chanState.activate(sct)
+
 	var vr VoteResult
 	if err := sct.ContextValue("vr", &vr); err != nil {
 		return xerrors.Errorf("ConsensusStateHandler fail to Activate(); %w", err)
@@ -125,8 +133,16 @@ func (cs *ConsensusStateHandler) Deactivate() error {
 	return cs.stopTimer()
 }
 
-func (cs *ConsensusStateHandler) SetChanState(ch chan StateContext) StateHandler {
-	cs.chanState = ch
+func (cs *ConsensusStateHandler) SetChanState(ch chan StateContext, stop chan struct{}) StateHandler {
+	cs.chanState.set(ch, stop)
+	return cs
+}
+
+// SetSealFetcher sets the SealFetcher, which fetches the missing proposal and
+// transactions before storing new block. It should be called before Start().
+func (cs *ConsensusStateHandler) SetSealFetcher(fetcher *SealFetcher) *ConsensusStateHandler {
+	cs.sealFetcher = fetcher
+
 	return cs
 }
 
@@ -261,12 +277,20 @@ func (cs *ConsensusStateHandler) gotINITMajority(vr VoteResult) error {
 				Object("block_vr", vr.LastBlock()).
 				Object("vr", vr).
 				Msg("init for next block; last block does not match; move to sync")
-			cs.chanState <- NewStateContext(node.StateSyncing).
-				SetContext("vr", vr)
+			cs.chanState.send(NewStateContext(node.StateSyncing).
+				SetContext("vr", vr))
 
 			return xerrors.Errorf("init for next block; last block does not match; move to sync")
 		}
 
+		if err := fetchProposal(cs.sealFetcher, vr.Height(), vr.Round(), vr.Proposal()); err != nil {
+			cs.Log().Error().Err(err).Object("vr", vr).Msg("failed to fetch proposal; move to join")
+			cs.chanState.send(NewStateContext(node.StateJoining).
+				SetContext("vr", vr))
+
+			return err
+		}
+
 		block, err := cs.proposalValidator.NewBlock(vr.Height(), vr.Round(), vr.Proposal())
 		if err != nil {
 			cs.Log().Error().
@@ -276,10 +300,20 @@ func (cs *ConsensusStateHandler) gotINITMajority(vr VoteResult) error {
 			return err
 		}
 
+		// NOTE without the transactions of block, the node can not follow the
+		// Policy and Suffrage of the next block
+		if err := storeBlock(block, cs.policyKeeper, cs.suffrage); err != nil {
+			cs.Log().Error().Err(err).Object("block", block).Msg("failed to apply new block; move to join")
+			cs.chanState.send(NewStateContext(node.StateJoining).
+				SetContext("vr", vr))
+
+			return err
+		}
+
 		_ = cs.homeState.SetBlock(block)
 
 		cs.Log().Info().Object("block", block).Object("vr", vr).Msg("new block created")
-	case diff == 1: // next round
+	case diff == 1: // next round or new block already stored by ALLCONFIRM
 		cs.Log().Debug().Object("vr", vr).Msg("got VoteResult of next round; keep going")
 
 		if !cs.homeState.Block().Hash().Equal(vr.Block()) {
@@ -288,15 +322,15 @@ func (cs *ConsensusStateHandler) gotINITMajority(vr VoteResult) error {
 				Object("block_vr", vr.Block()).
 				Object("vr", vr).
 				Msg("init for next round; block does not match; move to sync")
-			cs.chanState <- NewStateContext(node.StateSyncing).
-				SetContext("vr", vr)
+			cs.chanState.send(NewStateContext(node.StateSyncing).
+				SetContext("vr", vr))
 
 			return xerrors.Errorf("init for next round; block does not match; move to sync")
 		}
 	default: // unexpected height received, move to sync
 		cs.Log().Debug().Object("vr", vr).Msg("got not expected height VoteResult; move to sync")
-		cs.chanState <- NewStateContext(node.StateSyncing).
-			SetContext("vr", vr)
+		cs.chanState.send(NewStateContext(node.StateSyncing).
+			SetContext("vr", vr))
 		return xerrors.Errorf("got not expected height VoteResult; move to sync")
 	}
 
@@ -305,7 +339,7 @@ func (cs *ConsensusStateHandler) gotINITMajority(vr VoteResult) error {
 
 func (cs *ConsensusStateHandler) gotNotINITMajority(vr VoteResult) error {
 	switch vr.Stage() {
-	case StageSIGN, StageACCEPT:
+	case StageSIGN, StageACCEPT, StageALLCONFIRM:
 	default:
 		return xerrors.Errorf("invalid stage found", "vr", vr)
 	}
@@ -333,6 +367,8 @@ func (cs *ConsensusStateHandler) gotNotINITMajority(vr VoteResult) error {
 		return cs.gotSIGNMajority(block, vr)
 	case StageACCEPT:
 		return cs.gotACCEPTMajority(block, vr)
+	case StageALLCONFIRM:
+		return cs.gotALLCONFIRMMajority(block, vr)
 	default:
 		return xerrors.Errorf("invalid stage found", "vr", vr)
 	}
@@ -343,6 +379,10 @@ func (cs *ConsensusStateHandler) gotSIGNMajority(block Block, vr VoteResult) err
 		return err
 	}
 
+	if cs.policyKeeper.Policy().PipelineProposal {
+		cs.pipelineProposal(block)
+	}
+
 	acting := cs.suffrage.Acting(vr.Height(), vr.Round())
 	if !acting.Exists(cs.homeState.Home().Address()) {
 		cs.Log().Debug().
@@ -381,6 +421,10 @@ func (cs *ConsensusStateHandler) gotSIGNMajority(block Block, vr VoteResult) err
 }
 
 func (cs *ConsensusStateHandler) gotACCEPTMajority(block Block, vr VoteResult) error {
+	if cs.policyKeeper.Policy().AllConfirm {
+		return cs.broadcastALLCONFIRMBallot(block, vr)
+	}
+
 	if err := cs.initFailedTimer("init-wait-timer", vr); err != nil {
 		return err
 	}
@@ -405,6 +449,106 @@ func (cs *ConsensusStateHandler) gotACCEPTMajority(block Block, vr VoteResult) e
 	return nil
 }
 
+// broadcastALLCONFIRMBallot confirms the accepted block once more before
+// storing it; if ALLCONFIRM does not reach majority until timeout, the next
+// round will be started like SIGN stage.
+func (cs *ConsensusStateHandler) broadcastALLCONFIRMBallot(block Block, vr VoteResult) error {
+	if err := cs.nextRoundTimer("ballot-timeout", vr); err != nil {
+		return err
+	}
+
+	acting := cs.suffrage.Acting(vr.Height(), vr.Round())
+	if !acting.Exists(cs.homeState.Home().Address()) {
+		cs.Log().Debug().
+			Object("vr", vr).
+			Uint64("height", vr.Height().Uint64()).
+			Uint64("round", vr.Round().Uint64()).
+			Object("acting", acting).
+			Msg("not acting member at this VoteResult; not broadcast allconfirm ballot")
+		return nil
+	}
+
+	ballot, err := cs.ballotMaker.ALLCONFIRM(
+		cs.homeState.Block().Hash(),
+		cs.homeState.Block().Round(),
+		vr.Height(),
+		block.Hash(),
+		vr.Round(),
+		vr.Proposal(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := cs.nt.Broadcast(ballot); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// gotALLCONFIRMMajority stores the confirmed block and broadcasts the INIT
+// ballot of next block. The INIT ballot is same with the one of ACCEPT stage,
+// so the nodes, which did not get ALLCONFIRM majority still can store the
+// block by INIT majority.
+func (cs *ConsensusStateHandler) gotALLCONFIRMMajority(block Block, vr VoteResult) error {
+	if err := cs.initFailedTimer("init-wait-timer", vr); err != nil {
+		return err
+	}
+
+	if !cs.homeState.Block().Hash().Equal(vr.LastBlock()) {
+		cs.Log().Error().
+			Object("home", cs.homeState.Block().Hash()).
+			Object("last_block_vr", vr.LastBlock()).
+			Object("vr", vr).
+			Msg("allconfirm; last block does not match; move to sync")
+		cs.chanState.send(NewStateContext(node.StateSyncing).
+			SetContext("vr", vr))
+
+		return xerrors.Errorf("allconfirm; last block does not match; move to sync")
+	}
+
+	lastBlock := cs.homeState.Block()
+
+	if err := fetchProposal(cs.sealFetcher, block.Height(), block.Round(), block.Proposal()); err != nil {
+		cs.Log().Error().Err(err).Object("block", block).Msg("failed to fetch proposal; move to join")
+		cs.chanState.send(NewStateContext(node.StateJoining).
+			SetContext("vr", vr))
+
+		return err
+	}
+
+	if err := storeBlock(block, cs.policyKeeper, cs.suffrage); err != nil {
+		cs.Log().Error().Err(err).Object("block", block).Msg("failed to apply new block; move to join")
+		cs.chanState.send(NewStateContext(node.StateJoining).
+			SetContext("vr", vr))
+
+		return err
+	}
+
+	_ = cs.homeState.SetBlock(block)
+
+	cs.Log().Info().Object("block", block).Object("vr", vr).Msg("new block created by allconfirm")
+
+	ballot, err := cs.ballotMaker.INIT(
+		lastBlock.Hash(),
+		block.Round(),
+		block.Height().Add(1),
+		block.Hash(),
+		Round(0),
+		block.Proposal(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := cs.nt.Broadcast(ballot); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (cs *ConsensusStateHandler) prepareProposal(vr VoteResult) error {
 	cs.Log().Debug().Object("vr", vr).Msg("prepare proposal")
 	acting := cs.suffrage.Acting(vr.Height(), vr.Round())
@@ -434,11 +578,14 @@ func (cs *ConsensusStateHandler) prepareProposal(vr VoteResult) error {
 func (cs *ConsensusStateHandler) propose(vr VoteResult) error {
 	cs.Log().Debug().Object("vr", vr).Msg("proposer is home; propose new proposal")
 
-	proposal, err := cs.proposalMaker.Make(vr.Height(), vr.Round(), cs.homeState.Block().Hash())
-	if err != nil {
-		return err
+	proposal, found := cs.takePipelinedProposal(vr.Height(), vr.Round(), cs.homeState.Block().Hash())
+	if !found {
+		var err error
+		if proposal, err = cs.proposalMaker.Make(vr.Height(), vr.Round(), cs.homeState.Block().Hash()); err != nil {
+			return err
+		}
 	}
-	if err := proposal.Sign(cs.homeState.Home().PrivateKey(), nil); err != nil {
+	if err := proposal.SignAt(cs.homeState.Home().Signer(), nil, cs.homeState.Home().Now()); err != nil {
 		return err
 	}
 
@@ -449,6 +596,111 @@ func (cs *ConsensusStateHandler) propose(vr VoteResult) error {
 	return nil
 }
 
+// pipelineProposal prepares the proposal of the next block in background, if
+// home is the proposer of the next block. The block will be agreed by the
+// ACCEPT stage, so the proposal is not broadcasted until the block is stored.
+func (cs *ConsensusStateHandler) pipelineProposal(block Block) {
+	height := block.Height().Add(1)
+	if !cs.suffrage.Acting(height, Round(0)).Proposer().Equal(cs.homeState.Home()) {
+		return
+	}
+
+	pp := &pipelinedProposal{
+		height:    height,
+		round:     Round(0),
+		lastBlock: block.Hash(),
+		done:      make(chan struct{}),
+	}
+
+	cs.Lock()
+	cs.pipelined = pp
+	cs.Unlock()
+
+	cs.Log().Debug().
+		Str("height", height.String()).
+		Object("last_block", block.Hash()).
+		Msg("start to prepare pipelined proposal")
+
+	go func() {
+		defer close(pp.done)
+
+		pp.proposal, pp.err = cs.makePipelinedProposal(pp, block)
+	}()
+}
+
+// makePipelinedProposal makes the proposal of the next block. The operations
+// of block are still pending in the OperationPools until the block is stored,
+// so they are excluded from the pipelined proposal.
+func (cs *ConsensusStateHandler) makePipelinedProposal(pp *pipelinedProposal, block Block) (Proposal, error) {
+	proposal, err := cs.proposalMaker.Make(pp.height, pp.round, pp.lastBlock)
+	if err != nil {
+		return Proposal{}, err
+	}
+
+	agreed, err := cs.policyKeeper.BlockProposal(block)
+	if err != nil {
+		return Proposal{}, err
+	}
+
+	included := map[hash.Hash]struct{}{}
+	for _, h := range agreed.Transactions() {
+		included[h] = struct{}{}
+	}
+
+	var transactions []hash.Hash
+	for _, h := range proposal.Transactions() {
+		if _, found := included[h]; found {
+			continue
+		}
+
+		transactions = append(transactions, h)
+	}
+
+	if len(transactions) == len(proposal.Transactions()) {
+		return proposal, nil
+	}
+
+	return NewProposal(proposal.Height(), proposal.Round(), proposal.LastBlock(), proposal.Proposer(), transactions)
+}
+
+// takePipelinedProposal returns the pipelined proposal, only when it is for
+// the same height, round and last block. The pipelined proposal is discarded
+// anyway.
+func (cs *ConsensusStateHandler) takePipelinedProposal(height Height, round Round, lastBlock hash.Hash) (
+	Proposal, bool,
+) {
+	cs.Lock()
+	pp := cs.pipelined
+	cs.pipelined = nil
+	cs.Unlock()
+
+	if pp == nil {
+		return Proposal{}, false
+	}
+
+	if !pp.height.Equal(height) || pp.round != round || !pp.lastBlock.Equal(lastBlock) {
+		cs.Log().Debug().
+			Str("height", height.String()).
+			Uint64("round", round.Uint64()).
+			Object("last_block", lastBlock).
+			Object("pipelined_last_block", pp.lastBlock).
+			Msg("pipelined proposal does not match; discarded")
+
+		return Proposal{}, false
+	}
+
+	<-pp.done
+
+	if pp.err != nil {
+		cs.Log().Error().Err(pp.err).Msg("failed to prepare pipelined proposal")
+		return Proposal{}, false
+	}
+
+	cs.Log().Debug().Object("proposal", pp.proposal.Hash()).Msg("pipelined proposal will be used")
+
+	return pp.proposal, true
+}
+
 func (cs *ConsensusStateHandler) startNextRound(vr VoteResult) error {
 	ballot, err := cs.ballotMaker.INIT(
 		cs.homeState.PreviousBlock().Hash(),
@@ -481,7 +733,7 @@ func (cs *ConsensusStateHandler) nextRoundTimer(name string, vr VoteResult) erro
 
 	cs.timer = common.NewCallbackTimer(
 		name,
-		cs.timeoutWaitBallot,
+		cs.policyKeeper.Policy().TimeoutWaitBallot,
 		func(common.Timer) error {
 			return cs.startNextRound(vr)
 		},
@@ -499,15 +751,18 @@ func (cs *ConsensusStateHandler) initFailedTimer(name string, vr VoteResult) err
 		return err
 	}
 
+	// NOTE the timer can be fired after the next activation
+	generation := cs.chanState.activated()
+
 	cs.Lock()
 	defer cs.Unlock()
 
 	cs.timer = common.NewCallbackTimer(
 		name,
-		cs.timeoutWaitINITBallot,
+		cs.policyKeeper.Policy().TimeoutWaitINITBallot,
 		func(t common.Timer) error {
-			cs.chanState <- NewStateContext(node.StateJoining).
-				SetContext("vr", vr)
+			cs.chanState.sendWithGeneration(generation, NewStateContext(node.StateJoining).
+				SetContext("vr", vr))
 			cs.Log().Debug().Msg("failed to get INIT VoteResult; change state to JOINING")
 			return nil
 		},
@@ -9,6 +9,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/hash"
 	"github.com/spikeekips/mitum/network"
 	"github.com/spikeekips/mitum/node"
 	"github.com/spikeekips/mitum/seal"
@@ -18,7 +19,14 @@ type testConsensusStateHandler struct {
 	suite.Suite
 }
 
-func (t *testConsensusStateHandler) handler(suffrage Suffrage, timeoutWaitBallot time.Duration, timeoutWaitINITBallot time.Duration) (*ConsensusStateHandler, func()) {
+// handler makes new ConsensusStateHandler; the policy is changed by the
+// policies before the handler starts.
+func (t *testConsensusStateHandler) handler(
+	suffrage Suffrage,
+	timeoutWaitBallot time.Duration,
+	timeoutWaitINITBallot time.Duration,
+	policies ...func(*Policy),
+) (*ConsensusStateHandler, func()) {
 	home := node.NewRandomHome()
 	lastBlock := NewRandomBlock()
 	nextBlock := NewRandomNextBlock(lastBlock)
@@ -42,9 +50,23 @@ func (t *testConsensusStateHandler) handler(suffrage Suffrage, timeoutWaitBallot
 
 	pv := NewDummyProposalValidator()
 
+	policy := Policy{
+		Threshold:                         67,
+		IntervalBroadcastINITBallotInJoin: time.Second,
+		TimeoutWaitVoteResultInJoin:       time.Second,
+		TimeoutWaitBallot:                 timeoutWaitBallot,
+		TimeoutWaitINITBallot:             timeoutWaitINITBallot,
+	}
+	for _, f := range policies {
+		f(&policy)
+	}
+
+	policyKeeper, err := NewPolicyKeeper(homeState, thr, NewTSealStorage(), policy)
+	t.NoError(err)
+
 	dp := NewDefaultProposalMaker(home, 0)
 	ballotMaker := NewDefaultBallotMaker(home)
-	cs, err := NewConsensusStateHandler(homeState, cm, cn, suffrage, ballotMaker, pv, dp, timeoutWaitBallot, timeoutWaitINITBallot)
+	cs, err := NewConsensusStateHandler(homeState, cm, cn, suffrage, ballotMaker, pv, dp, policyKeeper)
 	t.NoError(err)
 
 	return cs, func() {
@@ -57,12 +79,13 @@ func (t *testConsensusStateHandler) handlerActivated(
 	suffrage Suffrage,
 	timeoutWaitBallot time.Duration,
 	timeoutWaitINITBallot time.Duration,
+	policies ...func(*Policy),
 ) (*ConsensusStateHandler, func(), VoteResult) {
-	cs, closeFunc := t.handler(suffrage, timeoutWaitBallot, timeoutWaitINITBallot)
+	cs, closeFunc := t.handler(suffrage, timeoutWaitBallot, timeoutWaitINITBallot, policies...)
 
 	t.Equal(node.StateConsensus, cs.State())
 
-	_ = cs.SetChanState(make(chan StateContext))
+	_ = cs.SetChanState(make(chan StateContext), nil)
 
 	t.NoError(cs.Start())
 	defer cs.Stop()
@@ -84,6 +107,37 @@ func (t *testConsensusStateHandler) handlerActivated(
 	return cs, closeFunc, vr
 }
 
+// newProposal saves the new proposal into the seal storage of PolicyKeeper, so
+// the block of the proposal can be stored.
+func (t *testConsensusStateHandler) newProposal(
+	cs *ConsensusStateHandler,
+	height Height,
+	round Round,
+	transactions ...hash.Hash,
+) hash.Hash {
+	home := cs.homeState.Home()
+
+	proposal, err := NewProposal(height, round, cs.homeState.Block().Hash(), home.Address(), transactions)
+	t.NoError(err)
+	t.NoError(proposal.Sign(home.PrivateKey(), nil))
+	t.NoError(cs.policyKeeper.sealStorage.Save(proposal))
+
+	return proposal.Hash()
+}
+
+// newTransaction saves the new KeyRotation into the seal storage of
+// PolicyKeeper.
+func (t *testConsensusStateHandler) newTransaction(cs *ConsensusStateHandler) hash.Hash {
+	home := node.NewRandomHome()
+
+	kr, err := NewKeyRotation(home.Address(), node.NewRandomHome().PublicKey(), cs.homeState.Block().Height().Add(10), nil)
+	t.NoError(err)
+	t.NoError(kr.Sign(home.PrivateKey(), nil))
+	t.NoError(cs.policyKeeper.sealStorage.Save(kr))
+
+	return kr.Hash()
+}
+
 func (t *testConsensusStateHandler) newNetwork(home node.Home) *network.ChannelNetwork {
 	return network.NewChannelNetwork(
 		home,
@@ -101,7 +155,7 @@ func (t *testConsensusStateHandler) TestNew() {
 
 	t.Equal(node.StateConsensus, cs.State())
 
-	_ = cs.SetChanState(make(chan StateContext))
+	_ = cs.SetChanState(make(chan StateContext), nil)
 
 	t.NoError(cs.Start())
 	defer cs.Stop()
@@ -135,7 +189,7 @@ func (t *testConsensusStateHandler) TestEmptyPreviousBlock() {
 
 	dp := NewDefaultProposalMaker(home, 0)
 	ballotMaker := NewDefaultBallotMaker(home)
-	_, err := NewConsensusStateHandler(homeState, cm, nil, nil, ballotMaker, nil, dp, time.Second, time.Second)
+	_, err := NewConsensusStateHandler(homeState, cm, nil, nil, ballotMaker, nil, dp, nil)
 	t.Contains(err.Error(), "previous block is empty")
 }
 
@@ -237,6 +291,27 @@ func (t *testConsensusStateHandler) TestReceiveProposalAndNextStages() {
 	}
 }
 
+func (t *testConsensusStateHandler) TestReceiveProposalUnknownTransaction() {
+	cs, closeFunc, vr := t.handlerActivated(nil, time.Second*3, time.Second*3)
+	defer closeFunc()
+
+	cs.compiler.lastINITVoteResult = vr
+
+	home := cs.homeState.Home()
+	proposal, err := NewProposal(
+		vr.Height(),
+		vr.Round(),
+		cs.homeState.Block().Hash(),
+		home.Address(),
+		[]hash.Hash{NewRandomProposalHash()},
+	)
+	t.NoError(err)
+	t.NoError(proposal.Sign(home.PrivateKey(), nil))
+
+	err = cs.ReceiveProposal(proposal)
+	t.Contains(err.Error(), "transaction of proposal not found")
+}
+
 func (t *testConsensusStateHandler) TestProposalTimeoutNextRound() {
 	proposer := node.NewRandomHome()
 	suffrage := NewFixedProposerSuffrage(proposer)
@@ -327,7 +402,7 @@ func (t *testConsensusStateHandler) TestINITBallotTimeoutStateJoining() {
 	cs.compiler.lastINITVoteResult = vr
 
 	chanState := make(chan StateContext)
-	_ = cs.SetChanState(chanState)
+	_ = cs.SetChanState(chanState, nil)
 
 	acceptVR := NewVoteResult(
 		vr.Height(),
@@ -351,6 +426,247 @@ func (t *testConsensusStateHandler) TestINITBallotTimeoutStateJoining() {
 	}
 }
 
+func (t *testConsensusStateHandler) TestALLCONFIRM() {
+	cs, closeFunc, vr := t.handlerActivated(nil, time.Second*3, time.Second*3, func(policy *Policy) {
+		policy.AllConfirm = true
+	})
+	defer closeFunc()
+
+	cs.compiler.lastINITVoteResult = vr
+
+	// NOTE skip proposal
+	select {
+	case <-time.After(time.Millisecond * 50):
+		t.NoError(errors.New("timed out; wait proposal"))
+		return
+	case <-cs.nt.(*network.ChannelNetwork).Reader():
+	}
+
+	lastBlock := cs.homeState.Block()
+	acceptVR := NewVoteResult(
+		vr.Height(),
+		vr.Round(),
+		StageACCEPT,
+	).
+		SetAgreement(Majority).
+		SetBlock(NewRandomBlock().Hash()).
+		SetLastBlock(lastBlock.Hash()).
+		SetProposal(t.newProposal(cs, vr.Height(), vr.Round()))
+
+	t.NoError(cs.ReceiveVoteResult(acceptVR))
+
+	// NOTE ACCEPT majority; block is not stored yet and allconfirm ballot is
+	// broadcasted
+	var allconfirm Ballot
+	select {
+	case <-time.After(time.Millisecond * 50):
+		t.NoError(errors.New("timed out; wait allconfirm ballot"))
+		return
+	case message := <-cs.nt.(*network.ChannelNetwork).Reader():
+		var ok bool
+		allconfirm, ok = message.(Ballot)
+		t.True(ok)
+	}
+
+	t.Equal(StageALLCONFIRM, allconfirm.Stage())
+	t.True(vr.Height().Equal(allconfirm.Height()))
+	t.Equal(vr.Round(), allconfirm.Round())
+	t.True(lastBlock.Hash().Equal(allconfirm.LastBlock()))
+	t.True(lastBlock.Equal(cs.homeState.Block()))
+
+	allconfirmVR := NewVoteResult(
+		allconfirm.Height(),
+		allconfirm.Round(),
+		StageALLCONFIRM,
+	).
+		SetAgreement(Majority).
+		SetBlock(allconfirm.Block()).
+		SetLastBlock(allconfirm.LastBlock()).
+		SetProposal(allconfirm.Proposal())
+
+	t.NoError(cs.ReceiveVoteResult(allconfirmVR))
+
+	// NOTE ALLCONFIRM majority; block is stored and init ballot of next block
+	// is broadcasted
+	t.True(vr.Height().Equal(cs.homeState.Block().Height()))
+	t.True(lastBlock.Equal(cs.homeState.PreviousBlock()))
+
+	select {
+	case <-time.After(time.Millisecond * 50):
+		t.NoError(errors.New("timed out; wait init ballot"))
+		return
+	case message := <-cs.nt.(*network.ChannelNetwork).Reader():
+		ballot, ok := message.(Ballot)
+		t.True(ok)
+
+		t.Equal(StageINIT, ballot.Stage())
+		t.True(vr.Height().Add(1).Equal(ballot.Height()))
+		t.Equal(Round(0), ballot.Round())
+		t.True(cs.homeState.Block().Hash().Equal(ballot.Block()))
+		t.True(lastBlock.Hash().Equal(ballot.LastBlock()))
+	}
+}
+
+func (t *testConsensusStateHandler) TestALLCONFIRMMissingProposal() {
+	cs, closeFunc := t.handler(nil, time.Second*3, time.Second*3)
+	defer closeFunc()
+
+	chanState := make(chan StateContext, 1)
+	_ = cs.SetChanState(chanState, nil)
+	_ = cs.SetSealFetcher(NewSealFetcher(cs.homeState.Home(), cs.nt, cs.policyKeeper.sealStorage, cs.suffrage))
+
+	t.NoError(cs.Start())
+
+	// NOTE the proposal of block is not found in any node
+	lastBlock := cs.homeState.Block()
+	block := NewRandomNextBlock(lastBlock)
+
+	vr := NewVoteResult(block.Height(), block.Round(), StageALLCONFIRM).
+		SetAgreement(Majority).
+		SetBlock(block.Hash()).
+		SetLastBlock(lastBlock.Hash()).
+		SetProposal(block.Proposal())
+
+	err := cs.gotALLCONFIRMMajority(block, vr)
+	t.True(xerrors.Is(err, SealNotFoundError))
+	t.True(lastBlock.Equal(cs.homeState.Block()))
+
+	select {
+	case <-time.After(time.Millisecond * 50):
+		t.NoError(errors.New("timed out; wait state context"))
+	case sct := <-chanState:
+		t.Equal(node.StateJoining, sct.State())
+	}
+}
+
+func (t *testConsensusStateHandler) pipelineProposal(policy *Policy) {
+	policy.PipelineProposal = true
+}
+
+func (t *testConsensusStateHandler) TestPipelineProposal() {
+	cs, closeFunc, vr := t.handlerActivated(nil, time.Second*3, time.Second*3, t.pipelineProposal)
+	defer closeFunc()
+
+	cs.compiler.lastINITVoteResult = vr
+
+	// NOTE skip proposal
+	select {
+	case <-time.After(time.Millisecond * 50):
+		t.NoError(errors.New("timed out; wait proposal"))
+		return
+	case <-cs.nt.(*network.ChannelNetwork).Reader():
+	}
+
+	proposal := t.newProposal(cs, vr.Height(), vr.Round())
+	block, err := cs.proposalValidator.NewBlock(vr.Height(), vr.Round(), proposal)
+	t.NoError(err)
+
+	signVR := NewVoteResult(
+		vr.Height(),
+		vr.Round(),
+		StageSIGN,
+	).
+		SetAgreement(Majority).
+		SetBlock(block.Hash()).
+		SetLastBlock(cs.homeState.Block().Hash()).
+		SetProposal(proposal)
+
+	t.NoError(cs.ReceiveVoteResult(signVR))
+
+	// NOTE only accept ballot is broadcasted; pipelined proposal is not
+	// broadcasted
+	select {
+	case <-time.After(time.Millisecond * 50):
+		t.NoError(errors.New("timed out; wait accept ballot"))
+		return
+	case message := <-cs.nt.(*network.ChannelNetwork).Reader():
+		ballot, ok := message.(Ballot)
+		t.True(ok)
+		t.Equal(StageACCEPT, ballot.Stage())
+	}
+
+	cs.RLock()
+	pp := cs.pipelined
+	cs.RUnlock()
+	t.NotNil(pp)
+	<-pp.done
+	t.True(block.Height().Add(1).Equal(pp.proposal.Height()))
+	t.True(block.Hash().Equal(pp.proposal.LastBlock()))
+
+	// NOTE init majority of next block; the pipelined proposal is broadcasted
+	initVR := NewVoteResult(
+		block.Height().Add(1),
+		Round(0),
+		StageINIT,
+	).
+		SetAgreement(Majority).
+		SetBlock(block.Hash()).
+		SetLastBlock(cs.homeState.Block().Hash()).
+		SetLastRound(block.Round()).
+		SetProposal(proposal)
+
+	t.NoError(cs.ReceiveVoteResult(initVR))
+	t.True(block.Equal(cs.homeState.Block()))
+
+	select {
+	case <-time.After(time.Millisecond * 50):
+		t.NoError(errors.New("timed out; wait proposal"))
+		return
+	case message := <-cs.nt.(*network.ChannelNetwork).Reader():
+		proposal, ok := message.(Proposal)
+		t.True(ok)
+		t.True(pp.proposal.Body().Hash().Equal(proposal.Body().Hash()))
+	}
+}
+
+func (t *testConsensusStateHandler) TestPipelineProposalExcludesOperations() {
+	cs, closeFunc := t.handler(nil, time.Second*3, time.Second*3, t.pipelineProposal)
+	defer closeFunc()
+
+	// NOTE the operations of agreed proposal are still pending
+	agreed := t.newTransaction(cs)
+	pending := t.newTransaction(cs)
+	cs.proposalMaker = NewDefaultProposalMaker(
+		cs.homeState.Home(), 0, testOperationPool{agreed, pending},
+	)
+
+	height := cs.homeState.Block().Height().Add(1)
+	proposal := t.newProposal(cs, height, Round(0), agreed)
+	block, err := cs.proposalValidator.NewBlock(height, Round(0), proposal)
+	t.NoError(err)
+
+	cs.pipelineProposal(block)
+
+	pp, found := cs.takePipelinedProposal(block.Height().Add(1), Round(0), block.Hash())
+	t.True(found)
+	t.Equal(1, len(pp.Transactions()))
+	t.True(pending.Equal(pp.Transactions()[0]))
+}
+
+func (t *testConsensusStateHandler) TestPipelineProposalDiscarded() {
+	cs, closeFunc, vr := t.handlerActivated(nil, time.Second*3, time.Second*3, t.pipelineProposal)
+	defer closeFunc()
+
+	block, err := cs.proposalValidator.NewBlock(vr.Height(), vr.Round(), t.newProposal(cs, vr.Height(), vr.Round()))
+	t.NoError(err)
+
+	cs.pipelineProposal(block)
+
+	// NOTE different block
+	_, found := cs.takePipelinedProposal(block.Height().Add(1), Round(0), NewRandomBlockHash())
+	t.False(found)
+
+	cs.RLock()
+	defer cs.RUnlock()
+	t.Nil(cs.pipelined)
+}
+
+type testOperationPool []hash.Hash
+
+func (op testOperationPool) Pending() []hash.Hash {
+	return op
+}
+
 func TestConsensusStateHandler(t *testing.T) {
 	suite.Run(t, new(testConsensusStateHandler))
 }
@@ -5,9 +5,13 @@ import "github.com/spikeekips/mitum/common"
 const (
 	InvalidStageErrorCode common.ErrorCode = iota + 1
 	InvalidBallotErrorCode
+	ForkDetectedErrorCode
+	SealNotFoundErrorCode
 )
 
 var (
 	InvalidStageError  = common.NewError("isaac", InvalidStageErrorCode, "invalid stage")
 	InvalidBallotError = common.NewError("isaac", InvalidBallotErrorCode, "invalid ballot")
+	ForkDetectedError  = common.NewError("isaac", ForkDetectedErrorCode, "fork detected")
+	SealNotFoundError  = common.NewError("isaac", SealNotFoundErrorCode, "seal not found")
 )
@@ -0,0 +1,378 @@
+package isaac
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/hash"
+)
+
+// ForkEvidence is the record of the detected fork; 2 different blocks are
+// agreed for the same height.
+type ForkEvidence struct {
+	height   Height
+	reason   string
+	expected hash.Hash // already accepted or stored block
+	found    hash.Hash // conflicting block
+	vr       VoteResult
+	detected time.Time
+}
+
+func (fe ForkEvidence) Height() Height {
+	return fe.height
+}
+
+func (fe ForkEvidence) Reason() string {
+	return fe.reason
+}
+
+func (fe ForkEvidence) Expected() hash.Hash {
+	return fe.expected
+}
+
+func (fe ForkEvidence) Found() hash.Hash {
+	return fe.found
+}
+
+// VoteResult is the VoteResult, which carries the conflicting block.
+func (fe ForkEvidence) VoteResult() VoteResult {
+	return fe.vr
+}
+
+func (fe ForkEvidence) Detected() time.Time {
+	return fe.detected
+}
+
+func (fe ForkEvidence) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"height":   fe.height,
+		"reason":   fe.reason,
+		"expected": fe.expected,
+		"found":    fe.found,
+		"vr":       fe.vr,
+		"detected": fe.detected,
+	})
+}
+
+func (fe ForkEvidence) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("height", fe.height.String())
+	e.Str("reason", fe.reason)
+	e.Object("expected", fe.expected)
+	e.Object("found", fe.found)
+	e.Object("vr", fe.vr)
+	e.Time("detected", fe.detected)
+}
+
+func (fe ForkEvidence) String() string {
+	b, _ := json.Marshal(fe) // nolint
+	return string(b)
+}
+
+// ForkDetector compares the blocks of the majority VoteResults with the
+// previously agreed blocks and the stored blocks of HomeState. Once fork is
+// detected, ForkDetector keeps the first ForkEvidence and the node should be
+// halted.
+// * ACCEPT and ALLCONFIRM VoteResult accepts `VoteResult.Block()` for
+// `VoteResult.Height()` and `VoteResult.Round()`; the accepted block is not
+// final, because the next round can agree the different block for the same
+// height
+// * INIT VoteResult confirms `VoteResult.Block()` for `VoteResult.Height() - 1`
+// and `VoteResult.LastBlock()` for `VoteResult.Height() - 2`; the confirmed
+// block is final like the stored block
+//
+// With Suffrage and Threshold, the blocks claimed by the ballots of peers are
+// also compared; see CheckBallot().
+type ForkDetector struct {
+	sync.RWMutex
+	homeState *HomeState
+	suffrage  Suffrage
+	threshold *Threshold
+	accepted  map[string] /* Height.String() + Round.String() */ acceptedBlock
+	confirmed map[string] /* Height.String() */ acceptedBlock
+	ballots   map[string] /* node.Address.String() + Stage.String() */ Ballot
+	evidence  *ForkEvidence
+}
+
+type acceptedBlock struct {
+	height Height
+	round  Round
+	block  hash.Hash
+	final  bool
+}
+
+func NewForkDetector(homeState *HomeState) *ForkDetector {
+	return &ForkDetector{
+		homeState: homeState,
+		accepted:  map[string]acceptedBlock{},
+		confirmed: map[string]acceptedBlock{},
+		ballots:   map[string]Ballot{},
+	}
+}
+
+func acceptedKey(height Height, round Round) string {
+	return height.String() + "-" + round.String()
+}
+
+// SetSuffrage sets the Suffrage and Threshold to check the ballots from peers;
+// without them, CheckBallot() does nothing.
+func (fd *ForkDetector) SetSuffrage(suffrage Suffrage, threshold *Threshold) *ForkDetector {
+	fd.Lock()
+	defer fd.Unlock()
+
+	fd.suffrage = suffrage
+	fd.threshold = threshold
+
+	return fd
+}
+
+// Evidence returns the ForkEvidence; if fork is not detected, false is
+// returned.
+func (fd *ForkDetector) Evidence() (ForkEvidence, bool) {
+	fd.RLock()
+	defer fd.RUnlock()
+
+	if fd.evidence == nil {
+		return ForkEvidence{}, false
+	}
+
+	return *fd.evidence, true
+}
+
+// CheckVoteResult checks the blocks of majority VoteResult.
+func (fd *ForkDetector) CheckVoteResult(vr VoteResult) error {
+	if !vr.GotMajority() {
+		return nil
+	}
+
+	fd.Lock()
+	defer fd.Unlock()
+
+	if fd.evidence != nil {
+		return ForkDetectedError.Newf("%v", fd.evidence)
+	}
+
+	switch vr.Stage() {
+	case StageACCEPT, StageALLCONFIRM:
+		return fd.check(acceptedBlock{height: vr.Height(), round: vr.Round(), block: vr.Block()}, vr)
+	case StageINIT:
+		if height, ok := vr.Height().SubOK(1); ok {
+			if err := fd.check(acceptedBlock{height: height, block: vr.Block(), final: true}, vr); err != nil {
+				return err
+			}
+		}
+
+		if height, ok := vr.Height().SubOK(2); ok {
+			if err := fd.check(acceptedBlock{height: height, block: vr.LastBlock(), final: true}, vr); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// CheckBallot checks the blocks, which are claimed by the ballots from peers.
+// The ballots, which Compiler does not vote like the ballots of the lower
+// height, are also checked, so the peers of the other fork can be found. The
+// latest ballot of each node and stage is kept and when the same block is
+// claimed by the nodes over threshold, the block is regarded as agreed.
+// * INIT ballot claims the stored blocks of node, `Ballot.Block()` for
+// `Ballot.Height() - 1` and `Ballot.LastBlock()` for `Ballot.Height() - 2`
+// * ACCEPT ballot claims `Ballot.Block()` for `Ballot.Height()`
+func (fd *ForkDetector) CheckBallot(ballot Ballot) error {
+	switch ballot.Stage() {
+	case StageINIT, StageACCEPT:
+	default:
+		return nil
+	}
+
+	fd.Lock()
+	defer fd.Unlock()
+
+	if fd.evidence != nil {
+		return ForkDetectedError.Newf("%v", fd.evidence)
+	}
+
+	if fd.suffrage == nil || fd.threshold == nil {
+		return nil
+	}
+
+	// NOTE the ballots of unknown nodes are ignored
+	if !fd.suffrage.Exists(ballot.Height().Sub(1), ballot.Node()) {
+		return nil
+	} else if err := VerifySigner(fd.suffrage, ballot.Node(), ballot.Height(), ballot.Signer()); err != nil {
+		return nil
+	}
+
+	key := ballot.Node().String() + ballot.Stage().String()
+	if last, found := fd.ballots[key]; found && last.Height().Cmp(ballot.Height()) > 0 {
+		return nil
+	}
+	fd.ballots[key] = ballot
+
+	_, threshold := fd.threshold.Get(ballot.Stage())
+
+	for _, c := range claimedBlocks(ballot) {
+		if c.block.Empty() || fd.countClaims(c) < threshold {
+			continue
+		}
+
+		vr := NewVoteResult(ballot.Height(), ballot.Round(), ballot.Stage()).
+			SetAgreement(Majority).
+			SetProposal(ballot.Proposal()).
+			SetBlock(ballot.Block()).
+			SetLastBlock(ballot.LastBlock()).
+			SetLastRound(ballot.LastRound())
+
+		if err := fd.check(c, vr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckStored compares the stored blocks of HomeState with the confirmed and
+// accepted blocks.
+func (fd *ForkDetector) CheckStored() error {
+	fd.Lock()
+	defer fd.Unlock()
+
+	if fd.evidence != nil {
+		return ForkDetectedError.Newf("%v", fd.evidence)
+	}
+
+	for _, block := range []Block{fd.homeState.PreviousBlock(), fd.homeState.Block()} {
+		if block.Empty() {
+			continue
+		}
+
+		if confirmed, found := fd.confirmed[block.Height().String()]; found && !confirmed.block.Equal(block.Hash()) {
+			return fd.detected(
+				block.Height(), "stored block is different from confirmed", confirmed.block, block.Hash(), VoteResult{},
+			)
+		}
+
+		accepted, found := fd.accepted[acceptedKey(block.Height(), block.Round())]
+		if !found || accepted.block.Equal(block.Hash()) {
+			continue
+		}
+
+		return fd.detected(
+			block.Height(), "stored block is different from accepted", accepted.block, block.Hash(), VoteResult{},
+		)
+	}
+
+	fd.tidy()
+
+	return nil
+}
+
+// check compares the agreed block with the stored and confirmed blocks of the
+// same height. The accepted block is only compared with the accepted block of
+// the same round, because the later round can agree the different block.
+func (fd *ForkDetector) check(c acceptedBlock, vr VoteResult) error {
+	height, block := c.height, c.block
+	if block.Empty() {
+		return nil
+	}
+
+	for _, stored := range []Block{fd.homeState.PreviousBlock(), fd.homeState.Block()} {
+		if stored.Empty() || !stored.Height().Equal(height) {
+			continue
+		}
+
+		if !stored.Hash().Equal(block) {
+			return fd.detected(height, "agreed block is different from stored", stored.Hash(), block, vr)
+		}
+	}
+
+	if confirmed, found := fd.confirmed[height.String()]; found && !confirmed.block.Equal(block) {
+		return fd.detected(height, "agreed block is different from confirmed", confirmed.block, block, vr)
+	}
+
+	if c.final {
+		if _, found := fd.confirmed[height.String()]; !found {
+			fd.confirmed[height.String()] = c
+		}
+
+		return nil
+	}
+
+	key := acceptedKey(height, c.round)
+	if accepted, found := fd.accepted[key]; !found {
+		fd.accepted[key] = c
+	} else if !accepted.block.Equal(block) {
+		return fd.detected(height, "agreed block is different from accepted", accepted.block, block, vr)
+	}
+
+	return nil
+}
+
+// countClaims returns the number of nodes, which claim the same block.
+func (fd *ForkDetector) countClaims(c acceptedBlock) uint {
+	nodes := map[string]struct{}{}
+	for _, ballot := range fd.ballots {
+		for _, b := range claimedBlocks(ballot) {
+			if b.height.Equal(c.height) && b.block.Equal(c.block) {
+				nodes[ballot.Node().String()] = struct{}{}
+			}
+		}
+	}
+
+	return uint(len(nodes))
+}
+
+func claimedBlocks(ballot Ballot) []acceptedBlock {
+	switch ballot.Stage() {
+	case StageACCEPT:
+		return []acceptedBlock{{height: ballot.Height(), round: ballot.Round(), block: ballot.Block()}}
+	case StageINIT:
+		var claimed []acceptedBlock
+		if height, ok := ballot.Height().SubOK(1); ok {
+			claimed = append(claimed, acceptedBlock{height: height, block: ballot.Block(), final: true})
+		}
+		if height, ok := ballot.Height().SubOK(2); ok {
+			claimed = append(claimed, acceptedBlock{height: height, block: ballot.LastBlock(), final: true})
+		}
+
+		return claimed
+	default:
+		return nil
+	}
+}
+
+func (fd *ForkDetector) detected(height Height, reason string, expected, found hash.Hash, vr VoteResult) error {
+	fd.evidence = &ForkEvidence{
+		height:   height,
+		reason:   reason,
+		expected: expected,
+		found:    found,
+		vr:       vr,
+		detected: common.Now().Time,
+	}
+
+	return ForkDetectedError.Newf("%v", fd.evidence)
+}
+
+// tidy removes the accepted and confirmed blocks, which are lower than the
+// previous block of HomeState; they are already compared with the stored
+// blocks.
+func (fd *ForkDetector) tidy() {
+	previous := fd.homeState.PreviousBlock()
+	if previous.Empty() {
+		return
+	}
+
+	for _, m := range []map[string]acceptedBlock{fd.accepted, fd.confirmed} {
+		for k, a := range m {
+			if a.height.Cmp(previous.Height()) < 0 {
+				delete(m, k)
+			}
+		}
+	}
+}
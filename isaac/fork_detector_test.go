@@ -0,0 +1,342 @@
+package isaac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/node"
+)
+
+type testForkDetector struct {
+	suite.Suite
+}
+
+func (t *testForkDetector) newHomeState() *HomeState {
+	lastBlock := NewRandomBlock()
+	nextBlock := NewRandomNextBlock(lastBlock)
+
+	return NewHomeState(node.NewRandomHome(), lastBlock).SetBlock(nextBlock)
+}
+
+func (t *testForkDetector) acceptVR(homeState *HomeState) VoteResult {
+	return NewVoteResult(
+		homeState.Block().Height().Add(1),
+		Round(0),
+		StageACCEPT,
+	).
+		SetAgreement(Majority).
+		SetBlock(NewRandomBlockHash()).
+		SetLastBlock(homeState.Block().Hash()).
+		SetProposal(NewRandomProposalHash())
+}
+
+func (t *testForkDetector) TestNoFork() {
+	homeState := t.newHomeState()
+	fd := NewForkDetector(homeState)
+
+	vr := t.acceptVR(homeState)
+	t.NoError(fd.CheckVoteResult(vr))
+
+	// NOTE same block by next round
+	t.NoError(fd.CheckVoteResult(
+		NewVoteResult(vr.Height(), vr.Round()+1, StageACCEPT).
+			SetAgreement(Majority).
+			SetBlock(vr.Block()).
+			SetLastBlock(vr.LastBlock()).
+			SetProposal(vr.Proposal()),
+	))
+
+	// NOTE init of next block carries the accepted block
+	initVR := NewVoteResult(
+		vr.Height().Add(1),
+		Round(0),
+		StageINIT,
+	).
+		SetAgreement(Majority).
+		SetBlock(vr.Block()).
+		SetLastBlock(homeState.Block().Hash()).
+		SetProposal(vr.Proposal())
+	t.NoError(fd.CheckVoteResult(initVR))
+	t.NoError(fd.CheckStored())
+
+	_, found := fd.Evidence()
+	t.False(found)
+}
+
+func (t *testForkDetector) TestNotMajority() {
+	homeState := t.newHomeState()
+	fd := NewForkDetector(homeState)
+
+	vr := t.acceptVR(homeState)
+	t.NoError(fd.CheckVoteResult(vr))
+	t.NoError(fd.CheckVoteResult(t.acceptVR(homeState).SetAgreement(Draw)))
+}
+
+func (t *testForkDetector) TestDifferentACCEPT() {
+	homeState := t.newHomeState()
+	fd := NewForkDetector(homeState)
+
+	vr := t.acceptVR(homeState)
+	t.NoError(fd.CheckVoteResult(vr))
+
+	another := t.acceptVR(homeState)
+	err := fd.CheckVoteResult(another)
+	t.True(xerrors.Is(err, ForkDetectedError))
+
+	evidence, found := fd.Evidence()
+	t.True(found)
+	t.True(vr.Height().Equal(evidence.Height()))
+	t.True(vr.Block().Equal(evidence.Expected()))
+	t.True(another.Block().Equal(evidence.Found()))
+
+	// NOTE once detected, all the VoteResult is refused
+	err = fd.CheckVoteResult(vr)
+	t.True(xerrors.Is(err, ForkDetectedError))
+}
+
+func (t *testForkDetector) TestNextRoundSameHeight() {
+	homeState := t.newHomeState()
+	fd := NewForkDetector(homeState)
+
+	vr := t.acceptVR(homeState)
+	t.NoError(fd.CheckVoteResult(vr))
+
+	// NOTE after the timeout, the next round of same height agrees the
+	// different block
+	next := NewVoteResult(vr.Height(), vr.Round()+1, StageACCEPT).
+		SetAgreement(Majority).
+		SetBlock(NewRandomBlockHash()).
+		SetLastBlock(vr.LastBlock()).
+		SetProposal(NewRandomProposalHash())
+	t.NoError(fd.CheckVoteResult(next))
+
+	initVR := NewVoteResult(vr.Height().Add(1), Round(0), StageINIT).
+		SetAgreement(Majority).
+		SetBlock(next.Block()).
+		SetLastBlock(homeState.Block().Hash()).
+		SetProposal(next.Proposal())
+	t.NoError(fd.CheckVoteResult(initVR))
+
+	_, found := fd.Evidence()
+	t.False(found)
+}
+
+func (t *testForkDetector) TestDifferentFromConfirmed() {
+	homeState := t.newHomeState()
+	fd := NewForkDetector(homeState)
+
+	height := homeState.Block().Height().Add(1)
+	confirmed := NewRandomBlockHash()
+
+	initVR := NewVoteResult(height.Add(1), Round(0), StageINIT).
+		SetAgreement(Majority).
+		SetBlock(confirmed).
+		SetLastBlock(homeState.Block().Hash()).
+		SetProposal(NewRandomProposalHash())
+	t.NoError(fd.CheckVoteResult(initVR))
+
+	// NOTE the confirmed block is final for any round
+	vr := NewVoteResult(height, Round(3), StageACCEPT).
+		SetAgreement(Majority).
+		SetBlock(NewRandomBlockHash()).
+		SetLastBlock(homeState.Block().Hash()).
+		SetProposal(NewRandomProposalHash())
+
+	err := fd.CheckVoteResult(vr)
+	t.True(xerrors.Is(err, ForkDetectedError))
+
+	evidence, found := fd.Evidence()
+	t.True(found)
+	t.True(confirmed.Equal(evidence.Expected()))
+	t.Contains(evidence.Reason(), "confirmed")
+}
+
+func (t *testForkDetector) TestDifferentFromStored() {
+	homeState := t.newHomeState()
+	fd := NewForkDetector(homeState)
+
+	initVR := NewVoteResult(
+		homeState.Block().Height().Add(1),
+		Round(0),
+		StageINIT,
+	).
+		SetAgreement(Majority).
+		SetBlock(NewRandomBlockHash()).
+		SetLastBlock(homeState.PreviousBlock().Hash()).
+		SetProposal(NewRandomProposalHash())
+
+	err := fd.CheckVoteResult(initVR)
+	t.True(xerrors.Is(err, ForkDetectedError))
+
+	evidence, found := fd.Evidence()
+	t.True(found)
+	t.True(homeState.Block().Height().Equal(evidence.Height()))
+	t.True(homeState.Block().Hash().Equal(evidence.Expected()))
+}
+
+func (t *testForkDetector) TestStoredDifferentFromAccepted() {
+	homeState := t.newHomeState()
+	fd := NewForkDetector(homeState)
+
+	vr := t.acceptVR(homeState)
+	t.NoError(fd.CheckVoteResult(vr))
+
+	// NOTE different block is stored
+	block, err := NewBlock(vr.Height(), vr.Round(), NewRandomProposalHash())
+	t.NoError(err)
+	_ = homeState.SetBlock(block)
+
+	err = fd.CheckStored()
+	t.True(xerrors.Is(err, ForkDetectedError))
+
+	evidence, found := fd.Evidence()
+	t.True(found)
+	t.True(vr.Block().Equal(evidence.Expected()))
+	t.True(block.Hash().Equal(evidence.Found()))
+}
+
+func (t *testForkDetector) newSuffrage(n int) ([]node.Home, Suffrage, *Threshold) {
+	var homes []node.Home
+	var nodes []node.Node
+	for i := 0; i < n; i++ {
+		home := node.NewRandomHome()
+		homes = append(homes, home)
+		nodes = append(nodes, home)
+	}
+
+	threshold, err := NewThreshold(uint(n), 67)
+	t.NoError(err)
+
+	return homes, NewFixedProposerSuffrage(nodes[0], nodes...), threshold
+}
+
+func (t *testForkDetector) initBallot(home node.Home, height Height, block, lastBlock hash.Hash) Ballot {
+	ballot, err := NewINITBallot(
+		home.Address(),
+		lastBlock,
+		Round(0),
+		height,
+		block,
+		Round(0),
+		NewRandomProposalHash(),
+	)
+	t.NoError(err)
+	t.NoError(ballot.Sign(home.PrivateKey(), nil))
+
+	return ballot
+}
+
+func (t *testForkDetector) TestBallotsNotOverThreshold() {
+	homeState := t.newHomeState()
+	homes, suffrage, threshold := t.newSuffrage(4)
+	fd := NewForkDetector(homeState).SetSuffrage(suffrage, threshold)
+
+	// NOTE 2 nodes claim the different stored block
+	block := NewRandomBlockHash()
+	for _, home := range homes[:2] {
+		ballot := t.initBallot(home, homeState.Block().Height().Add(1), block, homeState.PreviousBlock().Hash())
+		t.NoError(fd.CheckBallot(ballot))
+	}
+
+	_, found := fd.Evidence()
+	t.False(found)
+}
+
+func (t *testForkDetector) TestBallotsDifferentFromStored() {
+	homeState := t.newHomeState()
+	homes, suffrage, threshold := t.newSuffrage(4)
+	fd := NewForkDetector(homeState).SetSuffrage(suffrage, threshold)
+
+	// NOTE the peers of the other fork send the ballots of the lower height,
+	// which are not voted
+	block := NewRandomBlockHash()
+	var err error
+	for _, home := range homes[:3] {
+		ballot := t.initBallot(home, homeState.Block().Height(), block, NewRandomBlockHash())
+		if err = fd.CheckBallot(ballot); err != nil {
+			break
+		}
+	}
+	t.True(xerrors.Is(err, ForkDetectedError))
+
+	evidence, found := fd.Evidence()
+	t.True(found)
+	t.True(homeState.PreviousBlock().Height().Equal(evidence.Height()))
+	t.True(homeState.PreviousBlock().Hash().Equal(evidence.Expected()))
+	t.True(block.Equal(evidence.Found()))
+}
+
+func (t *testForkDetector) TestBallotsDifferentFromAccepted() {
+	homeState := t.newHomeState()
+	homes, suffrage, threshold := t.newSuffrage(4)
+	fd := NewForkDetector(homeState).SetSuffrage(suffrage, threshold)
+
+	vr := t.acceptVR(homeState)
+	t.NoError(fd.CheckVoteResult(vr))
+
+	var err error
+	for _, home := range homes[:3] {
+		ballot, e := NewACCEPTBallot(
+			home.Address(),
+			vr.LastBlock(),
+			Round(0),
+			vr.Height(),
+			NewRandomBlockHash(),
+			vr.Round()+1,
+			NewRandomProposalHash(),
+		)
+		t.NoError(e)
+		t.NoError(ballot.Sign(home.PrivateKey(), nil))
+
+		// NOTE each node claims the different block
+		t.NoError(fd.CheckBallot(ballot))
+	}
+
+	// NOTE the different block is claimed for the same round
+	another := NewRandomBlockHash()
+	for _, home := range homes[:3] {
+		ballot, e := NewACCEPTBallot(
+			home.Address(),
+			vr.LastBlock(),
+			Round(0),
+			vr.Height(),
+			another,
+			vr.Round(),
+			NewRandomProposalHash(),
+		)
+		t.NoError(e)
+		t.NoError(ballot.Sign(home.PrivateKey(), nil))
+
+		if err = fd.CheckBallot(ballot); err != nil {
+			break
+		}
+	}
+	t.True(xerrors.Is(err, ForkDetectedError))
+
+	evidence, found := fd.Evidence()
+	t.True(found)
+	t.True(vr.Block().Equal(evidence.Expected()))
+	t.True(another.Equal(evidence.Found()))
+}
+
+func (t *testForkDetector) TestBallotsUnknownNode() {
+	homeState := t.newHomeState()
+	_, suffrage, threshold := t.newSuffrage(4)
+	fd := NewForkDetector(homeState).SetSuffrage(suffrage, threshold)
+
+	block := NewRandomBlockHash()
+	for i := 0; i < 4; i++ {
+		ballot := t.initBallot(node.NewRandomHome(), homeState.Block().Height(), block, NewRandomBlockHash())
+		t.NoError(fd.CheckBallot(ballot))
+	}
+
+	_, found := fd.Evidence()
+	t.False(found)
+}
+
+func TestForkDetector(t *testing.T) {
+	suite.Run(t, new(testForkDetector))
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/suite"
 
+	"github.com/spikeekips/mitum/hash"
 	"github.com/spikeekips/mitum/node"
 	"github.com/spikeekips/mitum/seal"
 )
@@ -50,6 +51,33 @@ func (t *testINITBallotBody) TestNew() {
 	t.Equal(BallotType, seal.Type())
 }
 
+func (t *testINITBallotBody) TestHashAlgorithm() {
+	t.NoError(hash.DefaultAlgorithms.Set(BallotHashHint, hash.Blake2b256))
+	defer hash.DefaultAlgorithms.Reset(hash.DoubleSHA256)
+
+	home := node.NewRandomHome()
+	lastBlock := NewRandomBlock()
+	nextBlock := NewRandomNextBlock(lastBlock)
+
+	ballot, err := NewINITBallot(
+		home.Address(),
+		lastBlock.Hash(),
+		nextBlock.Round(),
+		nextBlock.Height().Add(1),
+		nextBlock.Hash(),
+		Round(1),
+		nextBlock.Proposal(),
+	)
+	t.NoError(err)
+	t.Equal(hash.Blake2b256, ballot.Body().Hash().Algorithm())
+
+	t.NoError(ballot.Sign(home.PrivateKey(), nil))
+
+	// NOTE the ballot is verified by it's recorded algorithm
+	hash.DefaultAlgorithms.Reset(hash.DoubleSHA256)
+	t.NoError(ballot.IsValid())
+}
+
 func TestINITBallotBody(t *testing.T) {
 	suite.Run(t, new(testINITBallotBody))
 }
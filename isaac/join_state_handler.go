@@ -28,19 +28,19 @@ import (
 type JoinStateHandler struct {
 	sync.RWMutex
 	*common.Logger
-	homeState                   *HomeState
-	compiler                    *Compiler
-	nt                          network.Network
-	suffrage                    Suffrage
-	ballotMaker                 BallotMaker
-	proposalValidator           ProposalValidator
-	intervalBroadcastINITBallot time.Duration
-	timeoutWaitVoteResult       time.Duration
-	chanState                   chan StateContext
-	started                     bool
-	timer                       *common.CallbackTimer
-	proposalChecker             *common.ChainChecker
-	voteResultChecker           *common.ChainChecker
+	homeState         *HomeState
+	compiler          *Compiler
+	nt                network.Network
+	suffrage          Suffrage
+	ballotMaker       BallotMaker
+	proposalValidator ProposalValidator
+	policyKeeper      *PolicyKeeper
+	chanState         stateChannel
+	started           bool
+	timer             *common.CallbackTimer
+	proposalChecker   *common.ChainChecker
+	voteResultChecker *common.ChainChecker
+	sealFetcher       *SealFetcher
 }
 
 func NewJoinStateHandler(
@@ -50,8 +50,7 @@ func NewJoinStateHandler(
 	suffrage Suffrage,
 	ballotMaker BallotMaker,
 	proposalValidator ProposalValidator,
-	intervalBroadcastINITBallot time.Duration,
-	timeoutWaitVoteResult time.Duration,
+	policyKeeper *PolicyKeeper,
 ) (*JoinStateHandler, error) {
 	if homeState.PreviousBlock().Empty() {
 		return nil, xerrors.Errorf("previous block is empty")
@@ -61,26 +60,30 @@ func NewJoinStateHandler(
 		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
 			return c.Str("module", "s.h.join")
 		}),
-		homeState:                   homeState,
-		compiler:                    compiler,
-		nt:                          nt,
-		suffrage:                    suffrage,
-		ballotMaker:                 ballotMaker,
-		proposalValidator:           proposalValidator,
-		intervalBroadcastINITBallot: intervalBroadcastINITBallot,
-		timeoutWaitVoteResult:       timeoutWaitVoteResult,
-		proposalChecker:             NewProposalCheckerJoin(homeState, suffrage),
-		voteResultChecker:           NewJoinVoteResultChecker(homeState),
+		homeState:         homeState,
+		compiler:          compiler,
+		nt:                nt,
+		suffrage:          suffrage,
+		ballotMaker:       ballotMaker,
+		proposalValidator: proposalValidator,
+		policyKeeper:      policyKeeper,
+		proposalChecker:   NewProposalCheckerJoin(homeState, suffrage, policyKeeper),
+		voteResultChecker: NewJoinVoteResultChecker(homeState),
 	}, nil
 }
 
 func (js *JoinStateHandler) Start() error {
-	if js.intervalBroadcastINITBallot < time.Second*2 {
-		js.Log().Warn().Dur("interval", js.intervalBroadcastINITBallot).Msg("intervalBroadcastINITBallot is too short")
+	policy := js.policyKeeper.Policy()
+	if policy.IntervalBroadcastINITBallotInJoin < time.Second*2 {
+		js.Log().Warn().
+			Dur("interval", policy.IntervalBroadcastINITBallotInJoin).
+			Msg("IntervalBroadcastINITBallotInJoin is too short")
 	}
 
-	if js.timeoutWaitVoteResult <= js.intervalBroadcastINITBallot {
-		js.Log().Warn().Dur("timeout", js.timeoutWaitVoteResult).Msg("timeoutWaitVoteResult is too short")
+	if policy.TimeoutWaitVoteResultInJoin <= policy.IntervalBroadcastINITBallotInJoin {
+		js.Log().Warn().
+			Dur("timeout", policy.TimeoutWaitVoteResultInJoin).
+			Msg("TimeoutWaitVoteResultInJoin is too short")
 	}
 
 	_ = js.Stop() // nolint
@@ -111,17 +114,21 @@ func (js *JoinStateHandler) IsStopped() bool {
 	return !js.started
 }
 
-func (js *JoinStateHandler) Activate(StateContext) error {
+func (js *JoinStateHandler) Activate(sct StateContext) error {
 	_ = js.stopTimer() // nolint
 
+	js.chanState.activate(sct)
+
 	js.Lock()
 	defer js.Unlock()
 
+	policy := js.policyKeeper.Policy()
+
 	// NOTE keeps broadcasting init ballot, which is based on last block of
-	// homeState until timeoutWaitVoteResult
+	// homeState until TimeoutWaitVoteResultInJoin
 	js.timer = common.NewCallbackTimer(
 		"join-broadcasting-init",
-		js.intervalBroadcastINITBallot,
+		policy.IntervalBroadcastINITBallotInJoin,
 		js.broadcastINITBallot,
 	).
 		SetIntervalFunc(func(runCount uint, elapsed time.Duration) time.Duration {
@@ -129,12 +136,12 @@ func (js *JoinStateHandler) Activate(StateContext) error {
 				return time.Nanosecond
 			}
 
-			if elapsed > js.timeoutWaitVoteResult {
+			if elapsed > policy.TimeoutWaitVoteResultInJoin {
 				go js.requestVoteProof()
 				return 0
 			}
 
-			return js.intervalBroadcastINITBallot
+			return policy.IntervalBroadcastINITBallotInJoin
 		})
 	js.timer.SetLogger(*js.Log())
 
@@ -149,13 +156,22 @@ func (js *JoinStateHandler) Deactivate() error {
 	return js.stopTimer()
 }
 
-func (js *JoinStateHandler) SetChanState(ch chan StateContext) StateHandler {
-	js.chanState = ch
+func (js *JoinStateHandler) SetChanState(ch chan StateContext, stop chan struct{}) StateHandler {
+	js.chanState.set(ch, stop)
+	return js
+}
+
+// SetSealFetcher sets the SealFetcher, which fetches the missing proposal and
+// transactions before catching up the block. It should be called before
+// Start().
+func (js *JoinStateHandler) SetSealFetcher(fetcher *SealFetcher) *JoinStateHandler {
+	js.sealFetcher = fetcher
+
 	return js
 }
 
 func (js *JoinStateHandler) State() node.State {
-	return node.StateBooting
+	return node.StateJoining
 }
 
 func (js *JoinStateHandler) stopTimer() error {
@@ -233,13 +249,13 @@ func (js *JoinStateHandler) broadcastINITBallot(common.Timer) error {
 }
 
 func (js *JoinStateHandler) requestVoteProof() {
-	//<-time.After(js.timeoutWaitVoteResult)
-
 	if js.IsStopped() {
 		return
 	}
 
-	js.Log().Debug().Dur("timeout", js.timeoutWaitVoteResult).Msg("timeout to wait VoteResult; try to request VoteProof")
+	js.Log().Debug().
+		Dur("timeout", js.policyKeeper.Policy().TimeoutWaitVoteResultInJoin).
+		Msg("timeout to wait VoteResult; try to request VoteProof")
 
 	if err := js.stopTimer(); err != nil {
 		return
@@ -281,12 +297,34 @@ func (js *JoinStateHandler) catchUp(vr VoteResult) error {
 		)
 	}
 
-	block, err := js.proposalValidator.NewBlock(vr.Height(), vr.Round(), vr.Proposal())
+	if err := fetchProposal(js.sealFetcher, vr.Height().Sub(1), vr.LastRound(), vr.Proposal()); err != nil {
+		js.Log().Error().Err(err).Object("vr", vr).Msg("failed to fetch proposal")
+		return err
+	}
+
+	// NOTE INIT VoteResult agrees the block of the previous height
+	block, err := js.proposalValidator.NewBlock(vr.Height().Sub(1), vr.LastRound(), vr.Proposal())
 	if err != nil {
 		js.Log().Error().Err(err).Object("vr", vr).Msg("failed to make new block from proposal")
 		return err
 	}
 
+	// NOTE the block from the proposal should be same with the block, which
+	// is agreed by the peers
+	if !block.Hash().Equal(vr.Block()) {
+		js.Log().Error().Object("block", block).Object("vr", vr).Msg("new block is different from agreed block")
+		return xerrors.Errorf(
+			"new block is different from agreed block; block=%q agreed=%q",
+			block.Hash(), vr.Block(),
+		)
+	}
+
+	// NOTE without the transactions of block, the block is not caught up
+	if err := storeBlock(block, js.policyKeeper, js.suffrage); err != nil {
+		js.Log().Error().Err(err).Object("block", block).Msg("failed to apply new block")
+		return err
+	}
+
 	_ = js.homeState.SetBlock(block)
 
 	js.Log().Debug().Object("block", block).Msg("new block from VoteResult saved")
@@ -310,18 +348,18 @@ func (js *JoinStateHandler) gotINITMajority(vr VoteResult) error {
 		return nil
 	case diff == 1: // expected; move to consensus
 		js.Log().Debug().Object("vr", vr).Msg("got expected VoteResult; move to consensus")
-		js.chanState <- NewStateContext(node.StateConsensus).
-			SetContext("vr", vr)
+		js.chanState.send(NewStateContext(node.StateConsensus).
+			SetContext("vr", vr))
 		return nil
 	case diff < 0: // something wrong, move to sync
 		js.Log().Debug().Object("vr", vr).Msg("got lower height VoteResult; move to sync")
-		js.chanState <- NewStateContext(node.StateSyncing).
-			SetContext("vr", vr)
+		js.chanState.send(NewStateContext(node.StateSyncing).
+			SetContext("vr", vr))
 		return nil
 	default: // higher height received, move to sync
 		js.Log().Debug().Object("vr", vr).Msg("got higher height VoteResult; move to sync")
-		js.chanState <- NewStateContext(node.StateSyncing).
-			SetContext("vr", vr)
+		js.chanState.send(NewStateContext(node.StateSyncing).
+			SetContext("vr", vr))
 		return nil
 	}
 }
@@ -37,9 +37,18 @@ func (t *testJoinStateHandler) handler(
 	cn := t.newNetwork(homeState.Home())
 	t.NoError(cn.Start())
 
+	policyKeeper, err := NewPolicyKeeper(homeState, thr, NewTSealStorage(), Policy{
+		Threshold:                         67,
+		IntervalBroadcastINITBallotInJoin: intervalBroadcastINITBallot,
+		TimeoutWaitVoteResultInJoin:       timeoutWaitVoteResult,
+		TimeoutWaitBallot:                 time.Second,
+		TimeoutWaitINITBallot:             time.Second,
+	})
+	t.NoError(err)
+
 	pv := NewDummyProposalValidator()
 	ballotMaker := NewDefaultBallotMaker(home)
-	js, err := NewJoinStateHandler(homeState, cm, cn, suffrage, ballotMaker, pv, intervalBroadcastINITBallot, timeoutWaitVoteResult)
+	js, err := NewJoinStateHandler(homeState, cm, cn, suffrage, ballotMaker, pv, policyKeeper)
 	t.NoError(err)
 
 	return js, func() {
@@ -60,7 +69,7 @@ func (t *testJoinStateHandler) TestNew() {
 	js, closeFunc := t.handler(time.Second*3, time.Second*6)
 	defer closeFunc()
 
-	_ = js.SetChanState(make(chan StateContext))
+	_ = js.SetChanState(make(chan StateContext), nil)
 
 	t.NoError(js.Start())
 	defer js.Stop()
@@ -85,7 +94,7 @@ func (t *testJoinStateHandler) TestEmptyPreviousBlock() {
 
 	pv := NewDummyProposalValidator()
 	ballotMaker := NewDefaultBallotMaker(home)
-	_, err := NewJoinStateHandler(homeState, cm, nil, suffrage, ballotMaker, pv, time.Second*10, time.Second*20)
+	_, err := NewJoinStateHandler(homeState, cm, nil, suffrage, ballotMaker, pv, nil)
 	t.Contains(err.Error(), "previous block is empty")
 }
 
@@ -95,7 +104,7 @@ func (t *testJoinStateHandler) TestBroadcastINITBallot() {
 	js, closeFunc := t.handler(time.Second*3, time.Second*6)
 	defer closeFunc()
 
-	_ = js.SetChanState(make(chan StateContext))
+	_ = js.SetChanState(make(chan StateContext), nil)
 
 	t.NoError(js.Start())
 	defer js.Stop()
@@ -138,7 +147,7 @@ func (t *testJoinStateHandler) TestTimeoutVoteProof() {
 		js.nt.(*network.ChannelNetwork).AddMembers(t.newNetwork(home))
 	}
 
-	_ = js.SetChanState(make(chan StateContext))
+	_ = js.SetChanState(make(chan StateContext), nil)
 
 	t.NoError(js.Start())
 	defer js.Stop()
@@ -159,7 +168,7 @@ func (t *testJoinStateHandler) TestActivateDeactivate() {
 	js, closeFunc := t.handler(time.Second*3, time.Second*6)
 	defer closeFunc()
 
-	_ = js.SetChanState(make(chan StateContext))
+	_ = js.SetChanState(make(chan StateContext), nil)
 
 	t.NoError(js.Start())
 	defer js.Stop()
@@ -0,0 +1,279 @@
+package isaac
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/rs/zerolog"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/keypair"
+	"github.com/spikeekips/mitum/node"
+	"github.com/spikeekips/mitum/seal"
+)
+
+var (
+	KeyRotationType     common.DataType = common.NewDataType(6, "key-rotation")
+	KeyRotationHashHint string          = "key-rotation"
+)
+
+func IsKeyRotationHash(h hash.Hash) bool {
+	return h.Hint() == KeyRotationHashHint
+}
+
+// KeyRotation is the operation to bind the new public key to the node address.
+// KeyRotation should be signed by the key of the node, which is valid at the
+// given height; like PolicyChange, it is included in Proposal and after the
+// block of the proposal is agreed, the new key will be effective from the
+// given height.
+//
+// The new BLS public key should have the proof of possession, which is made by
+// keypair.BLSPrivateKey.ProvePossession; without it, the rogue key can forge the
+// aggregated signature. The proof of the other keys is not needed.
+type KeyRotation struct {
+	seal.BaseSeal
+	body KeyRotationBody
+}
+
+func NewKeyRotation(
+	address node.Address,
+	publicKey keypair.PublicKey,
+	height Height,
+	proof keypair.Signature,
+) (KeyRotation, error) {
+	body := KeyRotationBody{
+		node:      address,
+		publicKey: publicKey,
+		height:    height,
+		proof:     proof,
+	}
+
+	h, err := body.makeHash()
+	if err != nil {
+		return KeyRotation{}, err
+	}
+
+	body.hash = h
+
+	return KeyRotation{BaseSeal: seal.NewBaseSeal(body), body: body}, nil
+}
+
+func (kr KeyRotation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(kr.BaseSeal)
+}
+
+func (kr KeyRotation) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, kr.BaseSeal)
+}
+
+func (kr *KeyRotation) DecodeRLP(s *rlp.Stream) error {
+	var raw seal.RLPDecodeSeal
+	if err := s.Decode(&raw); err != nil {
+		return err
+	}
+
+	var body KeyRotationBody
+	if err := rlp.DecodeBytes(raw.Body, &body); err != nil {
+		return err
+	}
+	bsl := &seal.BaseSeal{}
+	bsl = bsl.
+		SetType(raw.Type).
+		SetHash(raw.Hash).
+		SetHeader(raw.Header).
+		SetBody(body)
+
+	kr.BaseSeal = *bsl
+	kr.body = body
+
+	if err := kr.IsValid(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (kr KeyRotation) Body() seal.Body {
+	return kr.body
+}
+
+func (kr KeyRotation) Type() common.DataType {
+	return KeyRotationType
+}
+
+func (kr KeyRotation) Node() node.Address {
+	return kr.body.node
+}
+
+// PublicKey is the new public key of node.
+func (kr KeyRotation) PublicKey() keypair.PublicKey {
+	return kr.body.publicKey
+}
+
+// Height is the height, which the new key is effective from.
+func (kr KeyRotation) Height() Height {
+	return kr.body.height
+}
+
+// Proof is the proof of possession of the new BLS public key.
+func (kr KeyRotation) Proof() keypair.Signature {
+	return kr.body.proof
+}
+
+func (kr KeyRotation) IsValid() error {
+	if err := kr.BaseSeal.IsValid(); err != nil {
+		return err
+	}
+
+	if err := kr.body.IsValid(); err != nil {
+		return err
+	}
+
+	b, err := kr.body.hashBytes()
+	if err != nil {
+		return err
+	} else if err := kr.body.Hash().Verify(b); err != nil {
+		return err
+	}
+
+	if kr.Signer().Equal(kr.PublicKey()) {
+		return xerrors.Errorf("new public key is same with signer; publickey=%q", kr.PublicKey())
+	}
+
+	return nil
+}
+
+type KeyRotationBody struct {
+	hash      hash.Hash
+	node      node.Address
+	publicKey keypair.PublicKey
+	height    Height
+	proof     keypair.Signature
+}
+
+func (krb KeyRotationBody) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"hash":      krb.hash,
+		"node":      krb.node,
+		"publickey": krb.publicKey,
+		"height":    krb.height,
+		"proof":     krb.proof,
+	})
+}
+
+func (krb KeyRotationBody) MarshalZerologObject(e *zerolog.Event) {
+	e.Object("hash", krb.hash)
+	e.Object("node", krb.node)
+	e.Str("publickey", krb.publicKey.String())
+	e.Str("height", krb.height.String())
+}
+
+func (krb KeyRotationBody) String() string {
+	b, _ := json.Marshal(krb) // nolint
+	return string(b)
+}
+
+func (krb KeyRotationBody) Hash() hash.Hash {
+	return krb.hash
+}
+
+func (krb KeyRotationBody) Type() common.DataType {
+	return KeyRotationType
+}
+
+func (krb KeyRotationBody) IsValid() error {
+	if err := krb.hash.IsValid(); err != nil {
+		return err
+	} else if !IsKeyRotationHash(krb.hash) {
+		return xerrors.Errorf("KeyRotation.Hash() is not valid hash; hash=%q", krb.hash)
+	}
+
+	if err := krb.node.IsValid(); err != nil {
+		return err
+	} else if !node.IsAddress(krb.node) {
+		return xerrors.Errorf("node is not node.Address; node=%q", krb.node)
+	}
+
+	if krb.publicKey == nil {
+		return xerrors.Errorf("empty public key")
+	} else if err := krb.publicKey.IsValid(); err != nil {
+		return err
+	}
+
+	if err := krb.height.IsValid(); err != nil {
+		return err
+	}
+
+	if krb.publicKey.Type().Equal(keypair.BLSType) {
+		if err := keypair.VerifyBLSPossession(krb.publicKey, krb.proof); err != nil {
+			return xerrors.Errorf("invalid proof of possession of bls public key: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (krb KeyRotationBody) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, struct {
+		HS hash.Hash
+		N  node.Address
+		P  keypair.PublicKey
+		H  Height
+		PR keypair.Signature
+	}{
+		HS: krb.hash,
+		N:  krb.node,
+		P:  krb.publicKey,
+		H:  krb.height,
+		PR: krb.proof,
+	})
+}
+
+func (krb *KeyRotationBody) DecodeRLP(s *rlp.Stream) error {
+	var body struct {
+		HS hash.Hash
+		N  node.Address
+		P  rlp.RawValue
+		H  Height
+		PR keypair.Signature
+	}
+	if err := s.Decode(&body); err != nil {
+		return err
+	}
+
+	pk, err := keypair.DecodePublicKey(body.P)
+	if err != nil {
+		return err
+	}
+
+	krb.hash = body.HS
+	krb.node = body.N
+	krb.publicKey = pk
+	krb.height = body.H
+	if len(body.PR) > 0 {
+		krb.proof = body.PR
+	}
+
+	return nil
+}
+
+func (krb KeyRotationBody) makeHash() (hash.Hash, error) {
+	b, err := krb.hashBytes()
+	if err != nil {
+		return hash.Hash{}, err
+	}
+
+	return hash.NewHashByHint(KeyRotationHashHint, b)
+}
+
+func (krb KeyRotationBody) hashBytes() ([]byte, error) {
+	return rlp.EncodeToBytes([]interface{}{
+		krb.node,
+		krb.publicKey,
+		krb.height,
+		krb.proof,
+	})
+}
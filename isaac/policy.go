@@ -1,11 +1,130 @@
 package isaac
 
-import "time"
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"time"
 
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/rs/zerolog"
+	"golang.org/x/xerrors"
+)
+
+// Policy is the agreed parameters of consensus; PipelineProposal is the local
+// option of node, so it is not encoded and not compared.
+//
+// NOTE Threshold is float64, not uint anymore, like the percent of `Threshold`;
+// it is encoded with the 2 decimal places, so 66.67 can be agreed by
+// PolicyChange. The code, which sets uint Threshold should be converted.
 type Policy struct {
-	Threshold                         uint          // base percent for `Threshold`
+	Threshold                         float64       // base percent for `Threshold`
 	IntervalBroadcastINITBallotInJoin time.Duration // interval to broadcast INIT ballot in join
 	TimeoutWaitVoteResultInJoin       time.Duration // wait VoteResult in join state
 	TimeoutWaitBallot                 time.Duration // wait the new Proposal
 	TimeoutWaitINITBallot             time.Duration // wait the INIT ballot
+	AllConfirm                        bool          // confirm block by ALLCONFIRM stage before storing
+	PipelineProposal                  bool          // next proposer prepares proposal during ACCEPT stage
+}
+
+func (po Policy) IsValid() error {
+	if po.Threshold <= 0 || po.Threshold > 100 {
+		return xerrors.Errorf("invalid threshold; threshold=%v", po.Threshold)
+	}
+
+	for name, d := range map[string]time.Duration{
+		"IntervalBroadcastINITBallotInJoin": po.IntervalBroadcastINITBallotInJoin,
+		"TimeoutWaitVoteResultInJoin":       po.TimeoutWaitVoteResultInJoin,
+		"TimeoutWaitBallot":                 po.TimeoutWaitBallot,
+		"TimeoutWaitINITBallot":             po.TimeoutWaitINITBallot,
+	} {
+		if d < time.Nanosecond {
+			return xerrors.Errorf("%s should be greater than zero; duration=%v", name, d)
+		}
+	}
+
+	return nil
+}
+
+func (po Policy) Equal(n Policy) bool {
+	return po.thresholdUint() == n.thresholdUint() &&
+		po.IntervalBroadcastINITBallotInJoin == n.IntervalBroadcastINITBallotInJoin &&
+		po.TimeoutWaitVoteResultInJoin == n.TimeoutWaitVoteResultInJoin &&
+		po.TimeoutWaitBallot == n.TimeoutWaitBallot &&
+		po.TimeoutWaitINITBallot == n.TimeoutWaitINITBallot &&
+		po.AllConfirm == n.AllConfirm
+}
+
+// thresholdUint keeps the 2 decimal places of Threshold like `Threshold`
+// does; float can not be encoded by RLP.
+func (po Policy) thresholdUint() uint64 {
+	return uint64(math.Round(po.Threshold * 100))
+}
+
+func (po Policy) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, struct {
+		T   uint64
+		IBJ uint64
+		TVJ uint64
+		TB  uint64
+		TIB uint64
+		AC  bool
+	}{
+		T:   po.thresholdUint(),
+		IBJ: uint64(po.IntervalBroadcastINITBallotInJoin),
+		TVJ: uint64(po.TimeoutWaitVoteResultInJoin),
+		TB:  uint64(po.TimeoutWaitBallot),
+		TIB: uint64(po.TimeoutWaitINITBallot),
+		AC:  po.AllConfirm,
+	})
+}
+
+func (po *Policy) DecodeRLP(s *rlp.Stream) error {
+	var body struct {
+		T   uint64
+		IBJ uint64
+		TVJ uint64
+		TB  uint64
+		TIB uint64
+		AC  bool
+	}
+	if err := s.Decode(&body); err != nil {
+		return err
+	}
+
+	po.Threshold = float64(body.T) / 100
+	po.IntervalBroadcastINITBallotInJoin = time.Duration(body.IBJ)
+	po.TimeoutWaitVoteResultInJoin = time.Duration(body.TVJ)
+	po.TimeoutWaitBallot = time.Duration(body.TB)
+	po.TimeoutWaitINITBallot = time.Duration(body.TIB)
+	po.AllConfirm = body.AC
+
+	return nil
+}
+
+func (po Policy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"threshold":                              po.Threshold,
+		"interval_broadcast_init_ballot_in_join": po.IntervalBroadcastINITBallotInJoin,
+		"timeout_wait_vote_result_in_join":       po.TimeoutWaitVoteResultInJoin,
+		"timeout_wait_ballot":                    po.TimeoutWaitBallot,
+		"timeout_wait_init_ballot":               po.TimeoutWaitINITBallot,
+		"all_confirm":                            po.AllConfirm,
+		"pipeline_proposal":                      po.PipelineProposal,
+	})
+}
+
+func (po Policy) MarshalZerologObject(e *zerolog.Event) {
+	e.Float64("threshold", po.Threshold)
+	e.Dur("interval_broadcast_init_ballot_in_join", po.IntervalBroadcastINITBallotInJoin)
+	e.Dur("timeout_wait_vote_result_in_join", po.TimeoutWaitVoteResultInJoin)
+	e.Dur("timeout_wait_ballot", po.TimeoutWaitBallot)
+	e.Dur("timeout_wait_init_ballot", po.TimeoutWaitINITBallot)
+	e.Bool("all_confirm", po.AllConfirm)
+	e.Bool("pipeline_proposal", po.PipelineProposal)
+}
+
+func (po Policy) String() string {
+	b, _ := json.Marshal(po) // nolint
+	return string(b)
 }
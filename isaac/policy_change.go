@@ -0,0 +1,223 @@
+package isaac
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/rs/zerolog"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/seal"
+)
+
+var (
+	PolicyChangeType     common.DataType = common.NewDataType(5, "policy-change")
+	PolicyChangeHashHint string          = "policy-change"
+)
+
+func IsPolicyChangeHash(h hash.Hash) bool {
+	return h.Hint() == PolicyChangeHashHint
+}
+
+// PolicyChange is the operation to change the Policy. PolicyChange is
+// included in Proposal like the other transactions, and after the block of
+// the proposal is stored, the new Policy will be effective from the given
+// height.
+type PolicyChange struct {
+	seal.BaseSeal
+	body PolicyChangeBody
+}
+
+func NewPolicyChange(height Height, policy Policy) (PolicyChange, error) {
+	body := PolicyChangeBody{
+		height: height,
+		policy: policy,
+	}
+
+	h, err := body.makeHash()
+	if err != nil {
+		return PolicyChange{}, err
+	}
+
+	body.hash = h
+
+	return PolicyChange{BaseSeal: seal.NewBaseSeal(body), body: body}, nil
+}
+
+func (pc PolicyChange) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pc.BaseSeal)
+}
+
+func (pc PolicyChange) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, pc.BaseSeal)
+}
+
+func (pc *PolicyChange) DecodeRLP(s *rlp.Stream) error {
+	var raw seal.RLPDecodeSeal
+	if err := s.Decode(&raw); err != nil {
+		return err
+	}
+
+	var body PolicyChangeBody
+	if err := rlp.DecodeBytes(raw.Body, &body); err != nil {
+		return err
+	}
+	bsl := &seal.BaseSeal{}
+	bsl = bsl.
+		SetType(raw.Type).
+		SetHash(raw.Hash).
+		SetHeader(raw.Header).
+		SetBody(body)
+
+	pc.BaseSeal = *bsl
+	pc.body = body
+
+	if err := pc.IsValid(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (pc PolicyChange) Body() seal.Body {
+	return pc.body
+}
+
+func (pc PolicyChange) Type() common.DataType {
+	return PolicyChangeType
+}
+
+// Height is the height, which the new Policy is effective from.
+func (pc PolicyChange) Height() Height {
+	return pc.body.height
+}
+
+func (pc PolicyChange) Policy() Policy {
+	return pc.body.policy
+}
+
+func (pc PolicyChange) IsValid() error {
+	if err := pc.BaseSeal.IsValid(); err != nil {
+		return err
+	}
+
+	if err := pc.body.IsValid(); err != nil {
+		return err
+	}
+
+	b, err := pc.body.hashBytes()
+	if err != nil {
+		return err
+	} else if err := pc.body.Hash().Verify(b); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type PolicyChangeBody struct {
+	hash   hash.Hash
+	height Height
+	policy Policy
+}
+
+func (pcb PolicyChangeBody) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"hash":   pcb.hash,
+		"height": pcb.height,
+		"policy": pcb.policy,
+	})
+}
+
+func (pcb PolicyChangeBody) MarshalZerologObject(e *zerolog.Event) {
+	e.Object("hash", pcb.hash)
+	e.Str("height", pcb.height.String())
+	e.Object("policy", pcb.policy)
+}
+
+func (pcb PolicyChangeBody) String() string {
+	b, _ := json.Marshal(pcb) // nolint
+	return string(b)
+}
+
+func (pcb PolicyChangeBody) Hash() hash.Hash {
+	return pcb.hash
+}
+
+func (pcb PolicyChangeBody) Type() common.DataType {
+	return PolicyChangeType
+}
+
+func (pcb PolicyChangeBody) Height() Height {
+	return pcb.height
+}
+
+func (pcb PolicyChangeBody) Policy() Policy {
+	return pcb.policy
+}
+
+func (pcb PolicyChangeBody) IsValid() error {
+	if err := pcb.hash.IsValid(); err != nil {
+		return err
+	} else if !IsPolicyChangeHash(pcb.hash) {
+		return xerrors.Errorf("PolicyChange.Hash() is not valid hash; hash=%q", pcb.hash)
+	}
+
+	if err := pcb.height.IsValid(); err != nil {
+		return err
+	}
+
+	if err := pcb.policy.IsValid(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (pcb PolicyChangeBody) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, struct {
+		HS hash.Hash
+		H  Height
+		P  Policy
+	}{
+		HS: pcb.hash,
+		H:  pcb.height,
+		P:  pcb.policy,
+	})
+}
+
+func (pcb *PolicyChangeBody) DecodeRLP(s *rlp.Stream) error {
+	var body struct {
+		HS hash.Hash
+		H  Height
+		P  Policy
+	}
+	if err := s.Decode(&body); err != nil {
+		return err
+	}
+
+	pcb.hash = body.HS
+	pcb.height = body.H
+	pcb.policy = body.P
+
+	return nil
+}
+
+func (pcb PolicyChangeBody) makeHash() (hash.Hash, error) {
+	b, err := pcb.hashBytes()
+	if err != nil {
+		return hash.Hash{}, err
+	}
+
+	return hash.NewHashByHint(PolicyChangeHashHint, b)
+}
+
+func (pcb PolicyChangeBody) hashBytes() ([]byte, error) {
+	return rlp.EncodeToBytes([]interface{}{
+		pcb.height,
+		pcb.policy,
+	})
+}
@@ -0,0 +1,288 @@
+package isaac
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/keypair"
+	"github.com/spikeekips/mitum/seal"
+)
+
+// PolicyKeeper keeps the current Policy and the received PolicyChanges.
+// * the received PolicyChange is pending until it is included in the proposal
+// of the stored block
+// * the included PolicyChange is scheduled by it's height
+// * when the block of `PolicyChange.Height() - 1` is stored, the new Policy is
+// applied, so the consensus of `PolicyChange.Height()` runs by the new Policy
+// in every node
+// * only the PolicyChange signed by the authorities is accepted; without
+// authorities, no PolicyChange is accepted
+type PolicyKeeper struct {
+	sync.RWMutex
+	*common.Logger
+	homeState   *HomeState
+	threshold   *Threshold
+	sealStorage SealStorage
+	policy      Policy
+	authorities []keypair.PublicKey
+	pending     map[hash.Hash]PolicyChange
+	scheduled   map[string] /* Height.String() */ PolicyChange
+}
+
+func NewPolicyKeeper(
+	homeState *HomeState,
+	threshold *Threshold,
+	sealStorage SealStorage,
+	policy Policy,
+) (*PolicyKeeper, error) {
+	if err := policy.IsValid(); err != nil {
+		return nil, err
+	}
+
+	if err := threshold.SetPercent(policy.Threshold); err != nil {
+		return nil, err
+	}
+
+	return &PolicyKeeper{
+		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
+			return c.Str("module", "policy-keeper")
+		}),
+		homeState:   homeState,
+		threshold:   threshold,
+		sealStorage: sealStorage,
+		policy:      policy,
+		pending:     map[hash.Hash]PolicyChange{},
+		scheduled:   map[string]PolicyChange{},
+	}, nil
+}
+
+func (pk *PolicyKeeper) Policy() Policy {
+	pk.RLock()
+	defer pk.RUnlock()
+
+	return pk.policy
+}
+
+// SetAuthorities sets the public keys, which can sign PolicyChange; it should
+// be called before the PolicyKeeper is used.
+func (pk *PolicyKeeper) SetAuthorities(keys ...keypair.PublicKey) *PolicyKeeper {
+	pk.Lock()
+	defer pk.Unlock()
+
+	pk.authorities = keys
+
+	return pk
+}
+
+func (pk *PolicyKeeper) isAuthority(key keypair.PublicKey) bool {
+	pk.RLock()
+	defer pk.RUnlock()
+
+	if key == nil {
+		return false
+	}
+
+	for _, a := range pk.authorities {
+		if a.Equal(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckTransaction checks the PolicyChange is valid and signed by the
+// authorities; the other transactions are ignored.
+func (pk *PolicyKeeper) CheckTransaction(sl seal.Seal) error {
+	pc, ok := sl.(PolicyChange)
+	if !ok {
+		return nil
+	}
+
+	if err := pc.IsValid(); err != nil {
+		return err
+	}
+
+	if !pk.isAuthority(pc.Signer()) {
+		return xerrors.Errorf("PolicyChange is not signed by authorities; signer=%q", pc.Signer())
+	}
+
+	return nil
+}
+
+// AddPending keeps the PolicyChange until it is included in block.
+func (pk *PolicyKeeper) AddPending(pc PolicyChange) error {
+	if err := pk.CheckTransaction(pc); err != nil {
+		return err
+	}
+
+	if pc.Height().Cmp(pk.homeState.Block().Height()) < 1 {
+		return xerrors.Errorf(
+			"PolicyChange height should be greater than last block; height=%q block=%q",
+			pc.Height(),
+			pk.homeState.Block().Height(),
+		)
+	}
+
+	pk.Lock()
+	defer pk.Unlock()
+
+	pk.pending[pc.Hash()] = pc
+
+	pk.Log().Debug().Object("policy_change", pc).Msg("new PolicyChange added to pending")
+
+	return nil
+}
+
+// Pending returns the hashes of PolicyChanges, which are not yet included in
+// block. Pending can be used for OperationPool of ProposalMaker.
+func (pk *PolicyKeeper) Pending() []hash.Hash {
+	pk.RLock()
+	defer pk.RUnlock()
+
+	var hs []hash.Hash
+	for h := range pk.pending {
+		hs = append(hs, h)
+	}
+
+	sort.Slice(hs, func(i, j int) bool {
+		return hs[i].String() < hs[j].String()
+	})
+
+	return hs
+}
+
+// StoreBlock schedules the PolicyChanges in the proposal of block and applies
+// the Policy for the next height of block. If the proposal or it's
+// transactions are not found, StoreBlock fails; the node can not know the
+// Policy of the next height.
+func (pk *PolicyKeeper) StoreBlock(block Block) error {
+	changes, err := pk.policyChanges(block)
+	if err != nil {
+		return err
+	}
+
+	pk.Lock()
+	defer pk.Unlock()
+
+	for _, pc := range changes {
+		delete(pk.pending, pc.Hash())
+
+		if pc.Height().Cmp(block.Height()) < 1 {
+			pk.Log().Warn().
+				Object("policy_change", pc).
+				Object("block", block).
+				Msg("PolicyChange is included in block, but it's height is already passed; ignored")
+			continue
+		}
+
+		// NOTE the later PolicyChange overrides the previous one of same height
+		pk.scheduled[pc.Height().String()] = pc
+
+		pk.Log().Debug().
+			Object("policy_change", pc).
+			Object("block", block).
+			Msg("PolicyChange scheduled")
+	}
+
+	return pk.activate(block.Height().Add(1))
+}
+
+func (pk *PolicyKeeper) policyChanges(block Block) ([]PolicyChange, error) {
+	proposal, err := pk.BlockProposal(block)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := pk.Transactions(proposal)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []PolicyChange
+	for _, tx := range transactions {
+		if pc, ok := tx.(PolicyChange); ok {
+			changes = append(changes, pc)
+		}
+	}
+
+	return changes, nil
+}
+
+// BlockProposal returns the proposal of block from the seal storage.
+func (pk *PolicyKeeper) BlockProposal(block Block) (Proposal, error) {
+	sl := pk.sealStorage.Get(block.Proposal())
+	if sl == nil {
+		return Proposal{}, xerrors.Errorf("proposal of block not found in storage; proposal=%q", block.Proposal())
+	}
+
+	proposal, ok := sl.(Proposal)
+	if !ok {
+		return Proposal{}, xerrors.Errorf("proposal of block is not Proposal; proposal=%q", block.Proposal())
+	}
+
+	return proposal, nil
+}
+
+// Transactions returns the transactions of proposal from the seal storage; the
+// unknown transaction is error.
+func (pk *PolicyKeeper) Transactions(proposal Proposal) ([]seal.Seal, error) {
+	var transactions []seal.Seal
+	for _, h := range proposal.Transactions() {
+		sl := pk.sealStorage.Get(h)
+		if sl == nil {
+			return nil, xerrors.Errorf("transaction of proposal not found in storage; transaction=%q", h)
+		}
+
+		transactions = append(transactions, sl)
+	}
+
+	return transactions, nil
+}
+
+func (pk *PolicyKeeper) activate(height Height) error {
+	var heights []Height
+	for _, pc := range pk.scheduled {
+		if pc.Height().Cmp(height) > 0 {
+			continue
+		}
+
+		heights = append(heights, pc.Height())
+	}
+
+	if len(heights) < 1 {
+		return nil
+	}
+
+	sort.Slice(heights, func(i, j int) bool {
+		return heights[i].Cmp(heights[j]) < 0
+	})
+
+	pc := pk.scheduled[heights[len(heights)-1].String()]
+	for _, h := range heights {
+		delete(pk.scheduled, h.String())
+	}
+
+	if err := pk.threshold.SetPercent(pc.Policy().Threshold); err != nil {
+		return err
+	}
+
+	// NOTE PipelineProposal is not agreed, the local option is kept
+	policy := pc.Policy()
+	policy.PipelineProposal = pk.policy.PipelineProposal
+
+	pk.Log().Info().
+		Object("previous", pk.policy).
+		Object("policy", policy).
+		Str("height", height.String()).
+		Msg("new policy applied")
+
+	pk.policy = policy
+
+	return nil
+}
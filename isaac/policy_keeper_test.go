@@ -0,0 +1,326 @@
+package isaac
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/node"
+)
+
+type testPolicyKeeper struct {
+	suite.Suite
+	authority node.Home
+}
+
+func (t *testPolicyKeeper) SetupTest() {
+	t.authority = node.NewRandomHome()
+}
+
+func (t *testPolicyKeeper) newPolicyKeeper(homeState *HomeState, thr *Threshold, ss SealStorage, policy Policy) *PolicyKeeper {
+	pk, err := NewPolicyKeeper(homeState, thr, ss, policy)
+	t.NoError(err)
+
+	return pk.SetAuthorities(t.authority.PublicKey())
+}
+
+func (t *testPolicyKeeper) newPolicy(threshold float64) Policy {
+	return Policy{
+		Threshold:                         threshold,
+		IntervalBroadcastINITBallotInJoin: time.Second,
+		TimeoutWaitVoteResultInJoin:       time.Second * 2,
+		TimeoutWaitBallot:                 time.Second * 3,
+		TimeoutWaitINITBallot:             time.Second * 4,
+	}
+}
+
+func (t *testPolicyKeeper) newPolicyChange(height Height, policy Policy) PolicyChange {
+	pc, err := NewPolicyChange(height, policy)
+	t.NoError(err)
+	t.NoError(pc.Sign(t.authority.PrivateKey(), nil))
+
+	return pc
+}
+
+// storeNextBlock stores the PolicyChanges into the proposal of the next block
+// and then the next block.
+func (t *testPolicyKeeper) storeNextBlock(
+	homeState *HomeState,
+	pk *PolicyKeeper,
+	ss SealStorage,
+	pcs ...PolicyChange,
+) Block {
+	home := homeState.Home()
+
+	var transactions []hash.Hash
+	for _, pc := range pcs {
+		_ = ss.Save(pc)
+		transactions = append(transactions, pc.Hash())
+	}
+
+	proposal, err := NewProposal(
+		homeState.Block().Height().Add(1),
+		Round(0),
+		homeState.Block().Hash(),
+		home.Address(),
+		transactions,
+	)
+	t.NoError(err)
+	t.NoError(proposal.Sign(home.PrivateKey(), nil))
+	t.NoError(ss.Save(proposal))
+
+	block, err := NewBlock(proposal.Height(), proposal.Round(), proposal.Hash())
+	t.NoError(err)
+
+	_ = homeState.SetBlock(block)
+	t.NoError(pk.StoreBlock(block))
+
+	return block
+}
+
+func (t *testPolicyKeeper) TestPolicyRLP() {
+	policy := t.newPolicy(66.67)
+
+	b, err := rlp.EncodeToBytes(policy)
+	t.NoError(err)
+
+	var decoded Policy
+	t.NoError(rlp.DecodeBytes(b, &decoded))
+	t.True(policy.Equal(decoded))
+}
+
+func (t *testPolicyKeeper) TestPolicyChangeRLP() {
+	pc := t.newPolicyChange(NewBlockHeight(33), t.newPolicy(80))
+
+	b, err := rlp.EncodeToBytes(pc)
+	t.NoError(err)
+
+	var decoded PolicyChange
+	t.NoError(rlp.DecodeBytes(b, &decoded))
+	t.True(pc.Hash().Equal(decoded.Hash()))
+	t.True(pc.Height().Equal(decoded.Height()))
+	t.True(pc.Policy().Equal(decoded.Policy()))
+}
+
+func (t *testPolicyKeeper) TestPipelineProposalNotEncoded() {
+	policy := t.newPolicy(80)
+	pipelined := policy
+	pipelined.PipelineProposal = true
+
+	// NOTE PipelineProposal is the local option; it does not change the hash
+	// of PolicyChange
+	height := NewBlockHeight(33)
+	t.True(t.newPolicyChange(height, policy).Body().Hash().Equal(t.newPolicyChange(height, pipelined).Body().Hash()))
+
+	b, err := rlp.EncodeToBytes(pipelined)
+	t.NoError(err)
+
+	var decoded Policy
+	t.NoError(rlp.DecodeBytes(b, &decoded))
+	t.False(decoded.PipelineProposal)
+	t.True(pipelined.Equal(decoded))
+}
+
+func (t *testPolicyKeeper) TestActivateKeepsPipelineProposal() {
+	homeState := NewHomeState(node.NewRandomHome(), NewRandomBlock())
+	thr, _ := NewThreshold(10, 67)
+	ss := NewTSealStorage()
+
+	policy := t.newPolicy(67)
+	policy.PipelineProposal = true
+	pk := t.newPolicyKeeper(homeState, thr, ss, policy)
+
+	pc := t.newPolicyChange(homeState.Block().Height().Add(2), t.newPolicy(80))
+	t.storeNextBlock(homeState, pk, ss, pc)
+	t.True(t.newPolicy(80).Equal(pk.Policy()))
+	t.True(pk.Policy().PipelineProposal)
+}
+
+func (t *testPolicyKeeper) TestInvalidPolicy() {
+	homeState := NewHomeState(node.NewRandomHome(), NewRandomBlock())
+	thr, _ := NewThreshold(4, 67)
+
+	policy := t.newPolicy(67)
+	policy.TimeoutWaitBallot = 0
+
+	_, err := NewPolicyKeeper(homeState, thr, NewTSealStorage(), policy)
+	t.Contains(err.Error(), "TimeoutWaitBallot")
+}
+
+func (t *testPolicyKeeper) TestAddPendingPassedHeight() {
+	homeState := NewHomeState(node.NewRandomHome(), NewRandomBlock())
+	thr, _ := NewThreshold(4, 67)
+
+	pk := t.newPolicyKeeper(homeState, thr, NewTSealStorage(), t.newPolicy(67))
+
+	pc := t.newPolicyChange(homeState.Block().Height(), t.newPolicy(80))
+	err := pk.AddPending(pc)
+	t.Contains(err.Error(), "should be greater than last block")
+	t.Empty(pk.Pending())
+}
+
+func (t *testPolicyKeeper) TestActivateAtHeight() {
+	homeState := NewHomeState(node.NewRandomHome(), NewRandomBlock())
+	thr, _ := NewThreshold(10, 67)
+	ss := NewTSealStorage()
+
+	policy := t.newPolicy(67)
+	pk := t.newPolicyKeeper(homeState, thr, ss, policy)
+
+	// NOTE new policy is effective from 3 heights later
+	newPolicy := t.newPolicy(100)
+	newPolicy.TimeoutWaitBallot = time.Second * 10
+
+	pc := t.newPolicyChange(homeState.Block().Height().Add(3), newPolicy)
+	t.NoError(pk.AddPending(pc))
+	t.Equal([]hash.Hash{pc.Hash()}, pk.Pending())
+
+	// NOTE included in block; no more pending, but not yet applied
+	t.storeNextBlock(homeState, pk, ss, pc)
+	t.Empty(pk.Pending())
+	t.True(policy.Equal(pk.Policy()))
+
+	_, threshold := thr.Get(StageSIGN)
+	t.Equal(uint(7), threshold)
+
+	// NOTE the block of `height - 1` is stored; new policy applied
+	block := t.storeNextBlock(homeState, pk, ss)
+	t.True(block.Height().Add(1).Equal(pc.Height()))
+	t.True(newPolicy.Equal(pk.Policy()))
+
+	_, threshold = thr.Get(StageSIGN)
+	t.Equal(uint(10), threshold)
+}
+
+func (t *testPolicyKeeper) TestReplayStoredBlocks() {
+	genesis := NewRandomBlock()
+	homeState := NewHomeState(node.NewRandomHome(), genesis)
+	thr, _ := NewThreshold(10, 67)
+	ss := NewTSealStorage()
+
+	policy := t.newPolicy(67)
+	pk := t.newPolicyKeeper(homeState, thr, ss, policy)
+
+	// NOTE one is applied and the other is still scheduled
+	applied := t.newPolicyChange(homeState.Block().Height().Add(2), t.newPolicy(80))
+	scheduled := t.newPolicyChange(homeState.Block().Height().Add(4), t.newPolicy(100))
+
+	blocks := []Block{
+		t.storeNextBlock(homeState, pk, ss, applied, scheduled),
+		t.storeNextBlock(homeState, pk, ss),
+	}
+	t.True(t.newPolicy(80).Equal(pk.Policy()))
+
+	// NOTE the restarted node replays the stored blocks from the config policy
+	rthr, _ := NewThreshold(10, 67)
+	rhomeState := NewHomeState(homeState.Home(), genesis)
+	rpk := t.newPolicyKeeper(rhomeState, rthr, ss, policy)
+	for _, block := range blocks {
+		t.NoError(rpk.StoreBlock(block))
+	}
+
+	t.True(pk.Policy().Equal(rpk.Policy()))
+	_, threshold := thr.Get(StageSIGN)
+	_, rthreshold := rthr.Get(StageSIGN)
+	t.Equal(threshold, rthreshold)
+
+	// NOTE the scheduled one is also applied
+	block := t.storeNextBlock(homeState, pk, ss)
+	t.NoError(rpk.StoreBlock(block))
+	t.True(t.newPolicy(100).Equal(pk.Policy()))
+	t.True(t.newPolicy(100).Equal(rpk.Policy()))
+}
+
+func (t *testPolicyKeeper) TestBlockProposal() {
+	homeState := NewHomeState(node.NewRandomHome(), NewRandomBlock())
+	thr, _ := NewThreshold(4, 67)
+	ss := NewTSealStorage()
+
+	pk := t.newPolicyKeeper(homeState, thr, ss, t.newPolicy(67))
+
+	block := t.storeNextBlock(homeState, pk, ss)
+
+	proposal, err := pk.BlockProposal(block)
+	t.NoError(err)
+	t.True(block.Proposal().Equal(proposal.Hash()))
+
+	// NOTE proposal is unknown
+	_, err = pk.BlockProposal(NewRandomBlock())
+	t.Contains(err.Error(), "proposal of block not found")
+}
+
+func (t *testPolicyKeeper) TestSameHeightOverride() {
+	homeState := NewHomeState(node.NewRandomHome(), NewRandomBlock())
+	thr, _ := NewThreshold(10, 67)
+	ss := NewTSealStorage()
+
+	pk := t.newPolicyKeeper(homeState, thr, ss, t.newPolicy(67))
+
+	height := homeState.Block().Height().Add(2)
+	pc0 := t.newPolicyChange(height, t.newPolicy(80))
+	pc1 := t.newPolicyChange(height, t.newPolicy(90))
+
+	t.storeNextBlock(homeState, pk, ss, pc0, pc1)
+	t.True(t.newPolicy(90).Equal(pk.Policy()))
+}
+
+func (t *testPolicyKeeper) TestAddPendingNotAuthority() {
+	homeState := NewHomeState(node.NewRandomHome(), NewRandomBlock())
+	thr, _ := NewThreshold(4, 67)
+
+	pk := t.newPolicyKeeper(homeState, thr, NewTSealStorage(), t.newPolicy(67))
+
+	pc, err := NewPolicyChange(homeState.Block().Height().Add(1), t.newPolicy(80))
+	t.NoError(err)
+	t.NoError(pc.Sign(node.NewRandomHome().PrivateKey(), nil))
+
+	err = pk.AddPending(pc)
+	t.Contains(err.Error(), "not signed by authorities")
+	t.Empty(pk.Pending())
+
+	// NOTE without authorities, nothing is accepted
+	pk = t.newPolicyKeeper(homeState, thr, NewTSealStorage(), t.newPolicy(67)).SetAuthorities()
+	err = pk.AddPending(t.newPolicyChange(homeState.Block().Height().Add(1), t.newPolicy(80)))
+	t.Contains(err.Error(), "not signed by authorities")
+}
+
+func (t *testPolicyKeeper) TestStoreBlockUnknownTransaction() {
+	homeState := NewHomeState(node.NewRandomHome(), NewRandomBlock())
+	thr, _ := NewThreshold(4, 67)
+	ss := NewTSealStorage()
+
+	policy := t.newPolicy(67)
+	pk := t.newPolicyKeeper(homeState, thr, ss, policy)
+
+	home := homeState.Home()
+	proposal, err := NewProposal(
+		homeState.Block().Height().Add(1),
+		Round(0),
+		homeState.Block().Hash(),
+		home.Address(),
+		[]hash.Hash{t.newPolicyChange(homeState.Block().Height().Add(2), t.newPolicy(80)).Hash()},
+	)
+	t.NoError(err)
+	t.NoError(proposal.Sign(home.PrivateKey(), nil))
+
+	// NOTE proposal is unknown
+	block, err := NewBlock(proposal.Height(), proposal.Round(), proposal.Hash())
+	t.NoError(err)
+
+	err = pk.StoreBlock(block)
+	t.Contains(err.Error(), "proposal of block not found")
+
+	// NOTE transaction is unknown
+	t.NoError(ss.Save(proposal))
+
+	err = pk.StoreBlock(block)
+	t.Contains(err.Error(), "transaction of proposal not found")
+	t.True(policy.Equal(pk.Policy()))
+}
+
+func TestPolicyKeeper(t *testing.T) {
+	suite.Run(t, new(testPolicyKeeper))
+}
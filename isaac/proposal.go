@@ -20,7 +20,7 @@ var (
 )
 
 func NewProposalHash(b []byte) (hash.Hash, error) {
-	return hash.NewDoubleSHAHash(ProposalHashHint, b)
+	return hash.NewHashByHint(ProposalHashHint, b)
 }
 
 func IsProposalHash(h hash.Hash) bool {
@@ -116,6 +116,10 @@ func (pp Proposal) LastBlock() hash.Hash {
 	return pp.body.lastBlock
 }
 
+func (pp Proposal) Transactions() []hash.Hash {
+	return pp.body.transactions
+}
+
 func (pp Proposal) IsValid() error {
 	if err := pp.BaseSeal.IsValid(); err != nil {
 		return err
@@ -125,11 +129,11 @@ func (pp Proposal) IsValid() error {
 		return err
 	}
 
-	h0, err := pp.body.makeHash()
+	b, err := pp.body.hashBytes()
 	if err != nil {
 		return err
-	} else if !h0.Equal(pp.body.Hash()) {
-		return xerrors.Errorf("hash does not match; expected=%q hash=Tq", h0, pp.body.Hash())
+	} else if err := pp.body.Hash().Verify(b); err != nil {
+		return err
 	}
 
 	return nil
@@ -198,6 +202,10 @@ func (ppb ProposalBody) Proposer() node.Address {
 	return ppb.proposer
 }
 
+func (ppb ProposalBody) Transactions() []hash.Hash {
+	return ppb.transactions
+}
+
 func (ppb ProposalBody) IsValid() error {
 	if err := ppb.hash.IsValid(); err != nil {
 		return err
@@ -262,6 +270,15 @@ func (ppb *ProposalBody) DecodeRLP(s *rlp.Stream) error {
 }
 
 func (ppb ProposalBody) makeHash() (hash.Hash, error) {
+	b, err := ppb.hashBytes()
+	if err != nil {
+		return hash.Hash{}, err
+	}
+
+	return NewProposalHash(b)
+}
+
+func (ppb ProposalBody) hashBytes() ([]byte, error) {
 	body := ProposalBody{
 		height:       ppb.height,
 		round:        ppb.round,
@@ -270,10 +287,5 @@ func (ppb ProposalBody) makeHash() (hash.Hash, error) {
 		transactions: ppb.transactions,
 	}
 
-	b, err := rlp.EncodeToBytes(body)
-	if err != nil {
-		return hash.Hash{}, err
-	}
-
-	return hash.NewDoubleSHAHash(ProposalHashHint, b)
+	return rlp.EncodeToBytes(body)
 }
@@ -6,11 +6,19 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/seal"
 )
 
+// TransactionChecker checks the transaction of proposal; the transaction,
+// which the checker does not know, should be ignored.
+type TransactionChecker interface {
+	CheckTransaction(seal.Seal) error
+}
+
 type ProposalChecker struct {
-	homeState *HomeState
-	suffrage  Suffrage
+	homeState    *HomeState
+	suffrage     Suffrage
+	policyKeeper *PolicyKeeper
 }
 
 func NewProposalCheckerBooting(homeState *HomeState) *common.ChainChecker {
@@ -26,33 +34,47 @@ func NewProposalCheckerBooting(homeState *HomeState) *common.ChainChecker {
 	)
 }
 
-func NewProposalCheckerJoin(homeState *HomeState, suffrage Suffrage) *common.ChainChecker {
+func NewProposalCheckerJoin(
+	homeState *HomeState,
+	suffrage Suffrage,
+	policyKeeper *PolicyKeeper,
+) *common.ChainChecker {
 	pc := ProposalChecker{
-		homeState: homeState,
-		suffrage:  suffrage,
+		homeState:    homeState,
+		suffrage:     suffrage,
+		policyKeeper: policyKeeper,
 	}
 
 	return common.NewChainChecker(
 		"join-proposal-checker",
 		context.Background(),
 		pc.checkInActing,
+		pc.checkSigner,
 		pc.checkHeightAndRoundWithHomeState,
 		pc.checkHeightAndRoundWithLastINITVoteResult,
+		pc.checkTransactions,
 	)
 }
 
-func NewProposalCheckerConsensus(homeState *HomeState, suffrage Suffrage) *common.ChainChecker {
+func NewProposalCheckerConsensus(
+	homeState *HomeState,
+	suffrage Suffrage,
+	policyKeeper *PolicyKeeper,
+) *common.ChainChecker {
 	pc := ProposalChecker{
-		homeState: homeState,
-		suffrage:  suffrage,
+		homeState:    homeState,
+		suffrage:     suffrage,
+		policyKeeper: policyKeeper,
 	}
 
 	return common.NewChainChecker(
 		"join-proposal-checker",
 		context.Background(),
 		pc.checkInActing,
+		pc.checkSigner,
 		pc.checkHeightAndRoundWithHomeState,
 		pc.checkHeightAndRoundWithLastINITVoteResult,
+		pc.checkTransactions,
 	)
 }
 
@@ -74,6 +96,17 @@ func (pc ProposalChecker) checkInActing(c *common.ChainChecker) error {
 	return nil
 }
 
+// checkSigner checks the signer of proposal is the key of proposer at the
+// height.
+func (pc ProposalChecker) checkSigner(c *common.ChainChecker) error {
+	var proposal Proposal
+	if err := c.ContextValue("proposal", &proposal); err != nil {
+		return err
+	}
+
+	return VerifySigner(pc.suffrage, proposal.Proposer(), proposal.Height(), proposal.Signer())
+}
+
 func (pc ProposalChecker) checkHeightAndRoundWithHomeState(c *common.ChainChecker) error {
 	var proposal Proposal
 	if err := c.ContextValue("proposal", &proposal); err != nil {
@@ -135,3 +168,38 @@ func (pc ProposalChecker) checkHeightAndRoundWithLastINITVoteResult(c *common.Ch
 
 	return nil
 }
+
+// checkTransactions checks the transactions of proposal are known and valid;
+// the node does not sign the proposal, which it can not process. The
+// PolicyKeeper and the Suffrage, which is TransactionChecker, check the
+// transactions.
+func (pc ProposalChecker) checkTransactions(c *common.ChainChecker) error {
+	var proposal Proposal
+	if err := c.ContextValue("proposal", &proposal); err != nil {
+		return err
+	}
+
+	transactions, err := pc.policyKeeper.Transactions(proposal)
+	if err != nil {
+		return err
+	}
+
+	checkers := []TransactionChecker{pc.policyKeeper}
+	if tc, ok := pc.suffrage.(TransactionChecker); ok {
+		checkers = append(checkers, tc)
+	}
+
+	for _, tx := range transactions {
+		if err := tx.IsValid(); err != nil {
+			return xerrors.Errorf("invalid transaction in proposal; transaction=%q: %w", tx.Hash(), err)
+		}
+
+		for _, tc := range checkers {
+			if err := tc.CheckTransaction(tx); err != nil {
+				return xerrors.Errorf("invalid transaction in proposal; transaction=%q: %w", tx.Hash(), err)
+			}
+		}
+	}
+
+	return nil
+}
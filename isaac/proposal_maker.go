@@ -13,19 +13,27 @@ type ProposalMaker interface {
 	Make(Height, Round, hash.Hash /* last block */) (Proposal, error)
 }
 
+// OperationPool provides the hashes of the operations, which will be included
+// in the new proposal.
+type OperationPool interface {
+	Pending() []hash.Hash
+}
+
 type DefaultProposalMaker struct {
 	*common.Logger
 	home  node.Home
 	delay time.Duration
+	pools []OperationPool
 }
 
-func NewDefaultProposalMaker(home node.Home, delay time.Duration) DefaultProposalMaker {
+func NewDefaultProposalMaker(home node.Home, delay time.Duration, pools ...OperationPool) DefaultProposalMaker {
 	return DefaultProposalMaker{
 		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
 			return c.Str("module", "proposer-maker")
 		}),
 		home:  home,
 		delay: delay,
+		pools: pools,
 	}
 }
 
@@ -38,9 +46,9 @@ func (dp DefaultProposalMaker) Make(height Height, round Round, lastBlock hash.H
 		Logger()
 	log_.Debug().Msg("ready to make new proposal")
 
-	var started time.Time
+	var started common.Time
 	if dp.delay > 0 {
-		started = time.Now()
+		started = common.Now()
 	}
 
 	proposal, err := NewProposal(
@@ -48,16 +56,16 @@ func (dp DefaultProposalMaker) Make(height Height, round Round, lastBlock hash.H
 		round,
 		lastBlock,
 		dp.home.Address(),
-		nil, // TODO transactions
+		dp.transactions(),
 	)
 	if err != nil {
 		return Proposal{}, err
 	}
 
 	if dp.delay > 0 {
-		since := time.Since(started)
+		since := common.Now().Sub(started)
 		if dp.delay > since {
-			time.Sleep(dp.delay - since)
+			common.Sleep(dp.delay - since)
 		}
 	}
 
@@ -65,3 +73,19 @@ func (dp DefaultProposalMaker) Make(height Height, round Round, lastBlock hash.H
 
 	return proposal, nil
 }
+
+func (dp DefaultProposalMaker) transactions() []hash.Hash {
+	var hs []hash.Hash
+	found := map[hash.Hash]struct{}{}
+	for _, pool := range dp.pools {
+		for _, h := range pool.Pending() {
+			if _, ok := found[h]; ok {
+				continue
+			}
+			found[h] = struct{}{}
+			hs = append(hs, h)
+		}
+	}
+
+	return hs
+}
@@ -0,0 +1,188 @@
+package isaac
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/network"
+	"github.com/spikeekips/mitum/node"
+	"github.com/spikeekips/mitum/seal"
+)
+
+// SealFetcher requests the seals, which are not in the seal storage, to the
+// other nodes and saves the received seals. The proposer of the block is asked
+// at first, because it must have the proposal and it's transactions.
+type SealFetcher struct {
+	*common.Logger
+	home        node.Home
+	nt          network.Network
+	sealStorage SealStorage
+	suffrage    Suffrage
+	verifier    SealVerifier
+	timeout     time.Duration
+}
+
+func NewSealFetcher(home node.Home, nt network.Network, sealStorage SealStorage, suffrage Suffrage) *SealFetcher {
+	return &SealFetcher{
+		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
+			return c.Str("module", "seal-fetcher")
+		}),
+		home:        home,
+		nt:          nt,
+		sealStorage: sealStorage,
+		suffrage:    suffrage,
+		timeout:     time.Second,
+	}
+}
+
+// SetSealVerifier sets the SealVerifier for the received seals; by default,
+// seal.VerifySeal() is used.
+func (sf *SealFetcher) SetSealVerifier(verifier SealVerifier) *SealFetcher {
+	sf.verifier = verifier
+
+	return sf
+}
+
+// FetchProposal fetches the proposal and it's transactions of the block of the
+// height and round.
+func (sf *SealFetcher) FetchProposal(height Height, round Round, h hash.Hash) error {
+	if err := sf.Fetch(height, round, h); err != nil {
+		return err
+	}
+
+	proposal, ok := sf.sealStorage.Get(h).(Proposal)
+	if !ok {
+		return xerrors.Errorf("fetched seal is not Proposal; proposal=%q", h)
+	}
+
+	return sf.Fetch(height, round, proposal.Transactions()...)
+}
+
+// Fetch requests the seals of the hashes, which are not in the seal storage;
+// if any seal is not found from the nodes, it returns error.
+func (sf *SealFetcher) Fetch(height Height, round Round, hashes ...hash.Hash) error {
+	var nodes []node.Address
+	for _, h := range hashes {
+		if sf.sealStorage.Has(h) {
+			continue
+		}
+
+		if nodes == nil {
+			nodes = sf.nodes(height, round)
+		}
+
+		if err := sf.fetch(nodes, h); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nodes returns the nodes to be asked; the proposer comes first.
+func (sf *SealFetcher) nodes(height Height, round Round) []node.Address {
+	var nodes []node.Address
+	if proposer := sf.suffrage.Acting(height, round).Proposer(); proposer != nil {
+		nodes = append(nodes, proposer.Address())
+	}
+
+	for _, n := range sf.suffrage.Nodes() {
+		if n.Address().Equal(sf.home.Address()) || (len(nodes) > 0 && n.Address().Equal(nodes[0])) {
+			continue
+		}
+
+		nodes = append(nodes, n.Address())
+	}
+
+	return nodes
+}
+
+func (sf *SealFetcher) fetch(nodes []node.Address, h hash.Hash) error {
+	request, err := NewRequest(RequestSeal, "hash", h.String())
+	if err != nil {
+		return err
+	}
+
+	for _, n := range nodes {
+		if n.Equal(sf.home.Address()) {
+			continue
+		}
+
+		sl, err := sf.request(n, request)
+		if err != nil {
+			sf.Log().Debug().Err(err).Object("node", n).Object("seal", h).Msg("failed to fetch seal")
+			continue
+		} else if !sl.Hash().Equal(h) {
+			sf.Log().Debug().Object("node", n).Object("seal", h).Msg("fetched different seal")
+			continue
+		}
+
+		if err := sf.verify(sl); err != nil {
+			sf.Log().Error().Err(err).Object("node", n).Object("seal", h).Msg("fetched invalid seal")
+			continue
+		}
+
+		if err := sf.sealStorage.Save(sl); err != nil {
+			return err
+		}
+
+		sf.Log().Debug().Object("node", n).Object("seal", h).Msg("seal fetched")
+
+		return nil
+	}
+
+	return SealNotFoundError.Newf("seal not found from nodes; seal=%q", h)
+}
+
+func (sf *SealFetcher) request(n node.Address, request seal.Seal) (seal.Seal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sf.timeout)
+	defer cancel()
+
+	sl, err := sf.nt.Request(ctx, n, request)
+	if err != nil {
+		return nil, err
+	} else if sl == nil {
+		return nil, xerrors.Errorf("empty response")
+	}
+
+	return sl, nil
+}
+
+func (sf *SealFetcher) verify(sl seal.Seal) error {
+	if sf.verifier == nil {
+		return seal.VerifySeal(sl, nil)
+	}
+
+	return sf.verifier.Verify(sl)
+}
+
+// ResponseSealRequest returns the seal of the RequestSeal from the seal
+// storage.
+func ResponseSealRequest(sealStorage SealStorage, sl seal.Seal) (seal.Seal, error) {
+	request, ok := sl.(Request)
+	if !ok || request.Request() != RequestSeal {
+		return nil, xerrors.Errorf("not seal request; seal=%q", sl.Hash())
+	}
+
+	s, ok := request.Params()["hash"].(string)
+	if !ok {
+		return nil, xerrors.Errorf("hash of seal request is missing")
+	}
+
+	h, err := hash.ParseHash(s)
+	if err != nil {
+		return nil, err
+	}
+
+	found := sealStorage.Get(h)
+	if found == nil {
+		return nil, SealNotFoundError.Newf("seal=%q", h)
+	}
+
+	return found, nil
+}
@@ -0,0 +1,137 @@
+package isaac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/network"
+	"github.com/spikeekips/mitum/node"
+	"github.com/spikeekips/mitum/seal"
+)
+
+type testSealFetcher struct {
+	suite.Suite
+}
+
+func (t *testSealFetcher) newNetwork(home node.Home, sealStorage SealStorage) *network.ChannelNetwork {
+	return network.NewChannelNetwork(
+		home,
+		func(sl seal.Seal) (seal.Seal, error) {
+			if sealStorage == nil {
+				return nil, xerrors.Errorf("not serving")
+			}
+
+			return ResponseSealRequest(sealStorage, sl)
+		},
+	)
+}
+
+func (t *testSealFetcher) newProposal(proposer node.Home, sealStorage SealStorage) Proposal {
+	home := node.NewRandomHome()
+
+	kr, err := NewKeyRotation(home.Address(), node.NewRandomHome().PublicKey(), NewBlockHeight(10), nil)
+	t.NoError(err)
+	t.NoError(kr.Sign(home.PrivateKey(), nil))
+	t.NoError(sealStorage.Save(kr))
+
+	proposal, err := NewProposal(NewBlockHeight(1), Round(0), NewRandomBlockHash(), proposer.Address(), []hash.Hash{kr.Hash()})
+	t.NoError(err)
+	t.NoError(proposal.Sign(proposer.PrivateKey(), nil))
+	t.NoError(sealStorage.Save(proposal))
+
+	return proposal
+}
+
+func (t *testSealFetcher) TestFetchFromProposer() {
+	home := node.NewRandomHome()
+	proposer := node.NewRandomHome()
+	other := node.NewRandomHome()
+
+	proposerStorage := NewTSealStorage()
+	proposal := t.newProposal(proposer, proposerStorage)
+
+	nt := t.newNetwork(home, nil)
+	_ = nt.AddMembers(t.newNetwork(proposer, proposerStorage), t.newNetwork(other, nil))
+
+	sealStorage := NewTSealStorage()
+	sf := NewSealFetcher(home, nt, sealStorage, NewFixedProposerSuffrage(proposer, home, proposer, other))
+
+	t.NoError(sf.FetchProposal(proposal.Height(), proposal.Round(), proposal.Hash()))
+
+	t.True(sealStorage.Has(proposal.Hash()))
+	for _, h := range proposal.Transactions() {
+		t.True(sealStorage.Has(h))
+	}
+}
+
+func (t *testSealFetcher) TestFetchFromPeer() {
+	home := node.NewRandomHome()
+	proposer := node.NewRandomHome()
+	other := node.NewRandomHome()
+
+	otherStorage := NewTSealStorage()
+	proposal := t.newProposal(proposer, otherStorage)
+
+	nt := t.newNetwork(home, nil)
+	_ = nt.AddMembers(t.newNetwork(proposer, nil), t.newNetwork(other, otherStorage))
+
+	sealStorage := NewTSealStorage()
+	sf := NewSealFetcher(home, nt, sealStorage, NewFixedProposerSuffrage(proposer, home, proposer, other))
+
+	t.NoError(sf.FetchProposal(proposal.Height(), proposal.Round(), proposal.Hash()))
+
+	t.True(sealStorage.Has(proposal.Hash()))
+	for _, h := range proposal.Transactions() {
+		t.True(sealStorage.Has(h))
+	}
+}
+
+func (t *testSealFetcher) TestNotFound() {
+	home := node.NewRandomHome()
+	proposer := node.NewRandomHome()
+
+	proposal := t.newProposal(proposer, NewTSealStorage())
+
+	nt := t.newNetwork(home, nil)
+	_ = nt.AddMembers(t.newNetwork(proposer, NewTSealStorage()))
+
+	sealStorage := NewTSealStorage()
+	sf := NewSealFetcher(home, nt, sealStorage, NewFixedProposerSuffrage(proposer, home, proposer))
+
+	err := sf.FetchProposal(proposal.Height(), proposal.Round(), proposal.Hash())
+	t.True(xerrors.Is(err, SealNotFoundError))
+	t.False(sealStorage.Has(proposal.Hash()))
+}
+
+func (t *testSealFetcher) TestInvalidSeal() {
+	home := node.NewRandomHome()
+	proposer := node.NewRandomHome()
+
+	proposerStorage := NewTSealStorage()
+	proposal := t.newProposal(proposer, proposerStorage)
+
+	// NOTE the proposer responds with the different seal
+	pn := network.NewChannelNetwork(
+		proposer,
+		func(sl seal.Seal) (seal.Seal, error) {
+			return sl, nil
+		},
+	)
+
+	nt := t.newNetwork(home, nil)
+	_ = nt.AddMembers(pn)
+
+	sealStorage := NewTSealStorage()
+	sf := NewSealFetcher(home, nt, sealStorage, NewFixedProposerSuffrage(proposer, home, proposer))
+
+	err := sf.Fetch(proposal.Height(), proposal.Round(), proposal.Hash())
+	t.True(xerrors.Is(err, SealNotFoundError))
+	t.False(sealStorage.Has(proposal.Hash()))
+}
+
+func TestSealFetcher(t *testing.T) {
+	suite.Run(t, new(testSealFetcher))
+}
@@ -24,6 +24,7 @@ type RequestKind uint
 const (
 	RequestUnknown RequestKind = iota
 	RequestVoteProof
+	RequestSeal
 )
 
 func (rs RequestKind) MarshalJSON() ([]byte, error) {
@@ -32,7 +33,7 @@ func (rs RequestKind) MarshalJSON() ([]byte, error) {
 
 func (rs RequestKind) IsValid() error {
 	switch rs {
-	case RequestVoteProof:
+	case RequestVoteProof, RequestSeal:
 		return nil
 	default:
 		return xerrors.Errorf("unknown request; %q", rs)
@@ -43,6 +44,8 @@ func (rs RequestKind) String() string {
 	switch rs {
 	case RequestVoteProof:
 		return "vote-proof-request"
+	case RequestSeal:
+		return "seal-request"
 	default:
 		return ""
 	}
@@ -188,7 +191,7 @@ func (rb RequestBody) makeHash() (hash.Hash, error) {
 	if err != nil {
 		return hash.Hash{}, err
 	}
-	h, err := hash.NewDoubleSHAHash(RquestHashHint, b)
+	h, err := hash.NewHashByHint(RquestHashHint, b)
 	if err != nil {
 		return hash.Hash{}, err
 	}
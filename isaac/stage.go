@@ -55,7 +55,7 @@ func (s Stage) IsValid() error {
 	case StageINIT:
 	case StageSIGN:
 	case StageACCEPT:
-	//case StageALLCONFIRM:
+	case StageALLCONFIRM:
 	default:
 		return xerrors.Errorf("unknown stage")
 	}
@@ -71,6 +71,8 @@ func (s Stage) Next() Stage {
 		return StageACCEPT
 	case StageACCEPT:
 		return StageINIT
+	case StageALLCONFIRM:
+		return StageINIT
 	default:
 		panic(InvalidStageError)
 	}
@@ -81,6 +83,7 @@ func (s Stage) CanVote() bool {
 	case StageINIT:
 	case StageSIGN:
 	case StageACCEPT:
+	case StageALLCONFIRM:
 	default:
 		return false
 	}
@@ -7,17 +7,38 @@ import (
 
 	"github.com/rs/zerolog"
 	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/hash"
 	"github.com/spikeekips/mitum/node"
 	"github.com/spikeekips/mitum/seal"
 )
 
+// SealReceiver receives the valid seal, which is not handled by
+// StateController.
+type SealReceiver interface {
+	ReceiveSeal(seal.Seal) error
+}
+
+// SealVerifier verifies the incoming seal before StateController handles it;
+// seal.BatchVerifier is the SealVerifier.
+type SealVerifier interface {
+	Verify(seal.Seal) error
+}
+
 type StateController struct {
 	sync.RWMutex
 	*common.Logger
 	homeState        *HomeState
 	compiler         *Compiler
 	sealStorage      SealStorage
+	policyKeeper     *PolicyKeeper
+	forkDetector     *ForkDetector
+	observers        []VoteResultObserver
+	receivers        []SealReceiver
+	verifier         SealVerifier
+	hashAlgorithms   *hash.Algorithms
 	chanState        chan StateContext
+	stop             chan struct{}
+	generation       uint64
 	bootingHandler   StateHandler
 	joinHandler      StateHandler
 	consensusHandler StateHandler
@@ -29,12 +50,12 @@ func NewStateController(
 	homeState *HomeState,
 	compiler *Compiler,
 	sealStorage SealStorage,
+	policyKeeper *PolicyKeeper,
 	bootingHandler StateHandler,
 	joinHandler StateHandler,
 	consensusHandler StateHandler,
 	stoppedHandler StateHandler,
 ) *StateController {
-	chanState := make(chan StateContext)
 	sc := &StateController{
 		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
 			return c.Str("module", "state-controller")
@@ -42,43 +63,158 @@ func NewStateController(
 		homeState:        homeState,
 		compiler:         compiler,
 		sealStorage:      sealStorage,
-		chanState:        chanState,
-		bootingHandler:   bootingHandler.SetChanState(chanState),
-		joinHandler:      joinHandler.SetChanState(chanState),
-		consensusHandler: consensusHandler.SetChanState(chanState),
-		stoppedHandler:   stoppedHandler.SetChanState(chanState),
+		policyKeeper:     policyKeeper,
+		forkDetector:     NewForkDetector(homeState),
+		hashAlgorithms:   hash.DefaultAlgorithms,
+		bootingHandler:   bootingHandler,
+		joinHandler:      joinHandler,
+		consensusHandler: consensusHandler,
+		stoppedHandler:   stoppedHandler,
 	}
 
 	return sc
 }
 
+// AddVoteResultObservers adds the VoteResultObservers; it should be called
+// before Start().
+func (sc *StateController) AddVoteResultObservers(observers ...VoteResultObserver) *StateController {
+	sc.observers = append(sc.observers, observers...)
+
+	return sc
+}
+
+// AddSealReceivers adds the SealReceivers; it should be called before Start().
+func (sc *StateController) AddSealReceivers(receivers ...SealReceiver) *StateController {
+	sc.receivers = append(sc.receivers, receivers...)
+
+	return sc
+}
+
+// SetSealVerifier sets the SealVerifier; without SealVerifier, the seal is
+// verified one by one. It should be called before Start().
+func (sc *StateController) SetSealVerifier(verifier SealVerifier) *StateController {
+	sc.verifier = verifier
+
+	return sc
+}
+
+// SetHashAlgorithms sets the hash Algorithms of the network; the hashes of the
+// incoming seal should be made by the Algorithm of their hint. By default,
+// hash.DefaultAlgorithms is used. It should be called before Start().
+func (sc *StateController) SetHashAlgorithms(as *hash.Algorithms) *StateController {
+	sc.hashAlgorithms = as
+
+	return sc
+}
+
+// SetSuffrage sets the Suffrage and Threshold of ForkDetector, so the blocks of
+// the ballots from peers are also checked. It should be called before Start().
+func (sc *StateController) SetSuffrage(suffrage Suffrage, threshold *Threshold) *StateController {
+	_ = sc.forkDetector.SetSuffrage(suffrage, threshold)
+
+	return sc
+}
+
+// Start starts from booting state. StateController can be started again after
+// Stop().
 func (sc *StateController) Start() error {
-	go sc.loopState()
+	sc.Lock()
+	if sc.stop != nil {
+		sc.Unlock()
+		return common.DaemonAleadyStartedError
+	}
+
+	// NOTE the new channel is made at every start and the StateContext is
+	// tagged with the generation of start, so the StateContext from the
+	// handlers of previous start can not reach the new handlers.
+	sc.generation++
+	generation := sc.generation
+
+	chanState := make(chan StateContext)
+	stop := make(chan struct{})
+	for _, handler := range []StateHandler{
+		sc.bootingHandler,
+		sc.joinHandler,
+		sc.consensusHandler,
+		sc.stoppedHandler,
+	} {
+		_ = handler.SetChanState(chanState, stop)
+	}
+
+	sc.chanState = chanState
+	sc.stop = stop
+
+	go sc.loopState(chanState, stop, generation)
+	sc.Unlock()
 
 	// start booting
-	if err := sc.setState(NewStateContext(node.StateBooting)); err != nil {
+	current := sc.homeState.State()
+
+	sct := NewStateContext(node.StateBooting)
+	sct.generation = generation
+	if err := sc.setState(sct); err != nil {
 		return err
 	}
 
+	sc.logStateChanged(current, node.StateBooting)
+
 	return nil
 }
 
+// Stop deactivates the current StateHandler and the state of HomeState becomes
+// stopped; the StateContext sent by the deactivated handlers after Stop() is
+// dropped, so the senders are not blocked.
 func (sc *StateController) Stop() error {
-	if err := sc.StateHandler().Deactivate(); err != nil {
-		return err
+	sc.Lock()
+	if sc.stop == nil {
+		sc.Unlock()
+		return nil
 	}
 
-	sc.Lock()
-	defer sc.Unlock()
+	close(sc.stop)
+	sc.stop = nil
 
-	close(sc.chanState)
+	handler := sc.stateHandler
 	sc.stateHandler = nil
+	current := sc.homeState.State()
+	_ = sc.homeState.SetState(node.StateStopped)
+	sc.Unlock()
 
-	return nil
+	sc.logStateChanged(current, node.StateStopped)
+
+	if handler == nil {
+		return nil
+	}
+
+	return handler.Deactivate()
 }
 
-func (sc *StateController) loopState() {
-	for sct := range sc.chanState {
+func (sc *StateController) IsStopped() bool {
+	sc.RLock()
+	defer sc.RUnlock()
+
+	return sc.stop == nil
+}
+
+func (sc *StateController) loopState(chanState chan StateContext, stop chan struct{}, generation uint64) {
+	for {
+		var sct StateContext
+		select {
+		case <-stop:
+			return
+		case sct = <-chanState:
+		}
+
+		if sct.generation != generation {
+			sc.Log().Debug().
+				Uint64("generation", generation).
+				Uint64("generation_state", sct.generation).
+				Str("new_state", sct.State().String()).
+				Msg("StateContext of previous start; dropped")
+
+			continue
+		}
+
 		current := sc.homeState.State()
 		if err := sc.setState(sct); err != nil {
 			sc.Log().Error().
@@ -87,14 +223,18 @@ func (sc *StateController) loopState() {
 				Str("new_state", sct.State().String()).
 				Msg("error change state")
 		} else {
-			sc.Log().Info().
-				Str("current_state", current.String()).
-				Str("new_state", sct.State().String()).
-				Msg("state changed")
+			sc.logStateChanged(current, sct.State())
 		}
 	}
 }
 
+func (sc *StateController) logStateChanged(current, state node.State) {
+	sc.Log().Info().
+		Str("current_state", current.String()).
+		Str("new_state", state.String()).
+		Msg("state changed")
+}
+
 func (sc *StateController) setState(sct StateContext) error {
 	if err := sct.State().IsValid(); err != nil {
 		return err
@@ -104,6 +244,11 @@ func (sc *StateController) setState(sct StateContext) error {
 		return xerrors.Errorf("same state")
 	}
 
+	// NOTE once fork detected, node can not escape from stopped state
+	if evidence, found := sc.forkDetector.Evidence(); found && sct.State() != node.StateStopped {
+		return ForkDetectedError.Newf("node halted; %v", evidence)
+	}
+
 	// stop previous StateHandler and start new StateHandler
 	if sc.StateHandler() != nil {
 		if err := sc.StateHandler().Deactivate(); err != nil {
@@ -128,6 +273,10 @@ func (sc *StateController) setState(sct StateContext) error {
 	sc.Lock()
 	defer sc.Unlock()
 
+	if sc.stop == nil {
+		return xerrors.Errorf("StateController stopped; state=%v", sct.State())
+	}
+
 	if err := handler.Activate(sct); err != nil {
 		return err
 	}
@@ -145,11 +294,15 @@ func (sc *StateController) Receive(message interface{}) error {
 		return xerrors.Errorf("receive unknown message; message=%q", message)
 	}
 
+	if evidence, found := sc.forkDetector.Evidence(); found {
+		return ForkDetectedError.Newf("node halted; %v", evidence)
+	}
+
 	sc.Log().Debug().
 		Object("seal", sl).
 		Msgf("seal received; %v", sl.Type())
 
-	if err := sl.IsValid(); err != nil {
+	if err := sc.verifySeal(sl); err != nil {
 		sc.Log().Error().Err(err).Object("seal", sl.Hash()).Msg("invalid seal")
 		return err
 	}
@@ -175,9 +328,50 @@ func (sc *StateController) Receive(message interface{}) error {
 			return xerrors.Errorf("seal.Type() is ballot, but it's not; message=%q", message)
 		}
 
+		// NOTE the ballots, which are not voted, are also checked
+		if err := sc.forkDetector.CheckBallot(ballot); err != nil {
+			sc.halt()
+			return err
+		}
+
 		if err := sc.handleBallot(ballot); err != nil {
 			return err
 		}
+	case PolicyChangeType:
+		pc, ok := sl.(PolicyChange)
+		if !ok {
+			return xerrors.Errorf("seal.Type() is policy change, but it's not; message=%q", message)
+		}
+
+		if err := sc.policyKeeper.AddPending(pc); err != nil {
+			return err
+		}
+	default:
+		for _, r := range sc.receivers {
+			if err := r.ReceiveSeal(sl); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (sc *StateController) verifySeal(sl seal.Seal) error {
+	if sc.verifier == nil {
+		if err := seal.VerifySeal(sl, nil); err != nil {
+			return err
+		}
+	} else if err := sc.verifier.Verify(sl); err != nil {
+		return err
+	}
+
+	// NOTE seal.IsValid() verifies the hashes by the algorithm of the hashes,
+	// which is chosen by the sender
+	for _, h := range []hash.Hash{sl.Hash(), sl.Body().Hash()} {
+		if err := sc.hashAlgorithms.Check(h); err != nil {
+			return seal.InvalidSealError.New(err)
+		}
 	}
 
 	return nil
@@ -214,6 +408,15 @@ func (sc *StateController) handleBallot(ballot Ballot) error {
 		return nil
 	}
 
+	if err := sc.forkDetector.CheckVoteResult(vr); err != nil {
+		sc.halt()
+		return err
+	}
+
+	for _, o := range sc.observers {
+		o.ObserveVoteResult(vr)
+	}
+
 	if sc.StateHandler() == nil {
 		return nil
 	}
@@ -223,5 +426,42 @@ func (sc *StateController) handleBallot(ballot Ballot) error {
 		return err
 	}
 
+	if err := sc.forkDetector.CheckStored(); err != nil {
+		sc.halt()
+		return err
+	}
+
 	return nil
 }
+
+// ForkEvidence returns the reason why the node is halted; if fork is not
+// detected, false is returned.
+func (sc *StateController) ForkEvidence() (ForkEvidence, bool) {
+	return sc.forkDetector.Evidence()
+}
+
+// halt moves to the stopped state safely with ForkEvidence.
+func (sc *StateController) halt() {
+	evidence, found := sc.forkDetector.Evidence()
+	if !found {
+		return
+	}
+
+	sc.Log().Error().Object("evidence", evidence).Msg("fork detected; node will be halted")
+
+	sc.RLock()
+	chanState, stop, generation := sc.chanState, sc.stop, sc.generation
+	sc.RUnlock()
+
+	if stop == nil {
+		return
+	}
+
+	sct := NewStateContext(node.StateStopped).SetContext("fork", evidence)
+	sct.generation = generation
+
+	select {
+	case <-stop:
+	case chanState <- sct:
+	}
+}
@@ -0,0 +1,234 @@
+package isaac
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/network"
+	"github.com/spikeekips/mitum/node"
+	"github.com/spikeekips/mitum/seal"
+)
+
+type testStateController struct {
+	suite.Suite
+}
+
+func (t *testStateController) newStateController() (*StateController, *HomeState, func()) {
+	home := node.NewRandomHome()
+	lastBlock := NewRandomBlock()
+	nextBlock := NewRandomNextBlock(lastBlock)
+
+	homeState := NewHomeState(home, lastBlock)
+	_ = homeState.SetBlock(nextBlock)
+
+	suffrage := NewFixedProposerSuffrage(home, home)
+	ballotChecker := NewCompilerBallotChecker(homeState, suffrage)
+
+	thr, _ := NewThreshold(1, 67)
+	cm := NewCompiler(homeState, NewBallotbox(thr), ballotChecker)
+
+	cn := network.NewChannelNetwork(
+		home,
+		func(sl seal.Seal) (seal.Seal, error) {
+			return sl, xerrors.Errorf("echo back")
+		},
+	)
+	t.NoError(cn.Start())
+
+	ss := NewTSealStorage()
+	policyKeeper, err := NewPolicyKeeper(homeState, thr, ss, Policy{
+		Threshold:                         67,
+		IntervalBroadcastINITBallotInJoin: time.Second * 3,
+		TimeoutWaitVoteResultInJoin:       time.Second * 6,
+		TimeoutWaitBallot:                 time.Second,
+		TimeoutWaitINITBallot:             time.Second,
+	})
+	t.NoError(err)
+
+	pv := NewDummyProposalValidator()
+	ballotMaker := NewDefaultBallotMaker(home)
+
+	js, err := NewJoinStateHandler(homeState, cm, cn, suffrage, ballotMaker, pv, policyKeeper)
+	t.NoError(err)
+
+	sc := NewStateController(
+		homeState,
+		cm,
+		ss,
+		policyKeeper,
+		NewBootingStateHandler(homeState),
+		js,
+		NewStoppedStateHandler(), // NOTE consensus state is not reached in these tests
+		NewStoppedStateHandler(),
+	)
+
+	return sc, homeState, func() {
+		_ = sc.Stop()
+		_ = cn.Stop()
+	}
+}
+
+func (t *testStateController) waitState(homeState *HomeState, state node.State) {
+	timeout := time.After(time.Second * 2)
+	for {
+		if homeState.State() == state {
+			return
+		}
+
+		select {
+		case <-timeout:
+			t.Failf("timed out", "wait state changing to %v; current=%v", state, homeState.State())
+			return
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+}
+
+func (t *testStateController) TestStartStop() {
+	sc, homeState, closeFunc := t.newStateController()
+	defer closeFunc()
+
+	t.True(sc.IsStopped())
+
+	t.NoError(sc.Start())
+	t.False(sc.IsStopped())
+	t.True(xerrors.Is(sc.Start(), common.DaemonAleadyStartedError))
+
+	t.waitState(homeState, node.StateJoining)
+
+	t.NoError(sc.Stop())
+	t.True(sc.IsStopped())
+	t.Nil(sc.StateHandler())
+
+	// NOTE stop again is ok
+	t.NoError(sc.Stop())
+}
+
+func (t *testStateController) TestRestart() {
+	sc, homeState, closeFunc := t.newStateController()
+	defer closeFunc()
+
+	for i := 0; i < 3; i++ {
+		t.NoError(sc.Start())
+		t.waitState(homeState, node.StateJoining)
+		t.Equal(node.StateJoining, sc.StateHandler().State())
+
+		t.NoError(sc.Stop())
+		t.Nil(sc.StateHandler())
+		t.Equal(node.StateStopped, homeState.State())
+	}
+}
+
+func (t *testStateController) TestIgnoreStateAfterStop() {
+	sc, homeState, closeFunc := t.newStateController()
+	defer closeFunc()
+
+	t.NoError(sc.Start())
+	t.waitState(homeState, node.StateJoining)
+
+	sc.RLock()
+	chanState := sc.chanState
+	sc.RUnlock()
+
+	t.NoError(sc.Stop())
+
+	// NOTE the StateContext sent from the deactivated handler is not received
+	select {
+	case chanState <- NewStateContext(node.StateStopped):
+		t.Fail("StateContext should not be received after stop")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	t.Equal(node.StateStopped, homeState.State())
+	t.Nil(sc.StateHandler())
+}
+
+func (t *testStateController) TestHandlerNotBlockedAfterStop() {
+	sc, homeState, closeFunc := t.newStateController()
+	defer closeFunc()
+
+	t.NoError(sc.Start())
+	t.waitState(homeState, node.StateJoining)
+
+	t.NoError(sc.Stop())
+
+	// NOTE the deactivated handler sends StateContext after stop
+	done := make(chan struct{})
+	go func() {
+		_ = sc.joinHandler.(*JoinStateHandler).gotINITMajority(
+			NewVoteResult(homeState.Block().Height().Add(1), Round(0), StageINIT),
+		)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fail("handler should not be blocked after stop")
+	}
+}
+
+func (t *testStateController) TestDropStateOfPreviousStart() {
+	sc, homeState, closeFunc := t.newStateController()
+	defer closeFunc()
+
+	js := sc.joinHandler.(*JoinStateHandler)
+
+	t.NoError(sc.Start())
+	t.waitState(homeState, node.StateJoining)
+
+	previous := js.chanState.activated()
+
+	t.NoError(sc.Stop())
+	t.NoError(sc.Start())
+	t.waitState(homeState, node.StateJoining)
+
+	// NOTE the goroutine of previous start sends StateContext after restart
+	js.chanState.sendWithGeneration(previous, NewStateContext(node.StateStopped))
+
+	<-time.After(time.Millisecond * 100)
+	t.Equal(node.StateJoining, homeState.State())
+
+	// NOTE the StateContext of current start is accepted
+	js.chanState.send(NewStateContext(node.StateStopped))
+	t.waitState(homeState, node.StateStopped)
+}
+
+func (t *testStateController) TestHashAlgorithm() {
+	sc, homeState, closeFunc := t.newStateController()
+	defer closeFunc()
+
+	as := hash.NewAlgorithms(hash.DoubleSHA256)
+	t.NoError(as.Set(BallotHashHint, hash.Blake2b256))
+	_ = sc.SetHashAlgorithms(as)
+
+	home := homeState.Home()
+	block := homeState.Block()
+
+	// NOTE the ballot hash is made by DefaultAlgorithms, DoubleSHA256
+	ballot, err := NewINITBallot(
+		home.Address(),
+		homeState.PreviousBlock().Hash(),
+		homeState.PreviousBlock().Round(),
+		block.Height().Add(1),
+		block.Hash(),
+		Round(0),
+		block.Proposal(),
+	)
+	t.NoError(err)
+	t.NoError(ballot.Sign(home.PrivateKey(), nil))
+	t.NoError(ballot.IsValid())
+
+	err = sc.Receive(ballot)
+	t.True(xerrors.Is(err, seal.InvalidSealError))
+	t.Contains(err.Error(), "unexpected algorithm")
+}
+
+func TestStateController(t *testing.T) {
+	suite.Run(t, new(testStateController))
+}
@@ -3,8 +3,10 @@ package isaac
 import (
 	"context"
 	"reflect"
+	"sync"
 
 	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/hash"
 	"github.com/spikeekips/mitum/node"
 )
 
@@ -13,14 +15,47 @@ type StateHandler interface {
 	Activate(StateContext) error
 	Deactivate() error
 	State() node.State
-	SetChanState(chan StateContext) StateHandler
+	SetChanState(chan StateContext, chan struct{}) StateHandler
 	ReceiveVoteResult(VoteResult) error
 	ReceiveProposal(Proposal) error
 }
 
+// BlockStorer applies the new block before the block is set to HomeState; if
+// it fails, the node can not follow the next height, so the block is not
+// stored. The Suffrage, which is BlockStorer, is applied after PolicyKeeper.
+type BlockStorer interface {
+	StoreBlock(Block) error
+}
+
+// fetchProposal fetches the proposal and it's transactions, which are missing
+// in the seal storage; without SealFetcher, nothing is fetched.
+func fetchProposal(fetcher *SealFetcher, height Height, round Round, proposal hash.Hash) error {
+	if fetcher == nil {
+		return nil
+	}
+
+	return fetcher.FetchProposal(height, round, proposal)
+}
+
+func storeBlock(block Block, policyKeeper *PolicyKeeper, suffrage Suffrage) error {
+	if err := policyKeeper.StoreBlock(block); err != nil {
+		return err
+	}
+
+	if bs, ok := suffrage.(BlockStorer); ok {
+		return bs.StoreBlock(block)
+	}
+
+	return nil
+}
+
+// StateContext is the request to change the state. The generation is the
+// start of StateController, in which the request is made; StateController
+// drops the request of the previous start.
 type StateContext struct {
-	state node.State
-	ctx   context.Context
+	state      node.State
+	ctx        context.Context
+	generation uint64
 }
 
 func NewStateContext(state node.State) StateContext {
@@ -58,3 +93,60 @@ func (sc StateContext) ContextValue(key interface{}, value interface{}) error {
 
 	return nil
 }
+
+// stateChannel passes the StateContext from StateHandler to StateController.
+// After StateController is stopped, the StateContext is dropped instead of
+// blocking the sender. The StateContext is tagged with the generation of the
+// last activation; the goroutines, which can outlive the activation, should
+// keep the generation of their activation and send with it.
+type stateChannel struct {
+	sync.RWMutex
+	ch         chan StateContext
+	stop       chan struct{}
+	generation uint64
+}
+
+func (sc *stateChannel) set(ch chan StateContext, stop chan struct{}) {
+	sc.Lock()
+	defer sc.Unlock()
+
+	sc.ch = ch
+	sc.stop = stop
+}
+
+// activate keeps the generation of the StateContext, which activates the
+// StateHandler.
+func (sc *stateChannel) activate(sct StateContext) {
+	sc.Lock()
+	defer sc.Unlock()
+
+	sc.generation = sct.generation
+}
+
+func (sc *stateChannel) activated() uint64 {
+	sc.RLock()
+	defer sc.RUnlock()
+
+	return sc.generation
+}
+
+func (sc *stateChannel) send(sct StateContext) {
+	sc.sendWithGeneration(sc.activated(), sct)
+}
+
+func (sc *stateChannel) sendWithGeneration(generation uint64, sct StateContext) {
+	sc.RLock()
+	ch, stop := sc.ch, sc.stop
+	sc.RUnlock()
+
+	if ch == nil {
+		return
+	}
+
+	sct.generation = generation
+
+	select {
+	case <-stop:
+	case ch <- sct:
+	}
+}
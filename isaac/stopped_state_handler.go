@@ -47,7 +47,12 @@ func (ss *StoppedStateHandler) IsStopped() bool {
 	return ss.started
 }
 
-func (ss *StoppedStateHandler) Activate(StateContext) error {
+func (ss *StoppedStateHandler) Activate(sct StateContext) error {
+	var evidence ForkEvidence
+	if err := sct.ContextValue("fork", &evidence); err == nil {
+		ss.Log().Error().Object("evidence", evidence).Msg("stopped by fork")
+	}
+
 	return nil
 }
 
@@ -55,7 +60,7 @@ func (ss *StoppedStateHandler) Deactivate() error {
 	return nil
 }
 
-func (ss *StoppedStateHandler) SetChanState(chan StateContext) StateHandler {
+func (ss *StoppedStateHandler) SetChanState(chan StateContext, chan struct{}) StateHandler {
 	return ss
 }
 
@@ -0,0 +1,305 @@
+package isaac
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/keypair"
+	"github.com/spikeekips/mitum/node"
+	"github.com/spikeekips/mitum/seal"
+)
+
+// SignerVerifier verifies the signer is the key of node at the height.
+type SignerVerifier interface {
+	VerifySigner(node.Address, Height, keypair.PublicKey) error
+}
+
+// VerifySigner checks the signer is the key of node at the height. If the
+// Suffrage does not know the key rotations, the node address should be derived
+// from the signer.
+func VerifySigner(suffrage Suffrage, address node.Address, height Height, signer keypair.PublicKey) error {
+	if sv, ok := suffrage.(SignerVerifier); ok {
+		return sv.VerifySigner(address, height, signer)
+	}
+
+	return address.Verify(node.DefaultNetworkID, signer)
+}
+
+// KeySuffrage wraps Suffrage and keeps the KeyRotations of the nodes.
+// * the received KeyRotation is pending until it is included in the proposal
+// of the stored block
+// * the KeyRotations of the stored block are applied from their height
+// * Acting() returns the nodes with the key, which is valid at the height
+//
+// KeySuffrage should be the outermost Suffrage, so the other wrappers can not
+// hide SignerVerifier.
+type KeySuffrage struct {
+	sync.RWMutex
+	*common.Logger
+	Suffrage
+	sealStorage SealStorage
+	pending     map[hash.Hash]KeyRotation
+	rotations   map[node.Address][]KeyRotation // NOTE sorted by height
+	applied     map[hash.Hash]struct{}
+}
+
+func NewKeySuffrage(suffrage Suffrage, sealStorage SealStorage) *KeySuffrage {
+	return &KeySuffrage{
+		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
+			return c.Str("module", "key-suffrage")
+		}),
+		Suffrage:    suffrage,
+		sealStorage: sealStorage,
+		pending:     map[hash.Hash]KeyRotation{},
+		rotations:   map[node.Address][]KeyRotation{},
+		applied:     map[hash.Hash]struct{}{},
+	}
+}
+
+func (ks *KeySuffrage) AddNodes(nodes ...node.Node) Suffrage {
+	ks.Lock()
+	defer ks.Unlock()
+
+	ks.Suffrage = ks.Suffrage.AddNodes(nodes...)
+
+	return ks
+}
+
+func (ks *KeySuffrage) RemoveNodes(nodes ...node.Node) Suffrage {
+	ks.Lock()
+	defer ks.Unlock()
+
+	ks.Suffrage = ks.Suffrage.RemoveNodes(nodes...)
+
+	return ks
+}
+
+// StoreBlock applies the KeyRotations in the proposal of the new block and
+// stores the block to the inner Suffrage, which is BlockStorer. Like
+// PolicyKeeper.StoreBlock, the proposal and the transactions of the block
+// should be in the seal storage.
+func (ks *KeySuffrage) StoreBlock(block Block) error {
+	rotations, err := ks.keyRotations(block)
+	if err != nil {
+		return err
+	}
+
+	ks.Lock()
+	for _, kr := range rotations {
+		ks.apply(block, kr)
+	}
+	bs, ok := ks.Suffrage.(BlockStorer)
+	ks.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	return bs.StoreBlock(block)
+}
+
+func (ks *KeySuffrage) keyRotations(block Block) ([]KeyRotation, error) {
+	sl := ks.sealStorage.Get(block.Proposal())
+	if sl == nil {
+		return nil, xerrors.Errorf("proposal of block not found in storage; proposal=%q", block.Proposal())
+	}
+
+	proposal, ok := sl.(Proposal)
+	if !ok {
+		return nil, xerrors.Errorf("proposal of block is not Proposal; proposal=%q", block.Proposal())
+	}
+
+	var rotations []KeyRotation
+	for _, h := range proposal.Transactions() {
+		sl := ks.sealStorage.Get(h)
+		if sl == nil {
+			return nil, xerrors.Errorf("transaction of proposal not found in storage; transaction=%q", h)
+		}
+
+		if kr, ok := sl.(KeyRotation); ok {
+			rotations = append(rotations, kr)
+		}
+	}
+
+	return rotations, nil
+}
+
+// Acting returns the ActingSuffrage, whose nodes have the key of the height.
+func (ks *KeySuffrage) Acting(height Height, round Round) ActingSuffrage {
+	ks.RLock()
+	defer ks.RUnlock()
+
+	acting := ks.Suffrage.Acting(height, round)
+	if len(ks.rotations) < 1 {
+		return acting
+	}
+
+	nodes := make([]node.Node, len(acting.Nodes()))
+	for i, n := range acting.Nodes() {
+		nodes[i] = ks.withKey(n, height)
+	}
+
+	var proposer node.Node
+	if acting.Proposer() != nil {
+		proposer = ks.withKey(acting.Proposer(), height)
+	}
+
+	return NewActingSuffrage(height, round, proposer, nodes)
+}
+
+func (ks *KeySuffrage) withKey(n node.Node, height Height) node.Node {
+	pk, found := ks.publicKey(n.Address(), height)
+	if !found || pk.Equal(n.PublicKey()) {
+		return n
+	}
+
+	return node.NewOther(n.Address(), pk).SetAlias(n.Alias())
+}
+
+// PublicKey returns the rotated key of node at the height; if the key of node
+// is not rotated, found is false.
+func (ks *KeySuffrage) PublicKey(address node.Address, height Height) (keypair.PublicKey, bool) {
+	ks.RLock()
+	defer ks.RUnlock()
+
+	return ks.publicKey(address, height)
+}
+
+func (ks *KeySuffrage) publicKey(address node.Address, height Height) (keypair.PublicKey, bool) {
+	rotations := ks.rotations[address]
+	for i := len(rotations) - 1; i >= 0; i-- {
+		if rotations[i].Height().Cmp(height) <= 0 {
+			return rotations[i].PublicKey(), true
+		}
+	}
+
+	return nil, false
+}
+
+func (ks *KeySuffrage) VerifySigner(address node.Address, height Height, signer keypair.PublicKey) error {
+	ks.RLock()
+	defer ks.RUnlock()
+
+	return ks.verifySigner(address, height, signer)
+}
+
+func (ks *KeySuffrage) verifySigner(address node.Address, height Height, signer keypair.PublicKey) error {
+	pk, found := ks.publicKey(address, height)
+	if !found {
+		return address.Verify(node.DefaultNetworkID, signer)
+	}
+
+	if !pk.Equal(signer) {
+		return node.InvalidAddressError.Newf(
+			"signer is not the key of node at height; node=%q height=%q signer=%q",
+			address,
+			height,
+			signer,
+		)
+	}
+
+	return nil
+}
+
+// ReceiveSeal keeps the received KeyRotation in pending.
+func (ks *KeySuffrage) ReceiveSeal(sl seal.Seal) error {
+	kr, ok := sl.(KeyRotation)
+	if !ok {
+		return nil
+	}
+
+	return ks.AddPending(kr)
+}
+
+// AddPending keeps the KeyRotation until it is included in the agreed
+// proposal.
+func (ks *KeySuffrage) AddPending(kr KeyRotation) error {
+	if err := kr.IsValid(); err != nil {
+		return err
+	}
+
+	ks.Lock()
+	defer ks.Unlock()
+
+	if err := ks.checkKeyRotation(kr); err != nil {
+		return err
+	}
+
+	ks.pending[kr.Hash()] = kr
+
+	ks.Log().Debug().Object("key_rotation", kr).Msg("new KeyRotation added to pending")
+
+	return nil
+}
+
+func (ks *KeySuffrage) checkKeyRotation(kr KeyRotation) error {
+	if _, found := ks.applied[kr.Hash()]; found {
+		return xerrors.Errorf("KeyRotation already applied; key_rotation=%q", kr.Hash())
+	}
+
+	height, ok := kr.Height().SubOK(1)
+	if !ok {
+		height = GenesisHeight
+	}
+
+	if !ks.Suffrage.Exists(height, kr.Node()) {
+		return xerrors.Errorf("node of KeyRotation is not in suffrage; node=%q", kr.Node())
+	}
+
+	// NOTE KeyRotation should be signed by the key, which is valid at the
+	// height
+	return ks.verifySigner(kr.Node(), kr.Height(), kr.Signer())
+}
+
+// Pending returns the hashes of KeyRotations, which are not yet included in
+// the stored block. Pending can be used for OperationPool of ProposalMaker.
+func (ks *KeySuffrage) Pending() []hash.Hash {
+	ks.RLock()
+	defer ks.RUnlock()
+
+	var hs []hash.Hash
+	for h := range ks.pending {
+		hs = append(hs, h)
+	}
+
+	sort.Slice(hs, func(i, j int) bool {
+		return hs[i].String() < hs[j].String()
+	})
+
+	return hs
+}
+
+func (ks *KeySuffrage) apply(block Block, kr KeyRotation) {
+	if _, found := ks.applied[kr.Hash()]; found {
+		return
+	}
+
+	delete(ks.pending, kr.Hash())
+
+	log_ := ks.Log().With().Object("key_rotation", kr).Object("block", block).Logger()
+
+	if kr.Height().Cmp(block.Height()) < 1 {
+		log_.Warn().Msg("KeyRotation is included in block, but it's height is already passed; ignored")
+		return
+	}
+
+	if err := ks.checkKeyRotation(kr); err != nil {
+		log_.Warn().Err(err).Msg("invalid KeyRotation is included in block; ignored")
+		return
+	}
+
+	ks.applied[kr.Hash()] = struct{}{}
+
+	rotations := append(ks.rotations[kr.Node()], kr)
+	sort.SliceStable(rotations, func(i, j int) bool {
+		return rotations[i].Height().Cmp(rotations[j].Height()) < 0
+	})
+	ks.rotations[kr.Node()] = rotations
+
+	log_.Info().Msg("KeyRotation applied")
+}
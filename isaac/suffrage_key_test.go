@@ -0,0 +1,290 @@
+package isaac
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/keypair"
+	"github.com/spikeekips/mitum/node"
+)
+
+type testKeySuffrage struct {
+	suite.Suite
+}
+
+func (t *testKeySuffrage) newSuffrage() (*KeySuffrage, *TSealStorage, node.Home) {
+	home := node.NewRandomHome()
+	nodes := []node.Node{home}
+	for i := 0; i < 3; i++ {
+		nodes = append(nodes, node.NewRandomHome())
+	}
+
+	ss := NewTSealStorage()
+
+	return NewKeySuffrage(NewFixedProposerSuffrage(home, nodes...), ss), ss, home
+}
+
+func (t *testKeySuffrage) newKeyRotation(
+	address node.Address,
+	signer keypair.PrivateKey,
+	height Height,
+) (KeyRotation, keypair.PrivateKey) {
+	pk, _ := keypair.NewStellarPrivateKey()
+
+	kr, err := NewKeyRotation(address, pk.PublicKey(), height, nil)
+	t.NoError(err)
+	t.NoError(kr.Sign(signer, nil))
+
+	return kr, pk
+}
+
+// agree stores the KeyRotations into the proposal of height and stores the
+// block of it.
+func (t *testKeySuffrage) agree(ks *KeySuffrage, ss SealStorage, home node.Home, height Height, krs ...KeyRotation) {
+	var transactions []hash.Hash
+	for _, kr := range krs {
+		_ = ss.Save(kr)
+		transactions = append(transactions, kr.Hash())
+	}
+
+	proposal, err := NewProposal(height, Round(0), NewRandomBlockHash(), home.Address(), transactions)
+	t.NoError(err)
+	t.NoError(proposal.Sign(home.PrivateKey(), nil))
+	t.NoError(ss.Save(proposal))
+
+	block, err := NewBlock(height, Round(0), proposal.Hash())
+	t.NoError(err)
+	t.NoError(ks.StoreBlock(block))
+}
+
+func (t *testKeySuffrage) TestKeyRotationRLP() {
+	home := node.NewRandomHome()
+	kr, _ := t.newKeyRotation(home.Address(), home.PrivateKey(), NewBlockHeight(10))
+
+	b, err := rlp.EncodeToBytes(kr)
+	t.NoError(err)
+
+	var decoded KeyRotation
+	t.NoError(rlp.DecodeBytes(b, &decoded))
+	t.True(kr.Hash().Equal(decoded.Hash()))
+	t.True(kr.Node().Equal(decoded.Node()))
+	t.True(kr.PublicKey().Equal(decoded.PublicKey()))
+	t.True(kr.Height().Equal(decoded.Height()))
+}
+
+func (t *testKeySuffrage) TestRotate() {
+	ks, ss, home := t.newSuffrage()
+
+	height := NewBlockHeight(10)
+	kr, newKey := t.newKeyRotation(home.Address(), home.PrivateKey(), height.Add(2))
+	t.NoError(ks.AddPending(kr))
+	t.Equal([]hash.Hash{kr.Hash()}, ks.Pending())
+
+	t.agree(ks, ss, home, height, kr)
+	t.Empty(ks.Pending())
+
+	// NOTE before the height, the original key is valid
+	t.NoError(ks.VerifySigner(home.Address(), height.Add(1), home.PublicKey()))
+	t.True(xerrors.Is(
+		ks.VerifySigner(home.Address(), height.Add(1), newKey.PublicKey()),
+		node.InvalidAddressError,
+	))
+
+	// NOTE from the height, the new key is valid
+	t.NoError(ks.VerifySigner(home.Address(), height.Add(2), newKey.PublicKey()))
+	t.True(xerrors.Is(
+		ks.VerifySigner(home.Address(), height.Add(2), home.PublicKey()),
+		node.InvalidAddressError,
+	))
+
+	acting := ks.Acting(height.Add(2), Round(0))
+	t.True(newKey.PublicKey().Equal(acting.Proposer().PublicKey()))
+	for _, n := range acting.Nodes() {
+		if n.Address().Equal(home.Address()) {
+			t.True(newKey.PublicKey().Equal(n.PublicKey()))
+		}
+	}
+
+	t.True(home.PublicKey().Equal(ks.Acting(height.Add(1), Round(0)).Proposer().PublicKey()))
+}
+
+func (t *testKeySuffrage) TestRotateAgain() {
+	ks, ss, home := t.newSuffrage()
+
+	height := NewBlockHeight(10)
+	kr0, key0 := t.newKeyRotation(home.Address(), home.PrivateKey(), height.Add(2))
+	t.agree(ks, ss, home, height, kr0)
+
+	// NOTE the next rotation should be signed by the rotated key
+	wrong, _ := t.newKeyRotation(home.Address(), home.PrivateKey(), height.Add(5))
+	t.True(xerrors.Is(ks.AddPending(wrong), node.InvalidAddressError))
+
+	kr1, key1 := t.newKeyRotation(home.Address(), key0, height.Add(5))
+	t.NoError(ks.AddPending(kr1))
+	t.agree(ks, ss, home, height.Add(3), kr1)
+
+	t.NoError(ks.VerifySigner(home.Address(), height.Add(4), key0.PublicKey()))
+	t.NoError(ks.VerifySigner(home.Address(), height.Add(5), key1.PublicKey()))
+}
+
+func (t *testKeySuffrage) TestNotSignedByNode() {
+	ks, ss, home := t.newSuffrage()
+
+	another, _ := keypair.NewStellarPrivateKey()
+	kr, newKey := t.newKeyRotation(home.Address(), another, NewBlockHeight(12))
+	t.True(xerrors.Is(ks.AddPending(kr), node.InvalidAddressError))
+
+	// NOTE invalid KeyRotation in agreed proposal is ignored
+	t.agree(ks, ss, home, NewBlockHeight(10), kr)
+	t.True(xerrors.Is(
+		ks.VerifySigner(home.Address(), NewBlockHeight(12), newKey.PublicKey()),
+		node.InvalidAddressError,
+	))
+}
+
+func (t *testKeySuffrage) TestNotInSuffrage() {
+	ks, _, _ := t.newSuffrage()
+
+	other := node.NewRandomHome()
+	kr, _ := t.newKeyRotation(other.Address(), other.PrivateKey(), NewBlockHeight(12))
+	t.Contains(ks.AddPending(kr).Error(), "not in suffrage")
+}
+
+func (t *testKeySuffrage) TestPassedHeight() {
+	ks, ss, home := t.newSuffrage()
+
+	height := NewBlockHeight(10)
+	kr, newKey := t.newKeyRotation(home.Address(), home.PrivateKey(), height)
+	t.agree(ks, ss, home, height, kr)
+
+	_, found := ks.PublicKey(home.Address(), height.Add(1))
+	t.False(found)
+	t.True(xerrors.Is(
+		ks.VerifySigner(home.Address(), height.Add(1), newKey.PublicKey()),
+		node.InvalidAddressError,
+	))
+}
+
+func (t *testKeySuffrage) TestBallotChecker() {
+	ks, ss, home := t.newSuffrage()
+
+	lastBlock := NewRandomBlock()
+	nextBlock := NewRandomNextBlock(lastBlock)
+
+	kr, newKey := t.newKeyRotation(home.Address(), home.PrivateKey(), nextBlock.Height())
+	t.agree(ks, ss, home, lastBlock.Height(), kr)
+
+	homeState := NewHomeState(home, lastBlock)
+	checker := NewCompilerBallotChecker(homeState, ks)
+
+	lastINITVoteResult := NewVoteResult(nextBlock.Height(), nextBlock.Round(), StageINIT).
+		SetAgreement(Majority)
+
+	for _, c := range []struct {
+		pk    keypair.PrivateKey
+		valid bool
+	}{
+		{pk: home.PrivateKey(), valid: false},
+		{pk: newKey, valid: true},
+	} {
+		ballot, err := NewSIGNBallot(
+			home.Address(),
+			lastBlock.Hash(),
+			lastBlock.Round(),
+			nextBlock.Height(),
+			nextBlock.Hash(),
+			nextBlock.Round(),
+			nextBlock.Proposal(),
+		)
+		t.NoError(err)
+		t.NoError(ballot.Sign(c.pk, nil))
+
+		err = checker.
+			New(context.TODO()).
+			SetContext("ballot", ballot).
+			SetContext("lastINITVoteResult", lastINITVoteResult).
+			SetContext("lastStagesVoteResult", VoteResult{}).
+			Check()
+		if c.valid {
+			t.NoError(err)
+		} else {
+			t.True(xerrors.Is(err, node.InvalidAddressError))
+		}
+	}
+}
+
+func (t *testKeySuffrage) TestMissingTransaction() {
+	ks, ss, home := t.newSuffrage()
+
+	height := NewBlockHeight(10)
+	kr, newKey := t.newKeyRotation(home.Address(), home.PrivateKey(), height.Add(2))
+
+	// NOTE the KeyRotation is not in the seal storage
+	proposal, err := NewProposal(height, Round(0), NewRandomBlockHash(), home.Address(), []hash.Hash{kr.Hash()})
+	t.NoError(err)
+	t.NoError(proposal.Sign(home.PrivateKey(), nil))
+
+	block, err := NewBlock(height, Round(0), proposal.Hash())
+	t.NoError(err)
+
+	err = ks.StoreBlock(block)
+	t.Contains(err.Error(), "proposal of block not found")
+
+	t.NoError(ss.Save(proposal))
+	err = ks.StoreBlock(block)
+	t.Contains(err.Error(), "transaction of proposal not found")
+
+	_, found := ks.PublicKey(home.Address(), height.Add(2))
+	t.False(found)
+
+	t.NoError(ss.Save(kr))
+	t.NoError(ks.StoreBlock(block))
+	t.NoError(ks.VerifySigner(home.Address(), height.Add(2), newKey.PublicKey()))
+}
+
+func (t *testKeySuffrage) TestBLSProofOfPossession() {
+	home := node.NewRandomHome()
+	pk, _ := keypair.NewBLSPrivateKey()
+
+	{ // without proof
+		kr, err := NewKeyRotation(home.Address(), pk.PublicKey(), NewBlockHeight(3), nil)
+		t.NoError(err)
+		t.NoError(kr.Sign(home.PrivateKey(), nil))
+		t.Contains(kr.IsValid().Error(), "proof of possession")
+	}
+
+	{ // proof of another key
+		another, _ := keypair.NewBLSPrivateKey()
+		proof, err := another.(keypair.BLSPrivateKey).ProvePossession()
+		t.NoError(err)
+
+		kr, err := NewKeyRotation(home.Address(), pk.PublicKey(), NewBlockHeight(3), proof)
+		t.NoError(err)
+		t.NoError(kr.Sign(home.PrivateKey(), nil))
+		t.Contains(kr.IsValid().Error(), "proof of possession")
+	}
+
+	proof, err := pk.(keypair.BLSPrivateKey).ProvePossession()
+	t.NoError(err)
+
+	kr, err := NewKeyRotation(home.Address(), pk.PublicKey(), NewBlockHeight(3), proof)
+	t.NoError(err)
+	t.NoError(kr.Sign(home.PrivateKey(), nil))
+	t.NoError(kr.IsValid())
+
+	b, err := rlp.EncodeToBytes(kr)
+	t.NoError(err)
+
+	var decoded KeyRotation
+	t.NoError(rlp.DecodeBytes(b, &decoded))
+	t.True(kr.Proof().Equal(decoded.Proof()))
+}
+
+func TestKeySuffrage(t *testing.T) {
+	suite.Run(t, new(testKeySuffrage))
+}
@@ -0,0 +1,315 @@
+package isaac
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog"
+
+	"github.com/spikeekips/mitum/node"
+)
+
+// LivenessRecord is the liveness of node, which is collected from the stored
+// blocks and the majority VoteResults.
+type LivenessRecord struct {
+	address        node.Address
+	missedProposal uint
+	lateBallot     uint
+	lastMissed     Height
+}
+
+func (lr LivenessRecord) Address() node.Address {
+	return lr.address
+}
+
+// MissedProposal is the number of the rounds, which the node was proposer, but
+// the block was not agreed.
+func (lr LivenessRecord) MissedProposal() uint {
+	return lr.missedProposal
+}
+
+// LateBallot is the number of the majority VoteResults, which the acting node
+// did not vote before majority.
+func (lr LivenessRecord) LateBallot() uint {
+	return lr.lateBallot
+}
+
+func (lr LivenessRecord) LastMissed() Height {
+	return lr.lastMissed
+}
+
+func (lr LivenessRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"address":         lr.address,
+		"missed_proposal": lr.missedProposal,
+		"late_ballot":     lr.lateBallot,
+		"last_missed":     lr.lastMissed,
+	})
+}
+
+func (lr LivenessRecord) MarshalZerologObject(e *zerolog.Event) {
+	e.Object("address", lr.address)
+	e.Uint("missed_proposal", lr.missedProposal)
+	e.Uint("late_ballot", lr.lateBallot)
+	e.Str("last_missed", lr.lastMissed.String())
+}
+
+type agreedRound struct {
+	height Height
+	round  Round
+	missed []node.Address
+}
+
+// LivenessSuffrage wraps Suffrage and deprioritises the proposers, which
+// repeatedly missed their proposals.
+// * the agreed round of block is `Block.Round()` of the stored block; see
+// StoreBlock()
+// * the proposers of the rounds before the agreed round are missed
+// * if the proposer missed more than `maxMissed` times in the last `window`
+// heights, the next node, which is not failing, in the acting suffrage becomes
+// proposer
+//
+// The missed proposals are decided only by the stored blocks, so every node
+// with the same blocks selects the same proposer; the late ballots are
+// collected from the majority VoteResults, which are observed by the node, so
+// they are only for the record.
+type LivenessSuffrage struct {
+	sync.RWMutex
+	Suffrage
+	window    uint
+	maxMissed uint
+	agreed    map[string] /* Height.String() */ agreedRound
+	records   map[node.Address]LivenessRecord
+}
+
+func NewLivenessSuffrage(suffrage Suffrage, window, maxMissed uint) *LivenessSuffrage {
+	return &LivenessSuffrage{
+		Suffrage:  suffrage,
+		window:    window,
+		maxMissed: maxMissed,
+		agreed:    map[string]agreedRound{},
+		records:   map[node.Address]LivenessRecord{},
+	}
+}
+
+func (ls *LivenessSuffrage) AddNodes(nodes ...node.Node) Suffrage {
+	ls.Lock()
+	defer ls.Unlock()
+
+	ls.Suffrage = ls.Suffrage.AddNodes(nodes...)
+
+	return ls
+}
+
+func (ls *LivenessSuffrage) RemoveNodes(nodes ...node.Node) Suffrage {
+	ls.Lock()
+	defer ls.Unlock()
+
+	ls.Suffrage = ls.Suffrage.RemoveNodes(nodes...)
+
+	return ls
+}
+
+func (ls *LivenessSuffrage) Acting(height Height, round Round) ActingSuffrage {
+	ls.RLock()
+	defer ls.RUnlock()
+
+	return ls.acting(height, round)
+}
+
+func (ls *LivenessSuffrage) acting(height Height, round Round) ActingSuffrage {
+	acting := ls.Suffrage.Acting(height, round)
+
+	failing := ls.failing(height)
+	if len(failing) < 1 {
+		return acting
+	} else if _, found := failing[acting.Proposer().Address()]; !found {
+		return acting
+	}
+
+	nodes := acting.Nodes()
+	if len(nodes) < 1 {
+		return acting
+	}
+
+	start := int(round.Uint64() % uint64(len(nodes)))
+	for i := range nodes {
+		n := nodes[(start+i)%len(nodes)]
+		if _, found := failing[n.Address()]; found {
+			continue
+		}
+
+		return NewActingSuffrage(height, round, n, nodes)
+	}
+
+	// NOTE all the acting nodes are failing
+	return acting
+}
+
+// failing returns the nodes, which missed proposals more than maxMissed
+// in the window before height.
+func (ls *LivenessSuffrage) failing(height Height) map[node.Address]struct{} {
+	if ls.maxMissed < 1 {
+		return nil
+	}
+
+	from, ok := height.SubOK(ls.window)
+	if !ok {
+		from = GenesisHeight
+	}
+
+	counts := map[node.Address]uint{}
+	for _, a := range ls.agreed {
+		if a.height.Cmp(from) < 0 || a.height.Cmp(height) >= 0 {
+			continue
+		}
+
+		for _, address := range a.missed {
+			counts[address]++
+		}
+	}
+
+	failing := map[node.Address]struct{}{}
+	for address, c := range counts {
+		if c >= ls.maxMissed {
+			failing[address] = struct{}{}
+		}
+	}
+
+	return failing
+}
+
+// StoreBlock collects the missed proposals of the new block; it should be
+// called for every new block in order before the block is stored. If the inner
+// Suffrage is BlockStorer, the block is also stored to it.
+func (ls *LivenessSuffrage) StoreBlock(block Block) error {
+	ls.Lock()
+	defer ls.Unlock()
+
+	if bs, ok := ls.Suffrage.(BlockStorer); ok {
+		if err := bs.StoreBlock(block); err != nil {
+			return err
+		}
+	}
+
+	ls.agree(block.Height(), block.Round())
+
+	return nil
+}
+
+// Load rebuilds the missed proposals from the stored blocks, which are ordered
+// by height. The proposer of the height depends on the missed proposals of the
+// previous window, so the blocks should be given from the genesis; the old
+// agreed rounds are removed like StoreBlock(). Unlike StoreBlock(), the blocks
+// are not stored to the inner Suffrage. It should be called when the node
+// starts, so the new or restarted node selects the same proposer with the other
+// nodes.
+func (ls *LivenessSuffrage) Load(blocks ...Block) {
+	ls.Lock()
+	defer ls.Unlock()
+
+	for _, block := range blocks {
+		ls.agree(block.Height(), block.Round())
+	}
+}
+
+// ObserveVoteResult records the late ballots from the majority VoteResult.
+func (ls *LivenessSuffrage) ObserveVoteResult(vr VoteResult) {
+	if !vr.GotMajority() {
+		return
+	}
+
+	ls.Lock()
+	defer ls.Unlock()
+
+	ls.recordLateBallot(vr)
+}
+
+func (ls *LivenessSuffrage) agree(height Height, round Round) {
+	if _, found := ls.agreed[height.String()]; found {
+		return
+	}
+
+	// NOTE after all the nodes missed, the more rounds are meaningless
+	rounds := round.Uint64()
+	if n := uint64(len(ls.Suffrage.Nodes())); rounds > n {
+		rounds = n
+	}
+
+	a := agreedRound{height: height, round: round}
+	for r := uint64(0); r < rounds; r++ {
+		proposer := ls.acting(height, Round(r)).Proposer().Address()
+		a.missed = append(a.missed, proposer)
+
+		record := ls.record(proposer)
+		record.missedProposal++
+		record.lastMissed = height
+		ls.records[proposer] = record
+	}
+
+	ls.agreed[height.String()] = a
+
+	// NOTE remove the old agreed rounds
+	if from, ok := height.SubOK(ls.window); ok {
+		for k, a := range ls.agreed {
+			if a.height.Cmp(from) < 0 {
+				delete(ls.agreed, k)
+			}
+		}
+	}
+}
+
+func (ls *LivenessSuffrage) recordLateBallot(vr VoteResult) {
+	voted := map[node.Address]struct{}{}
+	for _, r := range vr.Records() {
+		voted[r.Node()] = struct{}{}
+	}
+
+	for _, n := range ls.acting(vr.Height(), vr.Round()).Nodes() {
+		if _, found := voted[n.Address()]; found {
+			continue
+		}
+
+		record := ls.record(n.Address())
+		record.lateBallot++
+		ls.records[n.Address()] = record
+	}
+}
+
+func (ls *LivenessSuffrage) record(address node.Address) LivenessRecord {
+	if record, found := ls.records[address]; found {
+		return record
+	}
+
+	return LivenessRecord{address: address}
+}
+
+// Records returns the liveness records sorted by address.
+func (ls *LivenessSuffrage) Records() []LivenessRecord {
+	ls.RLock()
+	defer ls.RUnlock()
+
+	var records []LivenessRecord
+	for _, r := range ls.records {
+		records = append(records, r)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].address.String() < records[j].address.String()
+	})
+
+	return records
+}
+
+func (ls *LivenessSuffrage) MarshalJSON() ([]byte, error) {
+	ls.RLock()
+	defer ls.RUnlock()
+
+	return json.Marshal(map[string]interface{}{
+		"type":       "LivenessSuffrage",
+		"suffrage":   ls.Suffrage,
+		"window":     ls.window,
+		"max_missed": ls.maxMissed,
+	})
+}
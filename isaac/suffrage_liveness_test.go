@@ -0,0 +1,146 @@
+package isaac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/spikeekips/mitum/node"
+)
+
+type testLivenessSuffrage struct {
+	suite.Suite
+}
+
+func (t *testLivenessSuffrage) newSuffrage(window, maxMissed uint) (*LivenessSuffrage, node.Node) {
+	proposer := node.NewRandomHome()
+	nodes := []node.Node{proposer}
+	for i := 0; i < 3; i++ {
+		nodes = append(nodes, node.NewRandomHome())
+	}
+
+	return NewLivenessSuffrage(NewFixedProposerSuffrage(proposer, nodes...), window, maxMissed), proposer
+}
+
+func (t *testLivenessSuffrage) acceptVR(height Height, round Round) VoteResult {
+	return NewVoteResult(height, round, StageACCEPT).
+		SetAgreement(Majority).
+		SetBlock(NewRandomBlockHash()).
+		SetProposal(NewRandomProposalHash())
+}
+
+func (t *testLivenessSuffrage) newBlock(height Height, round Round) Block {
+	block, err := NewBlock(height, round, NewRandomProposalHash())
+	t.NoError(err)
+
+	return block
+}
+
+func (t *testLivenessSuffrage) TestNoMissed() {
+	ls, proposer := t.newSuffrage(5, 2)
+
+	height := NewBlockHeight(10)
+	t.NoError(ls.StoreBlock(t.newBlock(height, Round(0))))
+
+	t.True(proposer.Equal(ls.Acting(height.Add(1), Round(0)).Proposer()))
+
+	for _, r := range ls.Records() {
+		t.Equal(uint(0), r.MissedProposal())
+	}
+}
+
+func (t *testLivenessSuffrage) TestDeprioritise() {
+	ls, proposer := t.newSuffrage(5, 2)
+
+	height := NewBlockHeight(10)
+
+	// NOTE block of height is agreed at round 2; the proposer missed 2 rounds
+	t.NoError(ls.StoreBlock(t.newBlock(height, Round(2))))
+
+	for _, r := range ls.Records() {
+		if !proposer.Address().Equal(r.Address()) {
+			t.Equal(uint(0), r.MissedProposal())
+			continue
+		}
+
+		t.Equal(uint(2), r.MissedProposal())
+		t.True(height.Equal(r.LastMissed()))
+	}
+
+	// NOTE the acting suffrage of the agreed height is not changed
+	t.True(proposer.Equal(ls.Acting(height, Round(0)).Proposer()))
+
+	next := ls.Acting(height.Add(1), Round(0))
+	t.False(proposer.Equal(next.Proposer()))
+	t.True(next.Exists(next.Proposer().Address()))
+
+	// NOTE out of window
+	t.True(proposer.Equal(ls.Acting(height.Add(6), Round(0)).Proposer()))
+}
+
+func (t *testLivenessSuffrage) TestVoteResultNotAgreed() {
+	ls, proposer := t.newSuffrage(5, 1)
+
+	// NOTE the observed VoteResult does not decide the missed proposals
+	height := NewBlockHeight(10)
+	ls.ObserveVoteResult(t.acceptVR(height, Round(2)))
+
+	t.True(proposer.Equal(ls.Acting(height.Add(1), Round(0)).Proposer()))
+
+	for _, r := range ls.Records() {
+		t.Equal(uint(0), r.MissedProposal())
+	}
+}
+
+func (t *testLivenessSuffrage) TestSameWithSameBlocks() {
+	ls0, proposer := t.newSuffrage(5, 1)
+	ls1 := NewLivenessSuffrage(ls0.Suffrage, 5, 1)
+
+	height := NewBlockHeight(10)
+
+	// NOTE ls0 observes the different VoteResults from ls1, but they store
+	// the same blocks
+	ls0.ObserveVoteResult(t.acceptVR(height, Round(0)))
+	ls0.ObserveVoteResult(t.acceptVR(height, Round(3)))
+
+	block := t.newBlock(height, Round(1))
+	t.NoError(ls0.StoreBlock(block))
+	t.NoError(ls1.StoreBlock(block))
+
+	for i := uint64(0); i < 5; i++ {
+		a0 := ls0.Acting(height.Add(1), Round(i))
+		a1 := ls1.Acting(height.Add(1), Round(i))
+		t.True(a0.Proposer().Equal(a1.Proposer()))
+		t.False(proposer.Equal(a0.Proposer()))
+	}
+}
+
+func (t *testLivenessSuffrage) TestLoad() {
+	ls0, proposer := t.newSuffrage(5, 1)
+
+	// NOTE ls0 stores the blocks while running and ls1 is restarted with the
+	// same blocks
+	var blocks []Block
+	for i := uint64(1); i <= 10; i++ {
+		block := t.newBlock(NewBlockHeight(i), Round(i%2))
+		t.NoError(ls0.StoreBlock(block))
+		blocks = append(blocks, block)
+	}
+
+	ls1 := NewLivenessSuffrage(ls0.Suffrage, 5, 1)
+	ls1.Load(blocks...)
+
+	height := blocks[len(blocks)-1].Height()
+	for i := uint64(0); i < 5; i++ {
+		a0 := ls0.Acting(height.Add(1), Round(i))
+		a1 := ls1.Acting(height.Add(1), Round(i))
+		t.True(a0.Proposer().Equal(a1.Proposer()))
+		t.False(proposer.Equal(a0.Proposer()))
+	}
+
+	t.Equal(ls0.Records(), ls1.Records())
+}
+
+func TestLivenessSuffrage(t *testing.T) {
+	suite.Run(t, new(testLivenessSuffrage))
+}
@@ -53,6 +53,34 @@ func (tr *Threshold) SetBase(baseTotal uint, basePercent float64) error {
 	return nil
 }
 
+// SetPercent updates the percent of base and the stages with keeping their
+// total.
+func (tr *Threshold) SetPercent(percent float64) error {
+	if _, err := calculateThreshold(0, percent); err != nil {
+		return err
+	}
+
+	tr.RLock()
+	baseTotal := tr.base[0]
+	tr.RUnlock()
+
+	if err := tr.SetBase(baseTotal, percent); err != nil {
+		return err
+	}
+
+	var err error
+	tr.threshold.Range(func(k, v interface{}) bool {
+		t := v.([3]uint)
+		if err = tr.Set(k.(Stage), t[0], percent); err != nil {
+			return false
+		}
+
+		return true
+	})
+
+	return err
+}
+
 func (tr *Threshold) Set(stage Stage, total uint, percent float64) error {
 	th, err := calculateThreshold(total, percent)
 	if err != nil {
@@ -0,0 +1,288 @@
+package isaac
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/rs/zerolog"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/keypair"
+	"github.com/spikeekips/mitum/node"
+)
+
+// AggregatedVoteProof is the proof of the majority VoteResult; instead of
+// carrying the signatures of every ballot, it carries one aggregated BLS
+// signature and the bitmap of the signers over the acting suffrage.
+//
+// The ballot body of the signer can be made from the VoteResult and the
+// address of the signer, so the proof can be verified by one pairing check
+// with the acting suffrage.
+type AggregatedVoteProof struct {
+	height    Height
+	round     Round
+	stage     Stage
+	proposal  hash.Hash
+	block     hash.Hash
+	lastBlock hash.Hash
+	lastRound Round
+	signers   []byte // bitmap of `ActingSuffrage.Nodes()`
+	signature keypair.Signature
+}
+
+// NewAggregatedVoteProof aggregates the signatures of the ballots, which voted
+// for the VoteResult. The signers of ballots should use BLS keypair.
+func NewAggregatedVoteProof(vr VoteResult, acting ActingSuffrage, ballots []Ballot) (AggregatedVoteProof, error) {
+	avp := AggregatedVoteProof{
+		height:    vr.Height(),
+		round:     vr.Round(),
+		stage:     vr.Stage(),
+		proposal:  vr.Proposal(),
+		block:     vr.Block(),
+		lastBlock: vr.LastBlock(),
+		lastRound: vr.LastRound(),
+		signers:   make([]byte, (len(acting.Nodes())+7)/8),
+	}
+
+	if len(ballots) < 1 {
+		return AggregatedVoteProof{}, xerrors.Errorf("empty ballots")
+	}
+
+	var sigs []keypair.Signature
+	for _, ballot := range ballots {
+		if err := avp.checkBallot(ballot); err != nil {
+			return AggregatedVoteProof{}, err
+		}
+
+		i := avp.indexOf(acting, ballot.Node())
+		if i < 0 {
+			return AggregatedVoteProof{}, xerrors.Errorf("ballot node is not in acting suffrage; node=%q", ballot.Node())
+		} else if avp.isSigner(i) {
+			return AggregatedVoteProof{}, xerrors.Errorf("duplicated ballot found; node=%q", ballot.Node())
+		} else if !acting.Nodes()[i].PublicKey().Equal(ballot.Signer()) {
+			return AggregatedVoteProof{}, xerrors.Errorf("ballot signer does not match; node=%q", ballot.Node())
+		}
+
+		avp.signers[i/8] |= 1 << uint(i%8)
+		sigs = append(sigs, ballot.Signature())
+	}
+
+	signature, err := keypair.AggregateBLSSignatures(sigs...)
+	if err != nil {
+		return AggregatedVoteProof{}, err
+	}
+	avp.signature = signature
+
+	return avp, nil
+}
+
+func (avp AggregatedVoteProof) checkBallot(ballot Ballot) error {
+	if !ballot.Signer().Type().Equal(keypair.BLSType) {
+		return xerrors.Errorf("ballot is not signed by bls keypair; type=%q", ballot.Signer().Type())
+	}
+
+	switch {
+	case ballot.Stage() != avp.stage:
+	case !ballot.Height().Equal(avp.height):
+	case ballot.Round() != avp.round:
+	case !ballot.Proposal().Equal(avp.proposal):
+	case !ballot.Block().Equal(avp.block):
+	case !ballot.LastBlock().Equal(avp.lastBlock):
+	case ballot.LastRound() != avp.lastRound:
+	default:
+		return nil
+	}
+
+	return xerrors.Errorf("ballot does not match with VoteResult; ballot=%q", ballot.Hash())
+}
+
+func (avp AggregatedVoteProof) indexOf(acting ActingSuffrage, address node.Address) int {
+	for i, n := range acting.Nodes() {
+		if n.Address().Equal(address) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (avp AggregatedVoteProof) isSigner(i int) bool {
+	if i < 0 || i/8 >= len(avp.signers) {
+		return false
+	}
+
+	return avp.signers[i/8]&(1<<uint(i%8)) != 0
+}
+
+func (avp AggregatedVoteProof) Height() Height {
+	return avp.height
+}
+
+func (avp AggregatedVoteProof) Round() Round {
+	return avp.round
+}
+
+func (avp AggregatedVoteProof) Stage() Stage {
+	return avp.stage
+}
+
+func (avp AggregatedVoteProof) Proposal() hash.Hash {
+	return avp.proposal
+}
+
+func (avp AggregatedVoteProof) Block() hash.Hash {
+	return avp.block
+}
+
+func (avp AggregatedVoteProof) LastBlock() hash.Hash {
+	return avp.lastBlock
+}
+
+func (avp AggregatedVoteProof) LastRound() Round {
+	return avp.lastRound
+}
+
+func (avp AggregatedVoteProof) Signature() keypair.Signature {
+	return avp.signature
+}
+
+// Signers returns the signed nodes of the acting suffrage.
+func (avp AggregatedVoteProof) Signers(acting ActingSuffrage) []node.Node {
+	var signers []node.Node
+	for i, n := range acting.Nodes() {
+		if avp.isSigner(i) {
+			signers = append(signers, n)
+		}
+	}
+
+	return signers
+}
+
+// Verify checks the aggregated signature with the acting suffrage and the
+// threshold.
+func (avp AggregatedVoteProof) Verify(acting ActingSuffrage, threshold uint) error {
+	if len(avp.signers) != (len(acting.Nodes())+7)/8 {
+		return xerrors.Errorf(
+			"signers does not match with acting suffrage; signers=%d nodes=%d",
+			len(avp.signers),
+			len(acting.Nodes()),
+		)
+	}
+
+	signers := avp.Signers(acting)
+	if uint(len(signers)) < threshold {
+		return xerrors.Errorf("not enough signers; signers=%d threshold=%d", len(signers), threshold)
+	}
+
+	inputs := make([][]byte, len(signers))
+	pks := make([]keypair.PublicKey, len(signers))
+	for i, n := range signers {
+		body := BaseBallotBody{
+			node:      n.Address(),
+			stage:     avp.stage,
+			height:    avp.height,
+			round:     avp.round,
+			proposal:  avp.proposal,
+			block:     avp.block,
+			lastBlock: avp.lastBlock,
+			lastRound: avp.lastRound,
+		}
+
+		h, err := body.makeHash()
+		if err != nil {
+			return err
+		}
+
+		// NOTE same with seal.BaseSeal.Sign() without input
+		inputs[i] = h.Bytes()
+		pks[i] = n.PublicKey()
+	}
+
+	return keypair.VerifyBLSAggregated(inputs, pks, avp.signature)
+}
+
+func (avp AggregatedVoteProof) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, struct {
+		H  Height
+		R  Round
+		S  Stage
+		P  hash.Hash
+		B  hash.Hash
+		LB hash.Hash
+		LR Round
+		SS []byte
+		SG keypair.Signature
+	}{
+		H:  avp.height,
+		R:  avp.round,
+		S:  avp.stage,
+		P:  avp.proposal,
+		B:  avp.block,
+		LB: avp.lastBlock,
+		LR: avp.lastRound,
+		SS: avp.signers,
+		SG: avp.signature,
+	})
+}
+
+func (avp *AggregatedVoteProof) DecodeRLP(s *rlp.Stream) error {
+	var d struct {
+		H  Height
+		R  Round
+		S  Stage
+		P  hash.Hash
+		B  hash.Hash
+		LB hash.Hash
+		LR Round
+		SS []byte
+		SG keypair.Signature
+	}
+	if err := s.Decode(&d); err != nil {
+		return err
+	}
+
+	avp.height = d.H
+	avp.round = d.R
+	avp.stage = d.S
+	avp.proposal = d.P
+	avp.block = d.B
+	avp.lastBlock = d.LB
+	avp.lastRound = d.LR
+	avp.signers = d.SS
+	avp.signature = d.SG
+
+	return nil
+}
+
+func (avp AggregatedVoteProof) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"height":     avp.height,
+		"round":      avp.round,
+		"stage":      avp.stage,
+		"proposal":   avp.proposal,
+		"block":      avp.block,
+		"last_block": avp.lastBlock,
+		"last_round": avp.lastRound,
+		"signers":    avp.signers,
+		"signature":  avp.signature,
+	})
+}
+
+func (avp AggregatedVoteProof) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("height", avp.height.String())
+	e.Uint64("round", avp.round.Uint64())
+	e.Str("stage", avp.stage.String())
+	e.Object("proposal", avp.proposal)
+	e.Object("block", avp.block)
+	e.Object("last_block", avp.lastBlock)
+	e.Uint64("last_round", avp.lastRound.Uint64())
+	e.Hex("signers", avp.signers)
+	e.Str("signature", avp.signature.String())
+}
+
+func (avp AggregatedVoteProof) String() string {
+	b, _ := json.Marshal(avp) // nolint
+	return string(b)
+}
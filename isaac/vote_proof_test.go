@@ -0,0 +1,143 @@
+package isaac
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/spikeekips/mitum/keypair"
+	"github.com/spikeekips/mitum/node"
+)
+
+type testAggregatedVoteProof struct {
+	suite.Suite
+}
+
+func (t *testAggregatedVoteProof) newActing(n int) ([]node.Home, ActingSuffrage) {
+	var homes []node.Home
+	var nodes []node.Node
+	for i := 0; i < n; i++ {
+		pk, _ := keypair.NewBLSPrivateKey()
+		home := node.NewHome(node.NewRandomAddress(), pk)
+		homes = append(homes, home)
+		nodes = append(nodes, home)
+	}
+
+	return homes, NewActingSuffrage(NewBlockHeight(33), Round(0), nodes[0], nodes)
+}
+
+func (t *testAggregatedVoteProof) ballots(homes []node.Home, vr VoteResult) []Ballot {
+	var ballots []Ballot
+	for _, home := range homes {
+		ballot, err := NewACCEPTBallot(
+			home.Address(),
+			vr.LastBlock(),
+			vr.LastRound(),
+			vr.Height(),
+			vr.Block(),
+			vr.Round(),
+			vr.Proposal(),
+		)
+		t.NoError(err)
+		t.NoError(ballot.Sign(home.PrivateKey(), nil))
+
+		ballots = append(ballots, ballot)
+	}
+
+	return ballots
+}
+
+func (t *testAggregatedVoteProof) newVoteResult() VoteResult {
+	return NewVoteResult(NewBlockHeight(33), Round(0), StageACCEPT).
+		SetAgreement(Majority).
+		SetBlock(NewRandomBlockHash()).
+		SetLastBlock(NewRandomBlockHash()).
+		SetLastRound(Round(1)).
+		SetProposal(NewRandomProposalHash())
+}
+
+func (t *testAggregatedVoteProof) TestNew() {
+	homes, acting := t.newActing(4)
+	vr := t.newVoteResult()
+
+	avp, err := NewAggregatedVoteProof(vr, acting, t.ballots(homes[:3], vr))
+	t.NoError(err)
+	t.Equal(3, len(avp.Signers(acting)))
+
+	t.NoError(avp.Verify(acting, 3))
+
+	// NOTE threshold is not satisfied
+	t.Error(avp.Verify(acting, 4))
+}
+
+func (t *testAggregatedVoteProof) TestEncodeRLP() {
+	homes, acting := t.newActing(4)
+	vr := t.newVoteResult()
+
+	avp, err := NewAggregatedVoteProof(vr, acting, t.ballots(homes, vr))
+	t.NoError(err)
+
+	b, err := rlp.EncodeToBytes(avp)
+	t.NoError(err)
+
+	var decoded AggregatedVoteProof
+	t.NoError(rlp.DecodeBytes(b, &decoded))
+	t.True(avp.Block().Equal(decoded.Block()))
+	t.True(avp.Signature().Equal(decoded.Signature()))
+
+	t.NoError(decoded.Verify(acting, 4))
+}
+
+func (t *testAggregatedVoteProof) TestTampered() {
+	homes, acting := t.newActing(4)
+	vr := t.newVoteResult()
+
+	avp, err := NewAggregatedVoteProof(vr, acting, t.ballots(homes[:3], vr))
+	t.NoError(err)
+
+	{ // different block
+		tampered := avp
+		tampered.block = NewRandomBlockHash()
+		t.Error(tampered.Verify(acting, 3))
+	}
+
+	{ // add signer, which did not sign
+		tampered := avp
+		tampered.signers = []byte{0xff}
+		t.Error(tampered.Verify(acting, 3))
+	}
+}
+
+func (t *testAggregatedVoteProof) TestWrongBallots() {
+	homes, acting := t.newActing(4)
+	vr := t.newVoteResult()
+
+	{ // ballot of another VoteResult
+		ballots := t.ballots(homes, t.newVoteResult())
+		_, err := NewAggregatedVoteProof(vr, acting, ballots)
+		t.Contains(err.Error(), "does not match")
+	}
+
+	{ // not in acting suffrage
+		others, _ := t.newActing(1)
+		_, err := NewAggregatedVoteProof(vr, acting, t.ballots(others, vr))
+		t.Contains(err.Error(), "not in acting suffrage")
+	}
+
+	{ // duplicated
+		ballots := t.ballots(homes[:1], vr)
+		_, err := NewAggregatedVoteProof(vr, acting, append(ballots, ballots...))
+		t.Contains(err.Error(), "duplicated")
+	}
+
+	{ // not bls
+		home := node.NewRandomHome()
+		_, err := NewAggregatedVoteProof(vr, acting, t.ballots([]node.Home{home}, vr))
+		t.Contains(err.Error(), "not signed by bls")
+	}
+}
+
+func TestAggregatedVoteProof(t *testing.T) {
+	suite.Run(t, new(testAggregatedVoteProof))
+}
@@ -32,6 +32,12 @@ func (ag Agreement) String() string {
 	}
 }
 
+// VoteResultObserver is notified with the finished VoteResult before it is
+// handed over to StateHandler.
+type VoteResultObserver interface {
+	ObserveVoteResult(VoteResult)
+}
+
 type VoteResult struct {
 	height    Height
 	round     Round
@@ -43,6 +49,7 @@ type VoteResult struct {
 	records   []Record
 	agreement Agreement
 	closed    bool
+	voteProof AggregatedVoteProof
 }
 
 func NewVoteResult(
@@ -136,6 +143,17 @@ func (vr VoteResult) SetRecords(records []Record) VoteResult {
 	return vr
 }
 
+// VoteProof returns the AggregatedVoteProof of the majority VoteResult; the
+// proof is made only when the voted ballots are signed by BLS keypair.
+func (vr VoteResult) VoteProof() (AggregatedVoteProof, bool) {
+	return vr.voteProof, len(vr.voteProof.Signature()) > 0
+}
+
+func (vr VoteResult) SetVoteProof(avp AggregatedVoteProof) VoteResult {
+	vr.voteProof = avp
+	return vr
+}
+
 func (vr VoteResult) IsClosed() bool {
 	return vr.closed
 }
@@ -146,7 +164,7 @@ func (vr VoteResult) SetClosed() VoteResult {
 }
 
 func (vr VoteResult) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]interface{}{
+	m := map[string]interface{}{
 		"height":     vr.height,
 		"round":      vr.round,
 		"stage":      vr.stage,
@@ -156,7 +174,13 @@ func (vr VoteResult) MarshalJSON() ([]byte, error) {
 		"closed":     vr.closed,
 		"last_block": vr.lastBlock,
 		"last_round": vr.lastRound,
-	})
+	}
+
+	if avp, found := vr.VoteProof(); found {
+		m["vote_proof"] = avp
+	}
+
+	return json.Marshal(m)
 }
 
 func (vr VoteResult) MarshalZerologObject(e *zerolog.Event) {
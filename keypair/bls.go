@@ -0,0 +1,571 @@
+package keypair
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/btcsuite/btcutil/base58"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+)
+
+var (
+	BLSType common.DataType = common.NewDataType(3, "bls")
+)
+
+var (
+	blsG2Generator = new(bn256.G2).ScalarBaseMult(big.NewInt(1))
+	blsCurveB      = big.NewInt(3)
+	blsG2Identity  = new(bn256.G2).ScalarBaseMult(big.NewInt(0)).Marshal()
+	// NOTE the proof of possession is hashed with its own domain, so the
+	// proof can not be used as the signature of any input.
+	blsPossessionDomain = []byte("mitum-bls-proof-of-possession")
+)
+
+// BLS is the BLS signature scheme over the bn256 curve. Signatures are in G1
+// and public keys are in G2, so the signatures can be aggregated into one G1
+// point.
+type BLS struct {
+}
+
+func (s BLS) Type() common.DataType {
+	return BLSType
+}
+
+// New generates the new random keypair
+func (s BLS) New() (PrivateKey, error) {
+	return NewBLSPrivateKey()
+}
+
+// NewFromSeed generates the keypair from raw seed
+func (s BLS) NewFromSeed(b []byte) (PrivateKey, error) {
+	h := sha256.Sum256(b)
+
+	k := new(big.Int).SetBytes(h[:])
+	k.Mod(k, bn256.Order)
+	if k.Sign() == 0 {
+		return nil, FailedToEncodeKeypairError.Newf("invalid seed")
+	}
+
+	return BLSPrivateKey{k: k}, nil
+}
+
+// blsHashToG1 maps input to the point of G1 by try-and-increment. G1 of bn256
+// has cofactor 1, so every point on the curve is in G1.
+func blsHashToG1(input []byte) *bn256.G1 {
+	return blsHashToG1WithDomain(nil, input)
+}
+
+func blsHashToG1WithDomain(domain, input []byte) *bn256.G1 {
+	p := bn256.P
+
+	var counter [4]byte
+	for i := uint32(0); ; i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+
+		h := sha256.New()
+		_, _ = h.Write(domain)
+		_, _ = h.Write(counter[:])
+		_, _ = h.Write(input)
+		digest := h.Sum(nil)
+
+		x := new(big.Int).SetBytes(digest)
+		x.Mod(x, p)
+
+		// y² = x³ + 3
+		y2 := new(big.Int).Exp(x, big.NewInt(3), p)
+		y2.Add(y2, blsCurveB)
+		y2.Mod(y2, p)
+
+		y := new(big.Int).ModSqrt(y2, p)
+		if y == nil {
+			continue
+		}
+
+		// NOTE select one of the 2 roots by the digest
+		if y.Bit(0) != uint(digest[len(digest)-1]&1) {
+			y.Sub(p, y)
+		}
+
+		b := append(blsPaddedBytes(x), blsPaddedBytes(y)...)
+
+		g := new(bn256.G1)
+		if _, err := g.Unmarshal(b); err != nil {
+			continue
+		}
+
+		return g
+	}
+}
+
+// blsPaddedBytes returns the 32 bytes big-endian bytes of b.
+func blsPaddedBytes(b *big.Int) []byte {
+	r := make([]byte, 32)
+	v := b.Bytes()
+	copy(r[32-len(v):], v)
+
+	return r
+}
+
+func blsSignatureToG1(sig Signature) (*bn256.G1, error) {
+	g := new(bn256.G1)
+	if _, err := g.Unmarshal([]byte(sig)); err != nil {
+		return nil, SignatureVerificationFailedError.New(err)
+	}
+
+	return g, nil
+}
+
+// blsCheckG2 checks the public key is in the subgroup of G2 and is not the
+// identity; the point of the twist curve out of the subgroup or the identity
+// makes the pairing check meaningless.
+func blsCheckG2(g *bn256.G2) error {
+	if string(g.Marshal()) == string(blsG2Identity) {
+		return FailedToEncodeKeypairError.Newf("public key is identity")
+	}
+
+	if string(new(bn256.G2).ScalarMult(g, bn256.Order).Marshal()) != string(blsG2Identity) {
+		return FailedToEncodeKeypairError.Newf("public key is not in G2 subgroup")
+	}
+
+	return nil
+}
+
+func blsUnmarshalG2(b []byte) (*bn256.G2, error) {
+	g := new(bn256.G2)
+	if _, err := g.Unmarshal(b); err != nil {
+		return nil, FailedToEncodeKeypairError.New(err)
+	}
+
+	if err := blsCheckG2(g); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+type BLSPublicKey struct {
+	pk *bn256.G2
+}
+
+func (s BLSPublicKey) Type() common.DataType {
+	return BLSType
+}
+
+func (s BLSPublicKey) Kind() Kind {
+	return PublicKeyKind
+}
+
+func (s BLSPublicKey) Verify(input []byte, sig Signature) error {
+	return VerifyBLSAggregated([][]byte{input}, []PublicKey{s}, sig)
+}
+
+func (s BLSPublicKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s BLSPublicKey) String() string {
+	return fmt.Sprintf("%s:%s:%s", base58.Encode(s.NativePublicKey()), s.Kind(), s.Type())
+}
+
+func (s BLSPublicKey) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, struct {
+		Type common.DataType
+		Kind Kind
+		Key  []byte
+	}{
+		Type: s.Type(),
+		Kind: s.Kind(),
+		Key:  s.NativePublicKey(),
+	})
+}
+
+func (s *BLSPublicKey) DecodeRLP(st *rlp.Stream) error {
+	var d struct {
+		Type common.DataType
+		Kind Kind
+		Key  []byte
+	}
+	if err := st.Decode(&d); err != nil {
+		return err
+	}
+
+	if !s.Type().Equal(d.Type) {
+		return FailedToEncodeKeypairError.Newf("not bls keypair type; type=%q", d.Type)
+	}
+
+	if s.Kind() != d.Kind {
+		return FailedToEncodeKeypairError.Newf("not public type; kind=%q", d.Kind)
+	}
+
+	pk, err := blsUnmarshalG2(d.Key)
+	if err != nil {
+		return err
+	}
+
+	s.pk = pk
+
+	return nil
+}
+
+func (s BLSPublicKey) Equal(k Key) bool {
+	if !s.Type().Equal(k.Type()) {
+		return false
+	}
+
+	if s.Kind() != k.Kind() {
+		return false
+	}
+
+	ks, ok := k.(BLSPublicKey)
+	if !ok {
+		return false
+	}
+
+	if s.pk == nil || ks.pk == nil {
+		return s.pk == ks.pk
+	}
+
+	return string(s.NativePublicKey()) == string(ks.NativePublicKey())
+}
+
+func (s BLSPublicKey) NativePublicKey() []byte {
+	if s.pk == nil {
+		return nil
+	}
+
+	return s.pk.Marshal()
+}
+
+func (s BLSPublicKey) IsValid() error {
+	if s.pk == nil {
+		return FailedToEncodeKeypairError.Newf("empty public key")
+	}
+
+	_, err := blsUnmarshalG2(s.pk.Marshal())
+
+	return err
+}
+
+type BLSPrivateKey struct {
+	k *big.Int
+}
+
+func NewBLSPrivateKey() (PrivateKey, error) {
+	k, _, err := bn256.RandomG2(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return BLSPrivateKey{k: k}, nil
+}
+
+func (s BLSPrivateKey) Type() common.DataType {
+	return BLSType
+}
+
+func (s BLSPrivateKey) Kind() Kind {
+	return PrivateKeyKind
+}
+
+func (s BLSPrivateKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s BLSPrivateKey) String() string {
+	return fmt.Sprintf("%s:%s:%s", base58.Encode(s.NativePrivateKey()), s.Kind(), s.Type())
+}
+
+func (s BLSPrivateKey) Sign(input []byte) (Signature, error) {
+	if s.k == nil {
+		return nil, FailedToEncodeKeypairError.Newf("empty private key")
+	}
+
+	return Signature(new(bn256.G1).ScalarMult(blsHashToG1(input), s.k).Marshal()), nil
+}
+
+// ProvePossession signs the public key itself under the separate domain; with
+// the proof, the owner of public key proves it has the private key, so the
+// rogue public key can not be used for the aggregated signature.
+func (s BLSPrivateKey) ProvePossession() (Signature, error) {
+	if s.k == nil {
+		return nil, FailedToEncodeKeypairError.Newf("empty private key")
+	}
+
+	input := blsHashToG1WithDomain(blsPossessionDomain, s.NativePublicKey())
+
+	return Signature(new(bn256.G1).ScalarMult(input, s.k).Marshal()), nil
+}
+
+func (s BLSPrivateKey) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, struct {
+		Type common.DataType
+		Kind Kind
+		Key  []byte
+	}{
+		Type: s.Type(),
+		Kind: s.Kind(),
+		Key:  s.NativePrivateKey(),
+	})
+}
+
+func (s *BLSPrivateKey) DecodeRLP(st *rlp.Stream) error {
+	var d struct {
+		Type common.DataType
+		Kind Kind
+		Key  []byte
+	}
+	if err := st.Decode(&d); err != nil {
+		return err
+	}
+
+	if !s.Type().Equal(d.Type) {
+		return FailedToEncodeKeypairError.Newf("not bls keypair type; type=%q", d.Type)
+	}
+
+	if s.Kind() != d.Kind {
+		return FailedToEncodeKeypairError.Newf("not private; kind=%q", d.Kind)
+	}
+
+	s.k = new(big.Int).SetBytes(d.Key)
+
+	return s.IsValid()
+}
+
+func (s BLSPrivateKey) Equal(k Key) bool {
+	if !s.Type().Equal(k.Type()) {
+		return false
+	}
+
+	if s.Kind() != k.Kind() {
+		return false
+	}
+
+	ks, ok := k.(BLSPrivateKey)
+	if !ok {
+		return false
+	}
+
+	if s.k == nil || ks.k == nil {
+		return s.k == ks.k
+	}
+
+	return s.k.Cmp(ks.k) == 0
+}
+
+func (s BLSPrivateKey) PublicKey() PublicKey {
+	if s.k == nil {
+		return BLSPublicKey{}
+	}
+
+	return BLSPublicKey{pk: new(bn256.G2).ScalarBaseMult(s.k)}
+}
+
+func (s BLSPrivateKey) NativePublicKey() []byte {
+	return s.PublicKey().NativePublicKey()
+}
+
+func (s BLSPrivateKey) NativePrivateKey() []byte {
+	if s.k == nil {
+		return nil
+	}
+
+	return blsPaddedBytes(s.k)
+}
+
+func (s BLSPrivateKey) IsValid() error {
+	if s.k == nil {
+		return FailedToEncodeKeypairError.Newf("empty private key")
+	}
+
+	if s.k.Sign() < 1 || s.k.Cmp(bn256.Order) >= 0 {
+		return FailedToEncodeKeypairError.Newf("private key is out of range")
+	}
+
+	return nil
+}
+
+// AggregateBLSSignatures aggregates the BLS signatures into one signature.
+func AggregateBLSSignatures(sigs ...Signature) (Signature, error) {
+	if len(sigs) < 1 {
+		return nil, SignatureVerificationFailedError.Newf("empty signatures")
+	}
+
+	var aggregated *bn256.G1
+	for _, sig := range sigs {
+		g, err := blsSignatureToG1(sig)
+		if err != nil {
+			return nil, err
+		}
+
+		if aggregated == nil {
+			aggregated = g
+			continue
+		}
+
+		aggregated = new(bn256.G1).Add(aggregated, g)
+	}
+
+	return Signature(aggregated.Marshal()), nil
+}
+
+// VerifyBLSAggregated verifies the aggregated signature of the inputs, signed
+// by the public keys respectively, by one pairing check.
+//
+// The inputs should be different each other; with the same input, the rogue
+// public key can forge the aggregated signature.
+func VerifyBLSAggregated(inputs [][]byte, pks []PublicKey, sig Signature) error {
+	if len(inputs) < 1 || len(inputs) != len(pks) {
+		return SignatureVerificationFailedError.Newf(
+			"inputs and public keys does not match; inputs=%d public keys=%d", len(inputs), len(pks),
+		)
+	}
+
+	if len(inputs) > 1 {
+		found := map[string]struct{}{}
+		for _, input := range inputs {
+			if _, ok := found[string(input)]; ok {
+				return SignatureVerificationFailedError.Newf("duplicated input found")
+			}
+			found[string(input)] = struct{}{}
+		}
+	}
+
+	g, err := blsSignatureToG1(sig)
+	if err != nil {
+		return err
+	}
+
+	// NOTE e(sig, g2) == ∏ e(H(input), pk)
+	g1s := []*bn256.G1{g}
+	g2s := []*bn256.G2{blsG2Generator}
+	for i, input := range inputs {
+		pk, ok := pks[i].(BLSPublicKey)
+		if !ok {
+			return SignatureVerificationFailedError.Newf("not bls public key; type=%T", pks[i])
+		} else if err := pk.IsValid(); err != nil {
+			return SignatureVerificationFailedError.New(err)
+		}
+
+		g1s = append(g1s, new(bn256.G1).Neg(blsHashToG1(input)))
+		g2s = append(g2s, pk.pk)
+	}
+
+	if !bn256.PairingCheck(g1s, g2s) {
+		return SignatureVerificationFailedError.Newf("invalid signature")
+	}
+
+	return nil
+}
+
+// VerifyBLSBatch verifies the signatures of the inputs, signed by the public
+// keys respectively, by one pairing check. Unlike VerifyBLSAggregated, each
+// signature is multiplied by the random scalar, so the invalid signatures can
+// not cancel each other and the inputs can be same. If it fails, the invalid
+// one is not known; the signatures should be verified one by one.
+func VerifyBLSBatch(inputs [][]byte, pks []PublicKey, sigs []Signature) error {
+	if len(inputs) < 1 || len(inputs) != len(pks) || len(inputs) != len(sigs) {
+		return SignatureVerificationFailedError.Newf(
+			"inputs, public keys and signatures does not match; inputs=%d public keys=%d signatures=%d",
+			len(inputs), len(pks), len(sigs),
+		)
+	}
+
+	// NOTE e(Σ rᵢ·sigᵢ, g2) == ∏ e(rᵢ·H(inputᵢ), pkᵢ)
+	max := new(big.Int).Lsh(big.NewInt(1), 128)
+
+	var sum *bn256.G1
+	g1s := []*bn256.G1{nil}
+	g2s := []*bn256.G2{blsG2Generator}
+	for i, input := range inputs {
+		pk, ok := pks[i].(BLSPublicKey)
+		if !ok {
+			return SignatureVerificationFailedError.Newf("not bls public key; type=%T", pks[i])
+		} else if err := pk.IsValid(); err != nil {
+			return SignatureVerificationFailedError.New(err)
+		}
+
+		g, err := blsSignatureToG1(sigs[i])
+		if err != nil {
+			return err
+		}
+
+		r, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return SignatureVerificationFailedError.New(err)
+		}
+		r.Add(r, big.NewInt(1))
+
+		g = new(bn256.G1).ScalarMult(g, r)
+		if sum == nil {
+			sum = g
+		} else {
+			sum = new(bn256.G1).Add(sum, g)
+		}
+
+		h := new(bn256.G1).ScalarMult(blsHashToG1(input), r)
+		g1s = append(g1s, new(bn256.G1).Neg(h))
+		g2s = append(g2s, pk.pk)
+	}
+
+	g1s[0] = sum
+
+	if !bn256.PairingCheck(g1s, g2s) {
+		return SignatureVerificationFailedError.Newf("invalid signature")
+	}
+
+	return nil
+}
+
+// VerifyBLSPossession verifies the proof of possession of the public key, which
+// is made by BLSPrivateKey.ProvePossession.
+func VerifyBLSPossession(pk PublicKey, proof Signature) error {
+	bpk, ok := pk.(BLSPublicKey)
+	if !ok {
+		return SignatureVerificationFailedError.Newf("not bls public key; type=%T", pk)
+	} else if err := bpk.IsValid(); err != nil {
+		return SignatureVerificationFailedError.New(err)
+	}
+
+	g, err := blsSignatureToG1(proof)
+	if err != nil {
+		return err
+	}
+
+	input := blsHashToG1WithDomain(blsPossessionDomain, bpk.NativePublicKey())
+	if !bn256.PairingCheck(
+		[]*bn256.G1{g, new(bn256.G1).Neg(input)},
+		[]*bn256.G2{blsG2Generator, bpk.pk},
+	) {
+		return SignatureVerificationFailedError.Newf("invalid proof of possession")
+	}
+
+	return nil
+}
+
+func parseBLSKey(key string, kind Kind) (Key, error) {
+	b := base58.Decode(key)
+	if len(b) < 1 {
+		return nil, xerrors.Errorf("invalid base58 key")
+	}
+
+	switch kind {
+	case PublicKeyKind:
+		pk, err := blsUnmarshalG2(b)
+		if err != nil {
+			return nil, err
+		}
+
+		return BLSPublicKey{pk: pk}, nil
+	default:
+		return BLSPrivateKey{k: new(big.Int).SetBytes(b)}, nil
+	}
+}
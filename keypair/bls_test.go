@@ -0,0 +1,214 @@
+package keypair
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+)
+
+type testBLSKeypair struct {
+	suite.Suite
+}
+
+func (t *testBLSKeypair) TestNew() {
+	pr0, _ := BLS{}.New()
+	t.Equal(BLSType, pr0.Type())
+	t.NoError(pr0.IsValid())
+
+	pr1, _ := BLS{}.New()
+	t.False(pr0.Equal(pr1))
+
+	pk := pr0.PublicKey()
+	t.NoError(pk.IsValid())
+	t.True(pk.Equal(pr0.PublicKey()))
+	t.False(pk.Equal(pr1.PublicKey()))
+}
+
+func (t *testBLSKeypair) TestEncodeRLP() {
+	pr, _ := BLS{}.New()
+
+	b, err := rlp.EncodeToBytes(pr)
+	t.NoError(err)
+
+	upr, err := DecodePrivateKey(b)
+	t.NoError(err)
+	t.True(pr.Equal(upr))
+
+	b, err = rlp.EncodeToBytes(pr.PublicKey())
+	t.NoError(err)
+
+	upk, err := DecodePublicKey(b)
+	t.NoError(err)
+	t.True(pr.PublicKey().Equal(upk))
+
+	var wrong BLSPrivateKey
+	err = rlp.DecodeBytes(b, &wrong)
+	t.True(xerrors.Is(err, FailedToEncodeKeypairError))
+}
+
+func (t *testBLSKeypair) TestSigning() {
+	pr, _ := BLS{}.New()
+
+	input := []byte("source")
+	sig, err := pr.Sign(input)
+	t.NoError(err)
+
+	t.NoError(pr.PublicKey().Verify(input, sig))
+
+	err = pr.PublicKey().Verify([]byte("killme"), sig)
+	t.True(xerrors.Is(err, SignatureVerificationFailedError))
+
+	another, _ := BLS{}.New()
+	err = another.PublicKey().Verify(input, sig)
+	t.True(xerrors.Is(err, SignatureVerificationFailedError))
+}
+
+func (t *testBLSKeypair) TestFromSeed() {
+	pr0, _ := BLS{}.NewFromSeed([]byte("find me"))
+	pr1, _ := BLS{}.NewFromSeed([]byte("find me"))
+
+	t.True(pr0.Equal(pr1))
+	t.True(pr0.PublicKey().Equal(pr1.PublicKey()))
+}
+
+func (t *testBLSKeypair) TestAggregate() {
+	var inputs [][]byte
+	var pks []PublicKey
+	var sigs []Signature
+	for i := 0; i < 4; i++ {
+		pr, _ := BLS{}.New()
+		input := []byte{byte(i), 1, 2, 3}
+
+		sig, err := pr.Sign(input)
+		t.NoError(err)
+
+		inputs = append(inputs, input)
+		pks = append(pks, pr.PublicKey())
+		sigs = append(sigs, sig)
+	}
+
+	aggregated, err := AggregateBLSSignatures(sigs...)
+	t.NoError(err)
+	t.NoError(VerifyBLSAggregated(inputs, pks, aggregated))
+
+	{ // missing signer
+		err := VerifyBLSAggregated(inputs[:3], pks[:3], aggregated)
+		t.True(xerrors.Is(err, SignatureVerificationFailedError))
+	}
+
+	{ // swapped public keys
+		swapped := []PublicKey{pks[1], pks[0], pks[2], pks[3]}
+		err := VerifyBLSAggregated(inputs, swapped, aggregated)
+		t.True(xerrors.Is(err, SignatureVerificationFailedError))
+	}
+
+	{ // same inputs are not allowed
+		err := VerifyBLSAggregated([][]byte{inputs[0], inputs[0]}, pks[:2], aggregated)
+		t.True(xerrors.Is(err, SignatureVerificationFailedError))
+		t.Contains(err.Error(), "duplicated")
+	}
+}
+
+func (t *testBLSKeypair) TestBatch() {
+	var inputs [][]byte
+	var pks []PublicKey
+	var sigs []Signature
+	for i := 0; i < 4; i++ {
+		pr, _ := BLS{}.New()
+
+		// NOTE same input is allowed
+		input := []byte("same input")
+
+		sig, err := pr.Sign(input)
+		t.NoError(err)
+
+		inputs = append(inputs, input)
+		pks = append(pks, pr.PublicKey())
+		sigs = append(sigs, sig)
+	}
+
+	t.NoError(VerifyBLSBatch(inputs, pks, sigs))
+
+	{ // swapped signatures
+		swapped := []Signature{sigs[1], sigs[0], sigs[2], sigs[3]}
+		err := VerifyBLSBatch(inputs, pks, swapped)
+		t.True(xerrors.Is(err, SignatureVerificationFailedError))
+	}
+
+	{ // the invalid signatures, which cancel each other in sum
+		g0, _ := blsSignatureToG1(sigs[0])
+		g1, _ := blsSignatureToG1(sigs[1])
+		delta := blsHashToG1([]byte("delta"))
+
+		wrong := append([]Signature(nil), sigs...)
+		wrong[0] = Signature(new(bn256.G1).Add(g0, delta).Marshal())
+		wrong[1] = Signature(new(bn256.G1).Add(g1, new(bn256.G1).Neg(delta)).Marshal())
+
+		aggregated, err := AggregateBLSSignatures(sigs...)
+		t.NoError(err)
+		wrongAggregated, err := AggregateBLSSignatures(wrong...)
+		t.NoError(err)
+		t.True(aggregated.Equal(wrongAggregated))
+
+		err = VerifyBLSBatch(inputs, pks, wrong)
+		t.True(xerrors.Is(err, SignatureVerificationFailedError))
+	}
+}
+
+func (t *testBLSKeypair) TestIdentityPublicKey() {
+	identity := make([]byte, len(blsG2Identity))
+
+	_, err := parseBLSKey(base58.Encode(identity), PublicKeyKind)
+	t.True(xerrors.Is(err, FailedToEncodeKeypairError))
+	t.Contains(err.Error(), "identity")
+
+	b, err := rlp.EncodeToBytes(struct {
+		Type common.DataType
+		Kind Kind
+		Key  []byte
+	}{Type: BLSType, Kind: PublicKeyKind, Key: identity})
+	t.NoError(err)
+
+	_, err = DecodePublicKey(b)
+	t.True(xerrors.Is(err, FailedToEncodeKeypairError))
+}
+
+func (t *testBLSKeypair) TestEmpty() {
+	var pr BLSPrivateKey
+
+	pk := pr.PublicKey()
+	t.Nil(pk.NativePublicKey())
+	t.Error(pk.IsValid())
+}
+
+func (t *testBLSKeypair) TestProofOfPossession() {
+	pr, _ := BLS{}.New()
+
+	proof, err := pr.(BLSPrivateKey).ProvePossession()
+	t.NoError(err)
+	t.NoError(VerifyBLSPossession(pr.PublicKey(), proof))
+
+	{ // proof of another key
+		another, _ := BLS{}.New()
+		err := VerifyBLSPossession(another.PublicKey(), proof)
+		t.True(xerrors.Is(err, SignatureVerificationFailedError))
+	}
+
+	{ // plain signature of public key is not the proof
+		sig, err := pr.Sign(pr.PublicKey().NativePublicKey())
+		t.NoError(err)
+
+		err = VerifyBLSPossession(pr.PublicKey(), sig)
+		t.True(xerrors.Is(err, SignatureVerificationFailedError))
+	}
+}
+
+func TestBLSKeypair(t *testing.T) {
+	suite.Run(t, new(testBLSKeypair))
+}
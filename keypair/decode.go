@@ -0,0 +1,82 @@
+package keypair
+
+import (
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/spikeekips/mitum/common"
+)
+
+// DecodePublicKey decodes the RLP encoded public key by it's type.
+func DecodePublicKey(b []byte) (PublicKey, error) {
+	t, err := decodeKeyType(b)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case t.Equal(StellarType):
+		var pk StellarPublicKey
+		if err := rlp.DecodeBytes(b, &pk); err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case t.Equal(Secp256k1Type):
+		var pk Secp256k1PublicKey
+		if err := rlp.DecodeBytes(b, &pk); err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case t.Equal(BLSType):
+		var pk BLSPublicKey
+		if err := rlp.DecodeBytes(b, &pk); err != nil {
+			return nil, err
+		}
+		return pk, nil
+	default:
+		return nil, KeypairNotRegisteredError.Newf("type=%q", t)
+	}
+}
+
+// DecodePrivateKey decodes the RLP encoded private key by it's type.
+func DecodePrivateKey(b []byte) (PrivateKey, error) {
+	t, err := decodeKeyType(b)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case t.Equal(StellarType):
+		var pk StellarPrivateKey
+		if err := rlp.DecodeBytes(b, &pk); err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case t.Equal(Secp256k1Type):
+		var pk Secp256k1PrivateKey
+		if err := rlp.DecodeBytes(b, &pk); err != nil {
+			return nil, err
+		}
+		return pk, nil
+	case t.Equal(BLSType):
+		var pk BLSPrivateKey
+		if err := rlp.DecodeBytes(b, &pk); err != nil {
+			return nil, err
+		}
+		return pk, nil
+	default:
+		return nil, KeypairNotRegisteredError.Newf("type=%q", t)
+	}
+}
+
+func decodeKeyType(b []byte) (common.DataType, error) {
+	var d struct {
+		Type common.DataType
+		Kind Kind
+		Key  rlp.RawValue
+	}
+	if err := rlp.DecodeBytes(b, &d); err != nil {
+		return common.DataType{}, FailedToEncodeKeypairError.New(err)
+	}
+
+	return d.Type, nil
+}
@@ -8,6 +8,8 @@ const (
 	FailedToEncodeKeypairErrorCode
 	UnknownKeyKindErrorCode
 	SignatureVerificationFailedErrorCode
+	InvalidKeystoreErrorCode
+	KeystoreDecryptionFailedErrorCode
 )
 
 var (
@@ -36,4 +38,14 @@ var (
 		SignatureVerificationFailedErrorCode,
 		"signature verification failed",
 	)
+	InvalidKeystoreError = common.NewError(
+		"keypair",
+		InvalidKeystoreErrorCode,
+		"invalid keystore",
+	)
+	KeystoreDecryptionFailedError = common.NewError(
+		"keypair",
+		KeystoreDecryptionFailedErrorCode,
+		"failed to decrypt keystore; wrong passphrase or broken keystore",
+	)
 )
@@ -27,3 +27,11 @@ type PrivateKey interface {
 	PublicKey() PublicKey
 	NativePrivateKey() []byte
 }
+
+// KeySigner signs the input with the private key. The PrivateKey is KeySigner,
+// but the private key can be isolated from the process by KeySigner, like
+// RemoteSigner.
+type KeySigner interface {
+	Sign([]byte) (Signature, error)
+	PublicKey() PublicKey
+}
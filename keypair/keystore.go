@@ -0,0 +1,240 @@
+package keypair
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	KeystoreVersion uint   = 1
+	KeystoreKDF     string = "scrypt"
+	KeystoreCipher  string = "aes-256-gcm"
+)
+
+// KeystoreKDFParams is the scrypt parameters of keystore.
+type KeystoreKDFParams struct {
+	N      int    `json:"n"`
+	R      int    `json:"r"`
+	P      int    `json:"p"`
+	KeyLen int    `json:"key_len"`
+	Salt   []byte `json:"salt"`
+}
+
+var DefaultKeystoreKDFParams = KeystoreKDFParams{N: 1 << 18, R: 8, P: 1, KeyLen: 32}
+
+// NOTE the keystore file is not trusted before decryption, so the too large
+// parameters, which exhaust the memory and cpu, are rejected; the memory of
+// scrypt is 128 * n * r bytes, so it is limited to 256MiB, which
+// DefaultKeystoreKDFParams uses.
+const (
+	maxKeystoreKDFN      = 1 << 20
+	maxKeystoreKDFR      = 32
+	maxKeystoreKDFP      = 2
+	maxKeystoreKDFMemory = 256 << 20
+)
+
+func (kp KeystoreKDFParams) IsValid() error {
+	if kp.N < 2 || kp.N&(kp.N-1) != 0 {
+		return InvalidKeystoreError.Newf("n should be power of 2; n=%d", kp.N)
+	} else if kp.N > maxKeystoreKDFN {
+		return InvalidKeystoreError.Newf("too large n; n=%d max=%d", kp.N, maxKeystoreKDFN)
+	}
+
+	if kp.R < 1 || kp.P < 1 {
+		return InvalidKeystoreError.Newf("r and p should be greater than 0; r=%d p=%d", kp.R, kp.P)
+	} else if kp.R > maxKeystoreKDFR || kp.P > maxKeystoreKDFP {
+		return InvalidKeystoreError.Newf(
+			"too large r or p; r=%d p=%d max_r=%d max_p=%d", kp.R, kp.P, maxKeystoreKDFR, maxKeystoreKDFP,
+		)
+	}
+
+	if m := uint64(128) * uint64(kp.N) * uint64(kp.R); m > maxKeystoreKDFMemory {
+		return InvalidKeystoreError.Newf(
+			"too large memory by n and r; n=%d r=%d memory=%d max=%d", kp.N, kp.R, m, maxKeystoreKDFMemory,
+		)
+	}
+
+	if kp.KeyLen != 32 {
+		return InvalidKeystoreError.Newf("key_len should be 32; key_len=%d", kp.KeyLen)
+	}
+
+	if len(kp.Salt) < 16 {
+		return InvalidKeystoreError.Newf("too short salt; salt=%d", len(kp.Salt))
+	}
+
+	return nil
+}
+
+// Keystore is the passphrase-encrypted private key. The private key is
+// encoded by RLP, so the keystore can store any type of PrivateKey. The
+// header, version, type, public key and kdf parameters, is authenticated
+// together with the encrypted key.
+type Keystore struct {
+	Version    uint              `json:"version"`
+	Type       string            `json:"type"`
+	PublicKey  string            `json:"public_key"`
+	KDF        string            `json:"kdf"`
+	KDFParams  KeystoreKDFParams `json:"kdf_params"`
+	Cipher     string            `json:"cipher"`
+	Nonce      []byte            `json:"nonce"`
+	CipherText []byte            `json:"cipher_text"`
+}
+
+// EncryptKeystore encrypts the private key with the passphrase and returns the
+// JSON encoded Keystore.
+func EncryptKeystore(pk PrivateKey, passphrase []byte) ([]byte, error) {
+	return encryptKeystore(pk, passphrase, DefaultKeystoreKDFParams)
+}
+
+func encryptKeystore(pk PrivateKey, passphrase []byte, params KeystoreKDFParams) ([]byte, error) {
+	if err := pk.IsValid(); err != nil {
+		return nil, err
+	}
+
+	params.Salt = make([]byte, 32)
+	if _, err := rand.Read(params.Salt); err != nil {
+		return nil, err
+	}
+
+	ks := Keystore{
+		Version:   KeystoreVersion,
+		Type:      pk.Type().Name(),
+		PublicKey: pk.PublicKey().String(),
+		KDF:       KeystoreKDF,
+		KDFParams: params,
+		Cipher:    KeystoreCipher,
+	}
+
+	aead, err := ks.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	ks.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(ks.Nonce); err != nil {
+		return nil, err
+	}
+
+	plain, err := rlp.EncodeToBytes(pk)
+	if err != nil {
+		return nil, err
+	}
+
+	ad, err := ks.additionalData()
+	if err != nil {
+		return nil, err
+	}
+
+	ks.CipherText = aead.Seal(nil, ks.Nonce, plain, ad)
+
+	return json.MarshalIndent(ks, "", "  ")
+}
+
+// DecryptKeystore decrypts the JSON encoded Keystore with the passphrase.
+func DecryptKeystore(b []byte, passphrase []byte) (PrivateKey, error) {
+	var ks Keystore
+	if err := json.Unmarshal(b, &ks); err != nil {
+		return nil, InvalidKeystoreError.New(err)
+	}
+
+	if err := ks.IsValid(); err != nil {
+		return nil, err
+	}
+
+	aead, err := ks.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	ad, err := ks.additionalData()
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := aead.Open(nil, ks.Nonce, ks.CipherText, ad)
+	if err != nil {
+		return nil, KeystoreDecryptionFailedError.New(err)
+	}
+
+	pk, err := DecodePrivateKey(plain)
+	if err != nil {
+		return nil, InvalidKeystoreError.New(err)
+	}
+
+	if pk.Type().Name() != ks.Type {
+		return nil, InvalidKeystoreError.Newf("type does not match; type=%q key=%q", ks.Type, pk.Type())
+	} else if pk.PublicKey().String() != ks.PublicKey {
+		return nil, InvalidKeystoreError.Newf("public key does not match; public_key=%q", ks.PublicKey)
+	}
+
+	return pk, nil
+}
+
+func (ks Keystore) IsValid() error {
+	if ks.Version != KeystoreVersion {
+		return InvalidKeystoreError.Newf("unknown version; version=%d", ks.Version)
+	}
+
+	if ks.KDF != KeystoreKDF {
+		return InvalidKeystoreError.Newf("unknown kdf; kdf=%q", ks.KDF)
+	}
+
+	if err := ks.KDFParams.IsValid(); err != nil {
+		return err
+	}
+
+	if ks.Cipher != KeystoreCipher {
+		return InvalidKeystoreError.Newf("unknown cipher; cipher=%q", ks.Cipher)
+	}
+
+	if len(ks.Nonce) < 1 {
+		return InvalidKeystoreError.Newf("empty nonce")
+	}
+
+	if len(ks.CipherText) < 1 {
+		return InvalidKeystoreError.Newf("empty cipher_text")
+	}
+
+	return nil
+}
+
+func (ks Keystore) aead(passphrase []byte) (cipher.AEAD, error) {
+	p := ks.KDFParams
+
+	key, err := scrypt.Key(passphrase, p.Salt, p.N, p.R, p.P, p.KeyLen)
+	if err != nil {
+		return nil, InvalidKeystoreError.New(err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if ks.Nonce != nil && len(ks.Nonce) != aead.NonceSize() {
+		return nil, InvalidKeystoreError.Newf("invalid nonce size; nonce=%d", len(ks.Nonce))
+	}
+
+	return aead, nil
+}
+
+func (ks Keystore) additionalData() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"version":    ks.Version,
+		"type":       ks.Type,
+		"public_key": ks.PublicKey,
+		"kdf":        ks.KDF,
+		"kdf_params": ks.KDFParams,
+		"cipher":     ks.Cipher,
+	})
+}
@@ -0,0 +1,124 @@
+package keypair
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type testKeystore struct {
+	suite.Suite
+	params KeystoreKDFParams
+}
+
+func (t *testKeystore) SetupSuite() {
+	// NOTE lighter kdf for test
+	t.params = KeystoreKDFParams{N: 1 << 10, R: 8, P: 1, KeyLen: 32}
+}
+
+func (t *testKeystore) TestEncryptDecrypt() {
+	passphrase := []byte("show me")
+
+	st, _ := NewStellarPrivateKey()
+	sp, _ := NewSecp256k1PrivateKey()
+	bl, _ := NewBLSPrivateKey()
+
+	for _, pk := range []PrivateKey{st, sp, bl} {
+		b, err := encryptKeystore(pk, passphrase, t.params)
+		t.NoError(err)
+		t.NotContains(string(b), pk.String())
+
+		upk, err := DecryptKeystore(b, passphrase)
+		t.NoError(err)
+		t.True(pk.Type().Equal(upk.Type()))
+		t.True(pk.Equal(upk))
+	}
+}
+
+func (t *testKeystore) TestWrongPassphrase() {
+	pk, _ := NewStellarPrivateKey()
+
+	b, err := encryptKeystore(pk, []byte("show me"), t.params)
+	t.NoError(err)
+
+	_, err = DecryptKeystore(b, []byte("killme"))
+	t.True(xerrors.Is(err, KeystoreDecryptionFailedError))
+}
+
+func (t *testKeystore) TestTamperedHeader() {
+	pk, _ := NewStellarPrivateKey()
+	another, _ := NewStellarPrivateKey()
+
+	b, err := encryptKeystore(pk, []byte("show me"), t.params)
+	t.NoError(err)
+
+	var ks Keystore
+	t.NoError(json.Unmarshal(b, &ks))
+
+	ks.PublicKey = another.PublicKey().String()
+	tb, err := json.Marshal(ks)
+	t.NoError(err)
+
+	_, err = DecryptKeystore(tb, []byte("show me"))
+	t.True(xerrors.Is(err, KeystoreDecryptionFailedError))
+}
+
+func (t *testKeystore) TestInvalid() {
+	pk, _ := NewStellarPrivateKey()
+
+	b, err := encryptKeystore(pk, []byte("show me"), t.params)
+	t.NoError(err)
+
+	var ks Keystore
+	t.NoError(json.Unmarshal(b, &ks))
+
+	{ // unknown version
+		k := ks
+		k.Version = 0
+		tb, _ := json.Marshal(k)
+		_, err := DecryptKeystore(tb, []byte("show me"))
+		t.True(xerrors.Is(err, InvalidKeystoreError))
+	}
+
+	{ // wrong kdf params
+		k := ks
+		k.KDFParams.N = 3
+		tb, _ := json.Marshal(k)
+		_, err := DecryptKeystore(tb, []byte("show me"))
+		t.True(xerrors.Is(err, InvalidKeystoreError))
+	}
+
+	{ // too large kdf params
+		for _, f := range []func(*KeystoreKDFParams){
+			func(p *KeystoreKDFParams) { p.N = 1 << 21 },
+			func(p *KeystoreKDFParams) { p.R = 33 },
+			func(p *KeystoreKDFParams) { p.P = 3 },
+			func(p *KeystoreKDFParams) { p.N = 1 << 20; p.R = 32 }, // 4GiB
+			func(p *KeystoreKDFParams) { p.N = 1 << 19; p.R = 8 },  // 512MiB
+		} {
+			k := ks
+			f(&k.KDFParams)
+			tb, _ := json.Marshal(k)
+			_, err := DecryptKeystore(tb, []byte("show me"))
+			t.True(xerrors.Is(err, InvalidKeystoreError))
+			t.Contains(err.Error(), "too large")
+		}
+	}
+
+	{ // the memory of DefaultKeystoreKDFParams is allowed
+		params := DefaultKeystoreKDFParams
+		params.Salt = ks.KDFParams.Salt
+		t.NoError(params.IsValid())
+	}
+
+	{ // not json
+		_, err := DecryptKeystore([]byte("killme"), []byte("show me"))
+		t.True(xerrors.Is(err, InvalidKeystoreError))
+	}
+}
+
+func TestKeystore(t *testing.T) {
+	suite.Run(t, new(testKeystore))
+}
@@ -0,0 +1,89 @@
+package keypair
+
+import (
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// ParseKey parses the string form of Key, `<key>:<kind>:<type>`; the returned
+// Key is PublicKey or PrivateKey by the kind.
+func ParseKey(s string) (Key, error) {
+	ss := strings.Split(s, ":")
+	if len(ss) != 3 || len(ss[0]) < 1 {
+		return nil, FailedToEncodeKeypairError.Newf("invalid key string; %q", s)
+	}
+
+	var kind Kind
+	switch ss[1] {
+	case PublicKeyKind.String():
+		kind = PublicKeyKind
+	case PrivateKeyKind.String():
+		kind = PrivateKeyKind
+	default:
+		return nil, UnknownKeyKindError.Newf("kind=%q", ss[1])
+	}
+
+	var k Key
+	var err error
+	switch ss[2] {
+	case StellarType.Name():
+		k, err = parseStellarKey(ss[0], kind)
+	case Secp256k1Type.Name():
+		k, err = parseSecp256k1Key(ss[0], kind)
+	case BLSType.Name():
+		k, err = parseBLSKey(ss[0], kind)
+	default:
+		return nil, KeypairNotRegisteredError.Newf("type=%q", ss[2])
+	}
+
+	if err != nil {
+		return nil, FailedToEncodeKeypairError.New(err)
+	}
+
+	if err := k.IsValid(); err != nil {
+		return nil, FailedToEncodeKeypairError.New(err)
+	}
+
+	return k, nil
+}
+
+// ParsePublicKey parses the string form of PublicKey.
+func ParsePublicKey(s string) (PublicKey, error) {
+	k, err := ParseKey(s)
+	if err != nil {
+		return nil, err
+	}
+
+	pk, ok := k.(PublicKey)
+	if !ok {
+		return nil, UnknownKeyKindError.Newf("not public key; kind=%q", k.Kind())
+	}
+
+	return pk, nil
+}
+
+// ParsePrivateKey parses the string form of PrivateKey.
+func ParsePrivateKey(s string) (PrivateKey, error) {
+	k, err := ParseKey(s)
+	if err != nil {
+		return nil, err
+	}
+
+	pk, ok := k.(PrivateKey)
+	if !ok {
+		return nil, UnknownKeyKindError.Newf("not private key; kind=%q", k.Kind())
+	}
+
+	return pk, nil
+}
+
+// ParseSignature parses the base58 encoded Signature.
+func ParseSignature(s string) (Signature, error) {
+	b := base58.Decode(s)
+	if len(b) < 1 {
+		return nil, SignatureVerificationFailedError.Newf("invalid signature string; %q", s)
+	}
+
+	return Signature(b), nil
+}
@@ -0,0 +1,80 @@
+package keypair
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type testParse struct {
+	suite.Suite
+}
+
+func (t *testParse) TestParseKey() {
+	st, _ := NewStellarPrivateKey()
+	sp, _ := NewSecp256k1PrivateKey()
+	bl, _ := NewBLSPrivateKey()
+
+	for _, pr := range []PrivateKey{st, sp, bl} {
+		upr, err := ParsePrivateKey(pr.String())
+		t.NoError(err)
+		t.True(pr.Equal(upr))
+
+		upk, err := ParsePublicKey(pr.PublicKey().String())
+		t.NoError(err)
+		t.True(pr.PublicKey().Equal(upk))
+
+		k, err := ParseKey(pr.PublicKey().String())
+		t.NoError(err)
+		_, ok := k.(PublicKey)
+		t.True(ok)
+
+		// NOTE wrong kind
+		_, err = ParsePublicKey(pr.String())
+		t.True(xerrors.Is(err, UnknownKeyKindError))
+	}
+}
+
+func (t *testParse) TestParseInvalidKey() {
+	st, _ := NewStellarPrivateKey()
+
+	cases := map[string]error{
+		"":                               FailedToEncodeKeypairError,
+		"findme":                         FailedToEncodeKeypairError,
+		"findme:public:unknown":          KeypairNotRegisteredError,
+		"findme:unknown:stellar":         UnknownKeyKindError,
+		"findme:public:stellar":          FailedToEncodeKeypairError,
+		"findme:public:secp256k1":        FailedToEncodeKeypairError,
+		st.PublicKey().String() + ":a":   FailedToEncodeKeypairError,
+		st.String()[:56] + ":public:bls": FailedToEncodeKeypairError,
+	}
+
+	for s, e := range cases {
+		_, err := ParseKey(s)
+		t.True(xerrors.Is(err, e), "%q: %v", s, err)
+	}
+
+	// NOTE stellar seed with public kind
+	_, err := ParseKey(st.String()[:56] + ":public:stellar")
+	t.True(xerrors.Is(err, FailedToEncodeKeypairError))
+}
+
+func (t *testParse) TestParseSignature() {
+	pr, _ := NewSecp256k1PrivateKey()
+
+	sig, err := pr.Sign([]byte("show me"))
+	t.NoError(err)
+
+	usig, err := ParseSignature(sig.String())
+	t.NoError(err)
+	t.True(sig.Equal(usig))
+	t.NoError(pr.PublicKey().Verify([]byte("show me"), usig))
+
+	_, err = ParseSignature("0OIl")
+	t.Error(err)
+}
+
+func TestParse(t *testing.T) {
+	suite.Run(t, new(testParse))
+}
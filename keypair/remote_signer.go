@@ -0,0 +1,373 @@
+package keypair
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/rs/zerolog"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+)
+
+const (
+	remoteSignerOpPublicKey string = "public_key"
+	remoteSignerOpSign      string = "sign"
+)
+
+// remoteSignerRequest and remoteSignerResponse are exchanged as one JSON line.
+type remoteSignerRequest struct {
+	Op    string `json:"op"`
+	Input []byte `json:"input,omitempty"`
+}
+
+type remoteSignerResponse struct {
+	PublicKey []byte    `json:"public_key,omitempty"` // RLP encoded PublicKey
+	Signature Signature `json:"signature,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// RemoteSignerServer holds the private key and signs the requests from
+// RemoteSigner over the unix socket.
+type RemoteSignerServer struct {
+	sync.RWMutex
+	*common.Logger
+	socket   string
+	pk       PrivateKey
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+}
+
+func NewRemoteSignerServer(socket string, pk PrivateKey) *RemoteSignerServer {
+	return &RemoteSignerServer{
+		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
+			return c.Str("module", "remote-signer-server").Str("socket", socket)
+		}),
+		socket: socket,
+		pk:     pk,
+		conns:  map[net.Conn]struct{}{},
+	}
+}
+
+func (rs *RemoteSignerServer) Start() error {
+	rs.Lock()
+	defer rs.Unlock()
+
+	if rs.listener != nil {
+		return common.DaemonAleadyStartedError
+	}
+
+	if err := rs.removeStaleSocket(); err != nil {
+		return err
+	}
+
+	listener, err := listenUnixSocket(rs.socket)
+	if err != nil {
+		return err
+	}
+
+	rs.listener = listener
+
+	go rs.accept(listener)
+
+	rs.Log().Debug().Msg("started")
+
+	return nil
+}
+
+func (rs *RemoteSignerServer) Stop() error {
+	rs.Lock()
+	defer rs.Unlock()
+
+	if rs.listener == nil {
+		return nil
+	}
+
+	if err := rs.listener.Close(); err != nil {
+		return err
+	}
+	rs.listener = nil
+
+	_ = os.Remove(rs.socket)
+
+	for conn := range rs.conns {
+		_ = conn.Close()
+	}
+	rs.conns = map[net.Conn]struct{}{}
+
+	rs.Log().Debug().Msg("stopped")
+
+	return nil
+}
+
+func (rs *RemoteSignerServer) IsStopped() bool {
+	rs.RLock()
+	defer rs.RUnlock()
+
+	return rs.listener == nil
+}
+
+// removeStaleSocket removes the socket file, which is left by the dead
+// server; if the other server is still listening, it fails.
+func (rs *RemoteSignerServer) removeStaleSocket() error {
+	fi, err := os.Lstat(rs.socket)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	} else if fi.Mode()&os.ModeSocket == 0 {
+		return xerrors.Errorf("socket path is not socket; path=%q mode=%s", rs.socket, fi.Mode())
+	}
+
+	if conn, err := net.DialTimeout("unix", rs.socket, time.Second); err == nil {
+		_ = conn.Close()
+		return xerrors.Errorf("the other server is listening the socket; path=%q", rs.socket)
+	}
+
+	return os.Remove(rs.socket)
+}
+
+// listenUnixSocket listens the socket inside the new directory, which only the
+// owner can access, and then moves it to the path, so the socket can not be
+// connected by the others before its permission is restricted.
+func listenUnixSocket(socket string) (net.Listener, error) {
+	dir, err := ioutil.TempDir(filepath.Dir(socket), ".remote-signer")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	temp := filepath.Join(dir, "signer.sock")
+	listener, err := net.Listen("unix", temp)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE the socket file will be removed by Stop()
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	if err := os.Chmod(temp, 0600); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	if err := os.Rename(temp, socket); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	return listener, nil
+}
+
+func (rs *RemoteSignerServer) accept(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !rs.IsStopped() {
+				rs.Log().Error().Err(err).Msg("failed to accept")
+			}
+
+			return
+		}
+
+		rs.Lock()
+		if rs.listener == nil {
+			rs.Unlock()
+			_ = conn.Close()
+			return
+		}
+		rs.conns[conn] = struct{}{}
+		rs.Unlock()
+
+		go rs.handle(conn)
+	}
+}
+
+func (rs *RemoteSignerServer) handle(conn net.Conn) {
+	defer func() {
+		rs.Lock()
+		delete(rs.conns, conn)
+		rs.Unlock()
+
+		_ = conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		var req remoteSignerRequest
+
+		var res remoteSignerResponse
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			res.Error = err.Error()
+		} else {
+			res = rs.response(req)
+		}
+
+		if err := encoder.Encode(res); err != nil {
+			rs.Log().Error().Err(err).Msg("failed to write response")
+			return
+		}
+	}
+}
+
+func (rs *RemoteSignerServer) response(req remoteSignerRequest) remoteSignerResponse {
+	var res remoteSignerResponse
+
+	switch req.Op {
+	case remoteSignerOpPublicKey:
+		b, err := rlp.EncodeToBytes(rs.pk.PublicKey())
+		if err != nil {
+			res.Error = err.Error()
+		} else {
+			res.PublicKey = b
+		}
+	case remoteSignerOpSign:
+		sig, err := rs.pk.Sign(req.Input)
+		if err != nil {
+			res.Error = err.Error()
+		} else {
+			res.Signature = sig
+		}
+
+		rs.Log().Debug().Int("input", len(req.Input)).Err(err).Msg("signed")
+	default:
+		res.Error = xerrors.Errorf("unknown op; op=%q", req.Op).Error()
+	}
+
+	return res
+}
+
+// RemoteSigner is the KeySigner, which requests signing to the
+// RemoteSignerServer. The signature from the server is verified with the public
+// key before returned.
+type RemoteSigner struct {
+	sync.Mutex
+	socket    string
+	timeout   time.Duration
+	conn      net.Conn
+	reader    *bufio.Reader
+	publicKey PublicKey
+}
+
+// NewRemoteSigner connects to the RemoteSignerServer and gets the public key.
+func NewRemoteSigner(socket string, timeout time.Duration) (*RemoteSigner, error) {
+	rs := &RemoteSigner{socket: socket, timeout: timeout}
+
+	res, err := rs.request(remoteSignerRequest{Op: remoteSignerOpPublicKey})
+	if err != nil {
+		return nil, err
+	}
+
+	pk, err := DecodePublicKey(res.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	rs.publicKey = pk
+
+	return rs, nil
+}
+
+func (rs *RemoteSigner) PublicKey() PublicKey {
+	return rs.publicKey
+}
+
+func (rs *RemoteSigner) Sign(input []byte) (Signature, error) {
+	res, err := rs.request(remoteSignerRequest{Op: remoteSignerOpSign, Input: input})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rs.publicKey.Verify(input, res.Signature); err != nil {
+		return nil, xerrors.Errorf("invalid signature from remote signer: %w", err)
+	}
+
+	return res.Signature, nil
+}
+
+func (rs *RemoteSigner) Close() error {
+	rs.Lock()
+	defer rs.Unlock()
+
+	return rs.close()
+}
+
+func (rs *RemoteSigner) close() error {
+	if rs.conn == nil {
+		return nil
+	}
+
+	err := rs.conn.Close()
+	rs.conn = nil
+	rs.reader = nil
+
+	return err
+}
+
+func (rs *RemoteSigner) request(req remoteSignerRequest) (remoteSignerResponse, error) {
+	rs.Lock()
+	defer rs.Unlock()
+
+	res, err := rs.send(req)
+	if err != nil {
+		// NOTE the server may be restarted; retry once with new connection
+		_ = rs.close()
+
+		res, err = rs.send(req)
+		if err != nil {
+			_ = rs.close()
+			return remoteSignerResponse{}, err
+		}
+	}
+
+	if len(res.Error) > 0 {
+		return remoteSignerResponse{}, xerrors.Errorf("remote signer: %s", res.Error)
+	}
+
+	return res, nil
+}
+
+func (rs *RemoteSigner) send(req remoteSignerRequest) (remoteSignerResponse, error) {
+	if rs.conn == nil {
+		conn, err := net.DialTimeout("unix", rs.socket, rs.timeout)
+		if err != nil {
+			return remoteSignerResponse{}, err
+		}
+
+		rs.conn = conn
+		rs.reader = bufio.NewReader(conn)
+	}
+
+	if err := rs.conn.SetDeadline(time.Now().Add(rs.timeout)); err != nil {
+		return remoteSignerResponse{}, err
+	}
+
+	b, err := json.Marshal(req)
+	if err != nil {
+		return remoteSignerResponse{}, err
+	}
+
+	if _, err := rs.conn.Write(append(b, '\n')); err != nil {
+		return remoteSignerResponse{}, err
+	}
+
+	line, err := rs.reader.ReadBytes('\n')
+	if err != nil {
+		return remoteSignerResponse{}, err
+	}
+
+	var res remoteSignerResponse
+	if err := json.Unmarshal(line, &res); err != nil {
+		return remoteSignerResponse{}, err
+	}
+
+	return res, nil
+}
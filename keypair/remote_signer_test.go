@@ -0,0 +1,157 @@
+package keypair
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type testRemoteSigner struct {
+	suite.Suite
+	dir string
+}
+
+func (t *testRemoteSigner) SetupTest() {
+	dir, err := ioutil.TempDir("", "remote-signer")
+	t.NoError(err)
+	t.dir = dir
+}
+
+func (t *testRemoteSigner) TearDownTest() {
+	_ = os.RemoveAll(t.dir)
+}
+
+func (t *testRemoteSigner) newServer(pk PrivateKey) (*RemoteSignerServer, string) {
+	socket := filepath.Join(t.dir, "signer.sock")
+
+	server := NewRemoteSignerServer(socket, pk)
+	t.NoError(server.Start())
+
+	return server, socket
+}
+
+func (t *testRemoteSigner) TestSign() {
+	pk, _ := NewSecp256k1PrivateKey()
+	server, socket := t.newServer(pk)
+	defer server.Stop()
+
+	signer, err := NewRemoteSigner(socket, time.Second)
+	t.NoError(err)
+	defer signer.Close()
+
+	t.True(pk.PublicKey().Equal(signer.PublicKey()))
+
+	input := []byte("show me")
+	sig, err := signer.Sign(input)
+	t.NoError(err)
+	t.NoError(pk.PublicKey().Verify(input, sig))
+
+	// NOTE KeySigner
+	var _ KeySigner = signer
+	var _ KeySigner = pk
+}
+
+func (t *testRemoteSigner) TestServerRestarted() {
+	pk, _ := NewStellarPrivateKey()
+	server, socket := t.newServer(pk)
+
+	signer, err := NewRemoteSigner(socket, time.Second)
+	t.NoError(err)
+	defer signer.Close()
+
+	_, err = signer.Sign([]byte("show me"))
+	t.NoError(err)
+
+	t.NoError(server.Stop())
+
+	_, err = signer.Sign([]byte("show me"))
+	t.Error(err)
+
+	server, _ = t.newServer(pk)
+	defer server.Stop()
+
+	sig, err := signer.Sign([]byte("show me"))
+	t.NoError(err)
+	t.NoError(pk.PublicKey().Verify([]byte("show me"), sig))
+}
+
+func (t *testRemoteSigner) TestSocketPermission() {
+	pk, _ := NewSecp256k1PrivateKey()
+	server, socket := t.newServer(pk)
+	defer server.Stop()
+
+	fi, err := os.Stat(socket)
+	t.NoError(err)
+	t.Equal(os.FileMode(0600), fi.Mode().Perm())
+
+	// NOTE the temporary directory is removed
+	files, err := ioutil.ReadDir(t.dir)
+	t.NoError(err)
+	t.Equal(1, len(files))
+
+	t.NoError(server.Stop())
+
+	_, err = os.Stat(socket)
+	t.True(os.IsNotExist(err))
+}
+
+func (t *testRemoteSigner) TestAlreadyListening() {
+	pk, _ := NewSecp256k1PrivateKey()
+	server, socket := t.newServer(pk)
+	defer server.Stop()
+
+	other := NewRemoteSignerServer(socket, pk)
+	t.Error(other.Start())
+
+	// NOTE the running server is not disturbed
+	signer, err := NewRemoteSigner(socket, time.Second)
+	t.NoError(err)
+	defer signer.Close()
+
+	_, err = signer.Sign([]byte("show me"))
+	t.NoError(err)
+}
+
+func (t *testRemoteSigner) TestStaleSocket() {
+	socket := filepath.Join(t.dir, "signer.sock")
+
+	// NOTE the socket file is left without the server
+	listener, err := net.Listen("unix", socket)
+	t.NoError(err)
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	t.NoError(listener.Close())
+
+	_, err = os.Stat(socket)
+	t.NoError(err)
+
+	pk, _ := NewSecp256k1PrivateKey()
+	server := NewRemoteSignerServer(socket, pk)
+	t.NoError(server.Start())
+	defer server.Stop()
+}
+
+func (t *testRemoteSigner) TestNotSocket() {
+	socket := filepath.Join(t.dir, "signer.sock")
+	t.NoError(ioutil.WriteFile(socket, []byte("findme"), 0600))
+
+	pk, _ := NewSecp256k1PrivateKey()
+	t.Error(NewRemoteSignerServer(socket, pk).Start())
+
+	b, err := ioutil.ReadFile(socket)
+	t.NoError(err)
+	t.Equal([]byte("findme"), b)
+}
+
+func (t *testRemoteSigner) TestNoServer() {
+	_, err := NewRemoteSigner(filepath.Join(t.dir, "unknown.sock"), time.Second)
+	t.Error(err)
+}
+
+func TestRemoteSigner(t *testing.T) {
+	suite.Run(t, new(testRemoteSigner))
+}
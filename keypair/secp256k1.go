@@ -0,0 +1,326 @@
+package keypair
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/common"
+)
+
+var (
+	Secp256k1Type common.DataType = common.NewDataType(2, "secp256k1")
+)
+
+type Secp256k1 struct {
+}
+
+func (s Secp256k1) Type() common.DataType {
+	return Secp256k1Type
+}
+
+// New generates the new random keypair
+func (s Secp256k1) New() (PrivateKey, error) {
+	return NewSecp256k1PrivateKey()
+}
+
+// NewFromSeed generates the keypair from raw seed; seed should be 32 bytes.
+func (s Secp256k1) NewFromSeed(b []byte) (PrivateKey, error) {
+	pk, err := crypto.ToECDSA(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return Secp256k1PrivateKey{pk: pk}, nil
+}
+
+// secp256k1Digest makes the 32 bytes digest of input for signing and
+// verification.
+func secp256k1Digest(input []byte) []byte {
+	return crypto.Keccak256(input)
+}
+
+type Secp256k1PublicKey struct {
+	pk *ecdsa.PublicKey
+}
+
+func (s Secp256k1PublicKey) Type() common.DataType {
+	return Secp256k1Type
+}
+
+func (s Secp256k1PublicKey) Kind() Kind {
+	return PublicKeyKind
+}
+
+func (s Secp256k1PublicKey) Verify(input []byte, sig Signature) error {
+	if s.pk == nil {
+		return SignatureVerificationFailedError.Newf("empty public key")
+	}
+
+	// NOTE recovery id is not used for verification
+	if len(sig) == crypto.SignatureLength {
+		sig = sig[:crypto.SignatureLength-1]
+	}
+
+	if !crypto.VerifySignature(s.NativePublicKey(), secp256k1Digest(input), []byte(sig)) {
+		return SignatureVerificationFailedError.Newf("invalid signature")
+	}
+
+	return nil
+}
+
+func (s Secp256k1PublicKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s Secp256k1PublicKey) String() string {
+	return fmt.Sprintf("%s:%s:%s", base58.Encode(s.NativePublicKey()), s.Kind(), s.Type())
+}
+
+func (s Secp256k1PublicKey) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, struct {
+		Type common.DataType
+		Kind Kind
+		Key  []byte
+	}{
+		Type: s.Type(),
+		Kind: s.Kind(),
+		Key:  s.NativePublicKey(),
+	})
+}
+
+func (s *Secp256k1PublicKey) DecodeRLP(st *rlp.Stream) error {
+	var d struct {
+		Type common.DataType
+		Kind Kind
+		Key  []byte
+	}
+	if err := st.Decode(&d); err != nil {
+		return err
+	}
+
+	if !s.Type().Equal(d.Type) {
+		return FailedToEncodeKeypairError.Newf("not secp256k1 keypair type; type=%q", d.Type)
+	}
+
+	if s.Kind() != d.Kind {
+		return FailedToEncodeKeypairError.Newf("not public type; kind=%q", d.Kind)
+	}
+
+	pk, err := crypto.DecompressPubkey(d.Key)
+	if err != nil {
+		return FailedToEncodeKeypairError.New(err)
+	}
+
+	s.pk = pk
+
+	return nil
+}
+
+func (s Secp256k1PublicKey) Equal(k Key) bool {
+	if !s.Type().Equal(k.Type()) {
+		return false
+	}
+
+	if s.Kind() != k.Kind() {
+		return false
+	}
+
+	ks, ok := k.(Secp256k1PublicKey)
+	if !ok {
+		return false
+	}
+
+	if s.pk == nil || ks.pk == nil {
+		return s.pk == ks.pk
+	}
+
+	return s.pk.X.Cmp(ks.pk.X) == 0 && s.pk.Y.Cmp(ks.pk.Y) == 0
+}
+
+// NativePublicKey returns the compressed public key.
+func (s Secp256k1PublicKey) NativePublicKey() []byte {
+	if s.pk == nil {
+		return nil
+	}
+
+	return crypto.CompressPubkey(s.pk)
+}
+
+func (s Secp256k1PublicKey) IsValid() error {
+	if s.pk == nil {
+		return FailedToEncodeKeypairError.Newf("empty public key")
+	}
+
+	if !crypto.S256().IsOnCurve(s.pk.X, s.pk.Y) {
+		return FailedToEncodeKeypairError.Newf("public key is not on curve")
+	}
+
+	return nil
+}
+
+type Secp256k1PrivateKey struct {
+	pk *ecdsa.PrivateKey
+}
+
+func NewSecp256k1PrivateKey() (PrivateKey, error) {
+	pk, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return Secp256k1PrivateKey{pk: pk}, nil
+}
+
+func (s Secp256k1PrivateKey) Type() common.DataType {
+	return Secp256k1Type
+}
+
+func (s Secp256k1PrivateKey) Kind() Kind {
+	return PrivateKeyKind
+}
+
+func (s Secp256k1PrivateKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s Secp256k1PrivateKey) String() string {
+	return fmt.Sprintf("%s:%s:%s", base58.Encode(s.NativePrivateKey()), s.Kind(), s.Type())
+}
+
+func (s Secp256k1PrivateKey) Sign(input []byte) (Signature, error) {
+	if s.pk == nil {
+		return nil, FailedToEncodeKeypairError.Newf("empty private key")
+	}
+
+	sig, err := crypto.Sign(secp256k1Digest(input), s.pk)
+	if err != nil {
+		return nil, err
+	}
+
+	return Signature(sig), nil
+}
+
+func (s Secp256k1PrivateKey) EncodeRLP(w io.Writer) error {
+	return rlp.Encode(w, struct {
+		Type common.DataType
+		Kind Kind
+		Key  []byte
+	}{
+		Type: s.Type(),
+		Kind: s.Kind(),
+		Key:  s.NativePrivateKey(),
+	})
+}
+
+func (s *Secp256k1PrivateKey) DecodeRLP(st *rlp.Stream) error {
+	var d struct {
+		Type common.DataType
+		Kind Kind
+		Key  []byte
+	}
+	if err := st.Decode(&d); err != nil {
+		return err
+	}
+
+	if !s.Type().Equal(d.Type) {
+		return FailedToEncodeKeypairError.Newf("not secp256k1 keypair type; type=%q", d.Type)
+	}
+
+	if s.Kind() != d.Kind {
+		return FailedToEncodeKeypairError.Newf("not private; kind=%q", d.Kind)
+	}
+
+	pk, err := crypto.ToECDSA(d.Key)
+	if err != nil {
+		return FailedToEncodeKeypairError.New(err)
+	}
+
+	s.pk = pk
+
+	return nil
+}
+
+func (s Secp256k1PrivateKey) Equal(k Key) bool {
+	if !s.Type().Equal(k.Type()) {
+		return false
+	}
+
+	if s.Kind() != k.Kind() {
+		return false
+	}
+
+	ks, ok := k.(Secp256k1PrivateKey)
+	if !ok {
+		return false
+	}
+
+	if s.pk == nil || ks.pk == nil {
+		return s.pk == ks.pk
+	}
+
+	return s.pk.D.Cmp(ks.pk.D) == 0
+}
+
+// PublicKey returns the empty public key, if the private key is empty.
+func (s Secp256k1PrivateKey) PublicKey() PublicKey {
+	if s.pk == nil {
+		return Secp256k1PublicKey{}
+	}
+
+	return Secp256k1PublicKey{pk: &s.pk.PublicKey}
+}
+
+func (s Secp256k1PrivateKey) NativePublicKey() []byte {
+	return s.PublicKey().NativePublicKey()
+}
+
+func (s Secp256k1PrivateKey) NativePrivateKey() []byte {
+	if s.pk == nil {
+		return nil
+	}
+
+	return crypto.FromECDSA(s.pk)
+}
+
+func (s Secp256k1PrivateKey) IsValid() error {
+	if s.pk == nil {
+		return FailedToEncodeKeypairError.Newf("empty private key")
+	}
+
+	if _, err := crypto.ToECDSA(s.NativePrivateKey()); err != nil {
+		return FailedToEncodeKeypairError.New(err)
+	}
+
+	return nil
+}
+
+func parseSecp256k1Key(key string, kind Kind) (Key, error) {
+	b := base58.Decode(key)
+	if len(b) < 1 {
+		return nil, xerrors.Errorf("invalid base58 key")
+	}
+
+	switch kind {
+	case PublicKeyKind:
+		pk, err := crypto.DecompressPubkey(b)
+		if err != nil {
+			return nil, err
+		}
+
+		return Secp256k1PublicKey{pk: pk}, nil
+	default:
+		pk, err := crypto.ToECDSA(b)
+		if err != nil {
+			return nil, err
+		}
+
+		return Secp256k1PrivateKey{pk: pk}, nil
+	}
+}
@@ -0,0 +1,140 @@
+package keypair
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+)
+
+type testSecp256k1Keypair struct {
+	suite.Suite
+}
+
+func (t *testSecp256k1Keypair) TestNew() {
+	pr0, _ := Secp256k1{}.New()
+	t.Equal(Secp256k1Type, pr0.Type())
+	t.NoError(pr0.IsValid())
+
+	pr1, _ := Secp256k1{}.New()
+	t.False(pr0.Equal(pr1))
+
+	pk := pr0.PublicKey()
+	t.Equal(Secp256k1Type, pk.Type())
+	t.NoError(pk.IsValid())
+	t.True(pk.Equal(pk))
+	t.False(pk.Equal(pr1.PublicKey()))
+}
+
+func (t *testSecp256k1Keypair) TestEncodeRLP() {
+	pr, _ := Secp256k1{}.New()
+
+	{
+		b, err := rlp.EncodeToBytes(pr)
+		t.NoError(err)
+
+		var key Secp256k1PrivateKey
+		t.NoError(rlp.DecodeBytes(b, &key))
+		t.True(pr.Equal(key))
+
+		var upk Secp256k1PublicKey
+		err = rlp.DecodeBytes(b, &upk)
+		t.True(xerrors.Is(err, FailedToEncodeKeypairError))
+		t.Contains(err.Error(), "not public")
+	}
+
+	{
+		b, err := rlp.EncodeToBytes(pr.PublicKey())
+		t.NoError(err)
+
+		var key Secp256k1PublicKey
+		t.NoError(rlp.DecodeBytes(b, &key))
+		t.True(pr.PublicKey().Equal(key))
+
+		var upr Secp256k1PrivateKey
+		err = rlp.DecodeBytes(b, &upr)
+		t.True(xerrors.Is(err, FailedToEncodeKeypairError))
+		t.Contains(err.Error(), "not private")
+	}
+}
+
+func (t *testSecp256k1Keypair) TestDecodeByType() {
+	st, _ := Stellar{}.New()
+	sp, _ := Secp256k1{}.New()
+
+	for _, pr := range []PrivateKey{st, sp} {
+		b, err := rlp.EncodeToBytes(pr.PublicKey())
+		t.NoError(err)
+
+		pk, err := DecodePublicKey(b)
+		t.NoError(err)
+		t.True(pr.PublicKey().Equal(pk))
+
+		b, err = rlp.EncodeToBytes(pr)
+		t.NoError(err)
+
+		upr, err := DecodePrivateKey(b)
+		t.NoError(err)
+		t.True(pr.Equal(upr))
+	}
+
+	// NOTE different type is not equal
+	t.False(st.PublicKey().Equal(sp.PublicKey()))
+}
+
+func (t *testSecp256k1Keypair) TestSigning() {
+	pr, _ := Secp256k1{}.New()
+
+	input := []byte("source")
+	sig, err := pr.Sign(input)
+	t.NoError(err)
+	t.NotEmpty(sig)
+
+	{ // valid input
+		err = pr.PublicKey().Verify(input, sig)
+		t.NoError(err)
+	}
+
+	{ // invalid input
+		err = pr.PublicKey().Verify([]byte("killme"), sig)
+		t.True(xerrors.Is(err, SignatureVerificationFailedError))
+	}
+
+	{ // another key
+		another, _ := Secp256k1{}.New()
+		err = another.PublicKey().Verify(input, sig)
+		t.True(xerrors.Is(err, SignatureVerificationFailedError))
+	}
+}
+
+func (t *testSecp256k1Keypair) TestFromSeed() {
+	seed := []byte("findme-findme-findme-findme-1234")
+
+	pr0, err := Secp256k1{}.NewFromSeed(seed)
+	t.NoError(err)
+	pr1, _ := Secp256k1{}.NewFromSeed(seed)
+
+	t.True(pr0.Equal(pr1))
+	t.True(pr0.PublicKey().Equal(pr1.PublicKey()))
+}
+
+func (t *testSecp256k1Keypair) TestEmpty() {
+	pr := Secp256k1PrivateKey{}
+	t.Error(pr.IsValid())
+
+	pb := pr.PublicKey()
+	t.Error(pb.IsValid())
+	t.Nil(pb.NativePublicKey())
+	t.Nil(pr.NativePublicKey())
+
+	_, err := pr.Sign([]byte("findme"))
+	t.True(xerrors.Is(err, FailedToEncodeKeypairError))
+
+	err = pb.Verify([]byte("findme"), Signature([]byte("showme")))
+	t.True(xerrors.Is(err, SignatureVerificationFailedError))
+}
+
+func TestSecp256k1Keypair(t *testing.T) {
+	suite.Run(t, new(testSecp256k1Keypair))
+}
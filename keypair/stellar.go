@@ -8,6 +8,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	stellarHash "github.com/stellar/go/hash"
 	stellarKeypair "github.com/stellar/go/keypair"
+	"golang.org/x/xerrors"
 
 	"github.com/spikeekips/mitum/common"
 )
@@ -259,3 +260,26 @@ func (s StellarPrivateKey) IsValid() error {
 
 	return nil
 }
+
+func parseStellarKey(key string, kind Kind) (Key, error) {
+	kp, err := stellarKeypair.Parse(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case PublicKeyKind:
+		if _, ok := kp.(*stellarKeypair.FromAddress); !ok {
+			return nil, xerrors.Errorf("not public key")
+		}
+
+		return StellarPublicKey{kp: kp}, nil
+	default:
+		full, ok := kp.(*stellarKeypair.Full)
+		if !ok {
+			return nil, xerrors.Errorf("not private key")
+		}
+
+		return StellarPrivateKey{kp: full}, nil
+	}
+}
@@ -8,7 +8,7 @@ import (
 )
 
 type Signer interface {
-	Sign(PrivateKey, []byte) error
+	Sign(KeySigner, []byte) error
 }
 
 type Kind uint
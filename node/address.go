@@ -1,19 +1,30 @@
 package node
 
 import (
+	"github.com/ethereum/go-ethereum/rlp"
+
 	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/keypair"
 )
 
 var (
 	AddressHashHint string = "na"
 )
 
+// NetworkID is the prefix of the address derivation; the same public key
+// derives the different address by network.
+type NetworkID []byte
+
+// DefaultNetworkID is the NetworkID of the network; the ballot and proposal
+// are validated with it.
+var DefaultNetworkID NetworkID = NetworkID("mitum")
+
 type Address struct {
 	hash.Hash
 }
 
 func NewAddress(b []byte) (Address, error) {
-	h, err := hash.NewDoubleSHAHash(AddressHashHint, b)
+	h, err := hash.NewHashByHint(AddressHashHint, b)
 	if err != nil {
 		return Address{}, err
 	}
@@ -21,10 +32,64 @@ func NewAddress(b []byte) (Address, error) {
 	return Address{Hash: h}, nil
 }
 
+// NewAddressFromPublicKey derives the canonical Address from the public key;
+// the type of public key is also hashed with the network id.
+func NewAddressFromPublicKey(networkID NetworkID, pk keypair.PublicKey) (Address, error) {
+	b, err := addressInput(networkID, pk)
+	if err != nil {
+		return Address{}, err
+	}
+
+	return NewAddress(b)
+}
+
+func addressInput(networkID NetworkID, pk keypair.PublicKey) ([]byte, error) {
+	if len(networkID) < 1 {
+		return nil, InvalidAddressError.Newf("empty network id")
+	} else if pk == nil {
+		return nil, InvalidAddressError.Newf("empty public key")
+	}
+
+	return rlp.EncodeToBytes([]interface{}{[]byte(networkID), pk})
+}
+
 func (ad Address) Equal(nad Address) bool {
 	return ad.Hash.Equal(nad.Hash)
 }
 
+// Verify checks the Address is derived from the public key in the network.
+func (ad Address) Verify(networkID NetworkID, pk keypair.PublicKey) error {
+	if !IsAddress(ad) {
+		return InvalidAddressError.Newf("not address hint; hint=%q", ad.Hint())
+	}
+
+	b, err := addressInput(networkID, pk)
+	if err != nil {
+		return err
+	}
+
+	if err := ad.Hash.Verify(b); err != nil {
+		return InvalidAddressError.Newf("address does not match with public key; address=%q publickey=%q", ad, pk)
+	}
+
+	return nil
+}
+
 func IsAddress(h Address) bool {
 	return h.Hint() == AddressHashHint
 }
+
+// ParseAddress parses the string form of Address.
+func ParseAddress(s string) (Address, error) {
+	h, err := hash.ParseHash(s)
+	if err != nil {
+		return Address{}, err
+	}
+
+	ad := Address{Hash: h}
+	if !IsAddress(ad) {
+		return Address{}, hash.InvalidHashInputError.Newf("not address hint; hint=%q", h.Hint())
+	}
+
+	return ad, nil
+}
@@ -0,0 +1,82 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/hash"
+	"github.com/spikeekips/mitum/keypair"
+)
+
+type testAddress struct {
+	suite.Suite
+}
+
+func (t *testAddress) TestFromPublicKey() {
+	pk, _ := keypair.NewStellarPrivateKey()
+
+	ad, err := NewAddressFromPublicKey(DefaultNetworkID, pk.PublicKey())
+	t.NoError(err)
+	t.True(IsAddress(ad))
+	t.NoError(ad.Verify(DefaultNetworkID, pk.PublicKey()))
+
+	// NOTE same key derives same address
+	nad, err := NewAddressFromPublicKey(DefaultNetworkID, pk.PublicKey())
+	t.NoError(err)
+	t.True(ad.Equal(nad))
+}
+
+func (t *testAddress) TestNetworkID() {
+	pk, _ := keypair.NewStellarPrivateKey()
+
+	ad, err := NewAddressFromPublicKey(DefaultNetworkID, pk.PublicKey())
+	t.NoError(err)
+
+	oad, err := NewAddressFromPublicKey(NetworkID("findme"), pk.PublicKey())
+	t.NoError(err)
+	t.False(ad.Equal(oad))
+
+	err = ad.Verify(NetworkID("findme"), pk.PublicKey())
+	t.True(xerrors.Is(err, InvalidAddressError))
+
+	_, err = NewAddressFromPublicKey(nil, pk.PublicKey())
+	t.True(xerrors.Is(err, InvalidAddressError))
+}
+
+func (t *testAddress) TestWrongPublicKey() {
+	pk, _ := keypair.NewStellarPrivateKey()
+	another, _ := keypair.NewSecp256k1PrivateKey()
+
+	ad, err := NewAddressFromPublicKey(DefaultNetworkID, pk.PublicKey())
+	t.NoError(err)
+
+	err = ad.Verify(DefaultNetworkID, another.PublicKey())
+	t.True(xerrors.Is(err, InvalidAddressError))
+
+	// NOTE the address, which is not derived from the public key
+	rad, err := NewAddress([]byte("show me"))
+	t.NoError(err)
+
+	err = rad.Verify(DefaultNetworkID, pk.PublicKey())
+	t.True(xerrors.Is(err, InvalidAddressError))
+}
+
+func (t *testAddress) TestHashAlgorithm() {
+	t.NoError(hash.DefaultAlgorithms.Set(AddressHashHint, hash.SHA3256))
+	defer hash.DefaultAlgorithms.Reset(hash.DoubleSHA256)
+
+	pk, _ := keypair.NewStellarPrivateKey()
+
+	ad, err := NewAddressFromPublicKey(DefaultNetworkID, pk.PublicKey())
+	t.NoError(err)
+	t.Equal(hash.SHA3256, ad.Algorithm())
+
+	hash.DefaultAlgorithms.Reset(hash.DoubleSHA256)
+	t.NoError(ad.Verify(DefaultNetworkID, pk.PublicKey()))
+}
+
+func TestAddress(t *testing.T) {
+	suite.Run(t, new(testAddress))
+}
@@ -4,8 +4,10 @@ import "github.com/spikeekips/mitum/common"
 
 const (
 	InvalidStateErrorCode common.ErrorCode = iota + 1
+	InvalidAddressErrorCode
 )
 
 var (
-	InvalidStateError = common.NewError("node", InvalidStateErrorCode, "invalid node state")
+	InvalidStateError   = common.NewError("node", InvalidStateErrorCode, "invalid node state")
+	InvalidAddressError = common.NewError("node", InvalidAddressErrorCode, "invalid address")
 )
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/rs/zerolog"
+	"github.com/spikeekips/mitum/common"
 	"github.com/spikeekips/mitum/keypair"
 )
 
@@ -20,11 +21,24 @@ type Home struct {
 	address    Address
 	publicKey  keypair.PublicKey
 	privateKey keypair.PrivateKey
+	signer     keypair.KeySigner
 	alias      string
+	clock      common.Clock
 }
 
 func NewHome(address Address, privateKey keypair.PrivateKey) Home {
-	return Home{address: address, publicKey: privateKey.PublicKey(), privateKey: privateKey}
+	return Home{
+		address:    address,
+		publicKey:  privateKey.PublicKey(),
+		privateKey: privateKey,
+		signer:     privateKey,
+	}
+}
+
+// NewHomeWithSigner creates Home without private key; the seals are signed by
+// the signer.
+func NewHomeWithSigner(address Address, signer keypair.KeySigner) Home {
+	return Home{address: address, publicKey: signer.PublicKey(), signer: signer}
 }
 
 func (hm Home) Address() Address {
@@ -35,10 +49,32 @@ func (hm Home) PublicKey() keypair.PublicKey {
 	return hm.publicKey
 }
 
+// PrivateKey returns the private key; with the remote signer, it is nil.
 func (hm Home) PrivateKey() keypair.PrivateKey {
 	return hm.privateKey
 }
 
+func (hm Home) Signer() keypair.KeySigner {
+	return hm.signer
+}
+
+// SetClock sets the clock of node; the time of the node, like the signed time
+// of seal, comes from the clock. Without clock, the current clock is used.
+func (hm Home) SetClock(c common.Clock) Home {
+	hm.clock = c
+
+	return hm
+}
+
+// Now returns the current time of node.
+func (hm Home) Now() common.Time {
+	if hm.clock == nil {
+		return common.Now()
+	}
+
+	return common.NowOf(hm.clock)
+}
+
 func (hm Home) Equal(o Node) bool {
 	if !hm.address.Equal(o.Address()) {
 		return false
@@ -140,3 +176,13 @@ func (ot Other) MarshalZerologObject(e *zerolog.Event) {
 	e.Object("address", ot.address)
 	e.Str("publickey", ot.publicKey.String())
 }
+
+// NewHomeFromKeystore loads the private key from the encrypted keystore.
+func NewHomeFromKeystore(address Address, keystore []byte, passphrase []byte) (Home, error) {
+	pk, err := keypair.DecryptKeystore(keystore, passphrase)
+	if err != nil {
+		return Home{}, err
+	}
+
+	return NewHome(address, pk), nil
+}
@@ -21,12 +21,14 @@ func NewRandomAddress() Address {
 
 func NewRandomHome() Home {
 	pk, _ := keypair.NewStellarPrivateKey()
+	address, _ := NewAddressFromPublicKey(DefaultNetworkID, pk.PublicKey())
 
-	return NewHome(NewRandomAddress(), pk)
+	return NewHome(address, pk)
 }
 
 func NewRandomOther() (Other, keypair.PrivateKey) {
 	pk, _ := keypair.NewStellarPrivateKey()
+	address, _ := NewAddressFromPublicKey(DefaultNetworkID, pk.PublicKey())
 
-	return NewOther(NewRandomAddress(), pk.PublicKey()), pk
+	return NewOther(address, pk.PublicKey()), pk
 }
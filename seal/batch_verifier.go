@@ -0,0 +1,288 @@
+package seal
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/spikeekips/mitum/common"
+	"github.com/spikeekips/mitum/keypair"
+)
+
+// VerifySeal checks the seal is valid and it's signature is signed with
+// input.
+func VerifySeal(sl Seal, input []byte) error {
+	if err := sl.IsValid(); err != nil {
+		return err
+	}
+
+	if err := sl.CheckSignature(input); err != nil {
+		return InvalidSealError.New(err)
+	}
+
+	return nil
+}
+
+type batchVerifyRequest struct {
+	seal   Seal
+	result chan error
+}
+
+// BatchVerifier collects the incoming seals for a short window and verifies
+// them together.
+// * the signatures of the seals, which are signed by BLS keypair, are verified
+// by one pairing check, keypair.VerifyBLSBatch; if it fails, they are verified
+// one by one to find the invalid seals
+// * the other seals are verified one by one on the worker pool
+// * the batch is verified when the window passed after the first seal of the
+// batch is collected, or the batch is full
+// * Verify() blocks until the result of the seal is returned, so the
+// concurrent callers share the batch
+// * if BatchVerifier is stopped, Verify() verifies the seal directly
+type BatchVerifier struct {
+	sync.RWMutex
+	*common.Logger
+	window  time.Duration
+	size    int
+	workers int
+	input   []byte
+	queue   chan batchVerifyRequest
+	stop    chan struct{}
+}
+
+func NewBatchVerifier(window time.Duration, size, workers uint, input []byte) *BatchVerifier {
+	if size < 1 {
+		size = 1
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &BatchVerifier{
+		Logger: common.NewLogger(func(c zerolog.Context) zerolog.Context {
+			return c.Str("module", "batch-verifier")
+		}),
+		window:  window,
+		size:    int(size),
+		workers: int(workers),
+		input:   input,
+	}
+}
+
+func (bv *BatchVerifier) Start() error {
+	bv.Lock()
+	defer bv.Unlock()
+
+	if bv.stop != nil {
+		return common.DaemonAleadyStartedError
+	}
+
+	bv.queue = make(chan batchVerifyRequest, bv.size)
+	bv.stop = make(chan struct{})
+
+	go bv.loop(bv.queue, bv.stop)
+
+	bv.Log().Debug().
+		Dur("window", bv.window).
+		Int("size", bv.size).
+		Int("workers", bv.workers).
+		Msg("started")
+
+	return nil
+}
+
+func (bv *BatchVerifier) Stop() error {
+	bv.Lock()
+	defer bv.Unlock()
+
+	if bv.stop == nil {
+		return nil
+	}
+
+	close(bv.stop)
+	bv.stop = nil
+	bv.queue = nil
+
+	bv.Log().Debug().Msg("stopped")
+
+	return nil
+}
+
+func (bv *BatchVerifier) IsStopped() bool {
+	bv.RLock()
+	defer bv.RUnlock()
+
+	return bv.stop == nil
+}
+
+// Verify verifies the seal in the next batch.
+func (bv *BatchVerifier) Verify(sl Seal) error {
+	bv.RLock()
+	queue, stop := bv.queue, bv.stop
+	bv.RUnlock()
+
+	if stop == nil {
+		return VerifySeal(sl, bv.input)
+	}
+
+	req := batchVerifyRequest{seal: sl, result: make(chan error, 1)}
+
+	select {
+	case queue <- req:
+	case <-stop:
+		return VerifySeal(sl, bv.input)
+	}
+
+	return <-req.result
+}
+
+// VerifyBatch verifies the seals and returns the result of each seal in the
+// same order.
+func (bv *BatchVerifier) VerifyBatch(seals []Seal) []error {
+	errs := make([]error, len(seals))
+
+	all := make([]int, len(seals))
+	for i := range seals {
+		all[i] = i
+	}
+
+	bv.parallel(all, func(i int) {
+		if err := seals[i].IsValid(); err != nil {
+			errs[i] = err
+		} else if !isBLSSeal(seals[i]) {
+			errs[i] = bv.checkSignature(seals[i])
+		}
+	})
+
+	var bls []int
+	for i := range seals {
+		if errs[i] == nil && isBLSSeal(seals[i]) {
+			bls = append(bls, i)
+		}
+	}
+
+	if len(bls) > 1 {
+		inputs := make([][]byte, len(bls))
+		pks := make([]keypair.PublicKey, len(bls))
+		sigs := make([]keypair.Signature, len(bls))
+		for j, i := range bls {
+			inputs[j] = bv.signatureInput(seals[i])
+			pks[j] = seals[i].Signer()
+			sigs[j] = seals[i].Signature()
+		}
+
+		if err := keypair.VerifyBLSBatch(inputs, pks, sigs); err == nil {
+			return errs
+		}
+	}
+
+	bv.parallel(bls, func(i int) {
+		errs[i] = bv.checkSignature(seals[i])
+	})
+
+	return errs
+}
+
+// parallel runs f with the indices on the worker pool.
+func (bv *BatchVerifier) parallel(indices []int, f func(int)) {
+	if len(indices) < 1 {
+		return
+	}
+
+	workers := bv.workers
+	if workers > len(indices) {
+		workers = len(indices)
+	}
+
+	ch := make(chan int, len(indices))
+	for _, i := range indices {
+		ch <- i
+	}
+	close(ch)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for j := range ch {
+				f(j)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// signatureInput is the signed bytes of seal; same with
+// BaseSeal.CheckSignature().
+func (bv *BatchVerifier) signatureInput(sl Seal) []byte {
+	var n []byte
+	n = append(n, sl.Body().Hash().Bytes()...)
+	n = append(n, bv.input...)
+
+	return n
+}
+
+func (bv *BatchVerifier) checkSignature(sl Seal) error {
+	if err := sl.CheckSignature(bv.input); err != nil {
+		return InvalidSealError.New(err)
+	}
+
+	return nil
+}
+
+func isBLSSeal(sl Seal) bool {
+	return sl.Signer() != nil && sl.Signer().Type().Equal(keypair.BLSType)
+}
+
+func (bv *BatchVerifier) loop(queue chan batchVerifyRequest, stop chan struct{}) {
+	var batch []batchVerifyRequest
+	var timer <-chan time.Time
+
+	flush := func() {
+		if len(batch) < 1 {
+			return
+		}
+
+		go bv.verify(batch)
+
+		batch = nil
+		timer = nil
+	}
+
+	for {
+		select {
+		case <-stop:
+			flush()
+			return
+		case req := <-queue:
+			batch = append(batch, req)
+			if len(batch) >= bv.size {
+				flush()
+			} else if timer == nil {
+				timer = time.After(bv.window)
+			}
+		case <-timer:
+			flush()
+		}
+	}
+}
+
+func (bv *BatchVerifier) verify(batch []batchVerifyRequest) {
+	seals := make([]Seal, len(batch))
+	for i, req := range batch {
+		seals[i] = req.seal
+	}
+
+	errs := bv.VerifyBatch(seals)
+	for i, req := range batch {
+		req.result <- errs[i]
+	}
+
+	bv.Log().Debug().Int("seals", len(batch)).Msg("batch verified")
+}
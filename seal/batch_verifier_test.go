@@ -0,0 +1,224 @@
+package seal
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/xerrors"
+
+	"github.com/spikeekips/mitum/keypair"
+)
+
+type testBatchVerifier struct {
+	suite.Suite
+}
+
+func (t *testBatchVerifier) newSeals(n int) []Seal {
+	pk, _ := keypair.NewStellarPrivateKey()
+
+	var seals []Seal
+	for i := 0; i < n; i++ {
+		sl, err := NewSealBodySigned(pk, "new", uint64(i+1))
+		t.NoError(err)
+		seals = append(seals, sl)
+	}
+
+	return seals
+}
+
+func (t *testBatchVerifier) newWrongSignatureSeal() Seal {
+	pk, _ := keypair.NewStellarPrivateKey()
+
+	sl := NewBaseSeal(NewSealBody("wrong", 33))
+	t.NoError(sl.Sign(pk, []byte("salt")))
+
+	return sl
+}
+
+func (t *testBatchVerifier) TestVerifySeal() {
+	sl := t.newSeals(1)[0]
+	t.NoError(VerifySeal(sl, nil))
+
+	err := VerifySeal(t.newWrongSignatureSeal(), nil)
+	t.True(xerrors.Is(err, InvalidSealError))
+
+	err = VerifySeal(NewBaseSeal(NewSealBody("unsigned", 33)), nil)
+	t.True(xerrors.Is(err, InvalidSealError))
+}
+
+func (t *testBatchVerifier) TestVerifyBatch() {
+	seals := t.newSeals(10)
+	seals[3] = t.newWrongSignatureSeal()
+	seals[7] = NewBaseSeal(NewSealBody("unsigned", 33))
+
+	bv := NewBatchVerifier(time.Millisecond, 4, 3, nil)
+
+	errs := bv.VerifyBatch(seals)
+	t.Equal(len(seals), len(errs))
+
+	for i, err := range errs {
+		switch i {
+		case 3, 7:
+			t.True(xerrors.Is(err, InvalidSealError))
+		default:
+			t.NoError(err)
+		}
+	}
+}
+
+func (t *testBatchVerifier) TestVerifyBatchBLS() {
+	var seals []Seal
+	for i := 0; i < 6; i++ {
+		pk, _ := keypair.NewBLSPrivateKey()
+		sl, err := NewSealBodySigned(pk, "bls", uint64(i+1))
+		t.NoError(err)
+		seals = append(seals, sl)
+	}
+	seals = append(seals, t.newSeals(2)...)
+
+	bv := NewBatchVerifier(time.Millisecond, 4, 3, nil)
+
+	for _, err := range bv.VerifyBatch(seals) {
+		t.NoError(err)
+	}
+
+	// NOTE signed with the different input
+	pk, _ := keypair.NewBLSPrivateKey()
+	wrong := NewBaseSeal(NewSealBody("wrong", 33))
+	t.NoError(wrong.Sign(pk, []byte("salt")))
+	seals[2] = wrong
+
+	for i, err := range bv.VerifyBatch(seals) {
+		if i == 2 {
+			t.True(xerrors.Is(err, InvalidSealError))
+		} else {
+			t.NoError(err)
+		}
+	}
+}
+
+func (t *testBatchVerifier) TestConcurrentVerify() {
+	seals := t.newSeals(30)
+	seals[11] = t.newWrongSignatureSeal()
+
+	bv := NewBatchVerifier(time.Millisecond*10, 8, 4, nil)
+	t.NoError(bv.Start())
+	defer func() {
+		_ = bv.Stop()
+	}()
+
+	errs := make([]error, len(seals))
+
+	var wg sync.WaitGroup
+	wg.Add(len(seals))
+
+	for i := range seals {
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = bv.Verify(seals[i])
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if i == 11 {
+			t.True(xerrors.Is(err, InvalidSealError))
+		} else {
+			t.NoError(err)
+		}
+	}
+}
+
+func (t *testBatchVerifier) TestWindow() {
+	bv := NewBatchVerifier(time.Millisecond*50, 100, 2, nil)
+	t.NoError(bv.Start())
+	defer func() {
+		_ = bv.Stop()
+	}()
+
+	// NOTE the batch is not full, the seal is verified after window
+	started := time.Now()
+	t.NoError(bv.Verify(t.newSeals(1)[0]))
+	t.True(time.Since(started) >= time.Millisecond*50)
+}
+
+func (t *testBatchVerifier) TestStopped() {
+	bv := NewBatchVerifier(time.Millisecond, 10, 2, nil)
+	t.True(bv.IsStopped())
+
+	// NOTE stopped BatchVerifier verifies directly
+	t.NoError(bv.Verify(t.newSeals(1)[0]))
+	t.True(xerrors.Is(bv.Verify(t.newWrongSignatureSeal()), InvalidSealError))
+
+	t.NoError(bv.Start())
+	t.False(bv.IsStopped())
+	t.Error(bv.Start())
+
+	t.NoError(bv.Stop())
+	t.True(bv.IsStopped())
+	t.NoError(bv.Verify(t.newSeals(1)[0]))
+}
+
+func TestBatchVerifier(t *testing.T) {
+	suite.Run(t, new(testBatchVerifier))
+}
+
+func benchmarkSeals(b *testing.B, n int) []Seal {
+	pk, _ := keypair.NewStellarPrivateKey()
+
+	seals := make([]Seal, n)
+	for i := range seals {
+		sl, err := NewSealBodySigned(pk, "new", uint64(i+1))
+		if err != nil {
+			b.Fatal(err)
+		}
+		seals[i] = sl
+	}
+
+	return seals
+}
+
+func BenchmarkVerifySealSequential(b *testing.B) {
+	seals := benchmarkSeals(b, 100)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, sl := range seals {
+			if err := VerifySeal(sl, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkBatchVerifier(b *testing.B) {
+	seals := benchmarkSeals(b, 100)
+
+	bv := NewBatchVerifier(time.Millisecond, 100, 8, nil)
+	if err := bv.Start(); err != nil {
+		b.Fatal(err)
+	}
+	defer func() {
+		_ = bv.Stop()
+	}()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var wg sync.WaitGroup
+		wg.Add(len(seals))
+
+		for _, sl := range seals {
+			go func(sl Seal) {
+				defer wg.Done()
+				if err := bv.Verify(sl); err != nil {
+					b.Error(err)
+				}
+			}(sl)
+		}
+
+		wg.Wait()
+	}
+}
@@ -185,7 +185,7 @@ func (bs BaseSeal) makeHash() (hash.Hash, error) {
 		return hash.Hash{}, err
 	}
 
-	return hash.NewDoubleSHAHash(SealHashHint, b)
+	return hash.NewHashByHint(SealHashHint, b)
 }
 
 func (bs BaseSeal) BodyHash() hash.Hash {
@@ -220,21 +220,27 @@ func (bs BaseSeal) IsValid() error {
 	return nil
 }
 
-func (bs *BaseSeal) Sign(pk keypair.PrivateKey, input []byte) error {
+func (bs *BaseSeal) Sign(signer keypair.KeySigner, input []byte) error {
+	return bs.SignAt(signer, input, common.Now())
+}
+
+// SignAt signs the seal like Sign() with the given signed time; the node signs
+// the seal at the time of it's clock.
+func (bs *BaseSeal) SignAt(signer keypair.KeySigner, input []byte, signedAt common.Time) error {
 	var n []byte
 
 	n = append(n, bs.body.Hash().Bytes()...)
 	n = append(n, input...)
 
-	sig, err := pk.Sign(n)
+	sig, err := signer.Sign(n)
 	if err != nil {
 		return err
 	}
 
 	bs.header.bodyHash = bs.body.Hash()
-	bs.header.signer = pk.PublicKey()
+	bs.header.signer = signer.PublicKey()
 	bs.header.signature = sig
-	bs.header.signedAt = common.Now()
+	bs.header.signedAt = signedAt
 
 	hash, err := bs.makeHash()
 	if err != nil {
@@ -302,7 +308,7 @@ func (hd Header) EncodeRLP(w io.Writer) error {
 
 func (hd *Header) DecodeRLP(s *rlp.Stream) error {
 	var h struct {
-		Signer    keypair.StellarPublicKey
+		Signer    rlp.RawValue
 		Signature keypair.Signature
 		BodyHash  hash.Hash
 		SignedAt  common.Time
@@ -312,7 +318,12 @@ func (hd *Header) DecodeRLP(s *rlp.Stream) error {
 		return err
 	}
 
-	hd.signer = h.Signer
+	signer, err := keypair.DecodePublicKey(h.Signer)
+	if err != nil {
+		return err
+	}
+
+	hd.signer = signer
 	hd.signature = h.Signature
 	hd.bodyHash = h.BodyHash
 	hd.signedAt = h.SignedAt
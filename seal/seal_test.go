@@ -85,6 +85,31 @@ func (t *testSeal) TestEncode() {
 	t.True(xerrors.Is(InvalidSealError, err))
 }
 
+func (t *testSeal) TestSecp256k1Signer() {
+	body := NewSealBody("new", 33)
+	sl := NewBaseSeal(body)
+
+	salt := []byte("salt")
+
+	pk, _ := keypair.NewSecp256k1PrivateKey()
+	err := sl.Sign(pk, salt)
+	t.NoError(err)
+
+	err = sl.CheckSignature(salt)
+	t.NoError(err)
+
+	b, err := rlp.EncodeToBytes(sl)
+	t.NoError(err)
+
+	var decoded BaseSeal
+	err = rlp.DecodeBytes(b, &decoded)
+	t.NoError(err)
+
+	t.Equal(keypair.Secp256k1Type, decoded.Signer().Type())
+	t.True(pk.PublicKey().Equal(decoded.Signer()))
+	t.True(sl.Signature().Equal(decoded.Signature()))
+}
+
 func TestSeal(t *testing.T) {
 	suite.Run(t, new(testSeal))
 }